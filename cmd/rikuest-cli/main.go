@@ -0,0 +1,355 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"rikuest/internal/database"
+	"rikuest/internal/models"
+	"rikuest/internal/services"
+)
+
+const usage = `Usage: rikuest-cli run [flags] <file>
+
+Runs the requests of a project one after another, in the order of the folder tree, and exits
+with status 1 when any of them fails. A request fails when it gets no response or a status other
+than its expected status, or of 400 or above when it has none. <file> is a Rikuest database or a
+project bundle exported from the app; the run works on a temporary copy and leaves it untouched.
+
+Reports are written with -report format=path, where format is junit, tap, json or html and a
+path of - writes the report to the standard output instead of the summary.
+
+Flags:
+`
+
+// Exit statuses: every request passed, some request failed, or the run could not start
+const (
+	exitPassed = 0
+	exitFailed = 1
+	exitError  = 2
+)
+
+// sqliteHeader starts every SQLite database file
+var sqliteHeader = []byte("SQLite format 3\x00")
+
+// variableFlags collects the -var flags, in the order they are given
+type variableFlags []models.Variable
+
+func (v *variableFlags) String() string {
+	return ""
+}
+
+func (v *variableFlags) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || strings.TrimSpace(key) == "" {
+		return fmt.Errorf("expected name=value")
+	}
+	*v = append(*v, models.Variable{Key: strings.TrimSpace(key), Value: val, Enabled: true})
+	return nil
+}
+
+// reportFlags collects the -report flags
+type reportFlags []report
+
+type report struct {
+	format string
+	path   string
+}
+
+func (r *reportFlags) String() string {
+	return ""
+}
+
+func (r *reportFlags) Set(value string) error {
+	format, path, ok := strings.Cut(value, "=")
+	if !ok || path == "" {
+		return fmt.Errorf("expected format=path")
+	}
+	if !slices.Contains(services.RunReportFormats, format) {
+		return fmt.Errorf("unknown format %q, expected one of %s", format, strings.Join(services.RunReportFormats, ", "))
+	}
+	*r = append(*r, report{format: format, path: path})
+	return nil
+}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "run" {
+		newRunFlags(os.Stderr).Usage()
+		os.Exit(exitError)
+	}
+	os.Exit(run(os.Args[2:]))
+}
+
+type runFlags struct {
+	*flag.FlagSet
+	project     string
+	folder      string
+	environment string
+	variables   variableFlags
+	reports     reportFlags
+}
+
+func newRunFlags(output io.Writer) *runFlags {
+	flags := &runFlags{FlagSet: flag.NewFlagSet("run", flag.ContinueOnError)}
+	flags.SetOutput(output)
+	flags.Usage = func() {
+		fmt.Fprint(output, usage)
+		flags.PrintDefaults()
+	}
+	flags.StringVar(&flags.project, "project", "", "name or ID of the project to run, needed when the database holds several")
+	flags.StringVar(&flags.folder, "folder", "", "name, path such as Users/Admin, or ID of the folder to run with its subfolders")
+	flags.StringVar(&flags.environment, "env", "", "name or ID of the environment to use instead of the active one")
+	flags.Var(&flags.variables, "var", "set a variable as name=value, over the ones of the environment (repeatable)")
+	flags.Var(&flags.reports, "report", "write a report as format=path, such as junit=report.xml (repeatable)")
+	return flags
+}
+
+func run(args []string) int {
+	flags := newRunFlags(os.Stderr)
+
+	// Flags may come before or after the file
+	var files []string
+	for {
+		if err := flags.Parse(args); errors.Is(err, flag.ErrHelp) {
+			return exitPassed
+		} else if err != nil {
+			return exitError
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		files = append(files, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+	if len(files) != 1 {
+		flags.Usage()
+		return exitError
+	}
+
+	result, err := runFile(files[0], flags)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return exitError
+	}
+
+	summary := true
+	for _, report := range flags.reports {
+		if err := writeReport(report, result); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			return exitError
+		}
+		summary = summary && report.path != "-"
+	}
+	if summary {
+		printResult(os.Stdout, result)
+	}
+	if result.Failed > 0 {
+		return exitFailed
+	}
+	return exitPassed
+}
+
+// runFile copies a database, or imports a bundle, into a temporary database, then runs the
+// selected project or folder. The file is left untouched: it is not migrated and the run is not
+// recorded in its history.
+func runFile(path string, flags *runFlags) (*models.RunResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "rikuest-run-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	copied := filepath.Join(dir, "rikuest.db")
+	isDatabase := bytes.HasPrefix(data, sqliteHeader)
+	if isDatabase {
+		if err := database.CopyFile(path, copied); err != nil {
+			return nil, err
+		}
+	}
+	db, err := database.NewDB(copied)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	projectID := 0
+	if !isDatabase {
+		imported, err := services.NewProjectService(db).ImportProject(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s is neither a database nor a project bundle: %w", path, err)
+		}
+		projectID = imported.ProjectID
+	}
+
+	if projectID == 0 {
+		if projectID, err = findProject(db, flags.project); err != nil {
+			return nil, err
+		}
+	} else if flags.project != "" {
+		return nil, errors.New("-project only applies to databases, a bundle holds a single project")
+	}
+
+	options := models.RunOptions{Variables: flags.variables}
+	if flags.folder != "" {
+		folderID, err := findFolder(db, projectID, flags.folder)
+		if err != nil {
+			return nil, err
+		}
+		options.FolderID = &folderID
+	}
+	if flags.environment != "" {
+		environmentID, err := findEnvironment(db, projectID, flags.environment)
+		if err != nil {
+			return nil, err
+		}
+		options.EnvironmentID = &environmentID
+	}
+
+	// The runner acts for whoever runs it, and file fields with relative paths are found next to
+	// the file
+	files := services.FileAccess{Unrestricted: true, Root: filepath.Dir(path)}
+	return services.NewRunnerService(db, files).RunProject(projectID, options)
+}
+
+// findProject returns the project with a name or ID, or the only project of the database when
+// none is given
+func findProject(db *database.DB, value string) (int, error) {
+	projects, err := services.NewProjectService(db).GetProjects()
+	if err != nil {
+		return 0, err
+	}
+	if value == "" {
+		switch len(projects) {
+		case 0:
+			return 0, errors.New("the database holds no project")
+		case 1:
+			return projects[0].ID, nil
+		}
+		names := make([]string, len(projects))
+		for i, project := range projects {
+			names[i] = fmt.Sprintf("%q (%d)", project.Name, project.ID)
+		}
+		return 0, fmt.Errorf("the database holds several projects, select one with -project: %s", strings.Join(names, ", "))
+	}
+
+	var matches []int
+	for _, project := range projects {
+		if project.Name == value || strconv.Itoa(project.ID) == value {
+			matches = append(matches, project.ID)
+		}
+	}
+	return single("project", value, matches)
+}
+
+// findFolder returns the folder of a project with an ID, or with a path of names from the root
+func findFolder(db *database.DB, projectID int, value string) (int, error) {
+	folders, err := services.NewFolderService(db).GetFolders(projectID)
+	if err != nil {
+		return 0, err
+	}
+	for _, folder := range folders {
+		if strconv.Itoa(folder.ID) == value {
+			return folder.ID, nil
+		}
+	}
+
+	// Follow the path one level at a time, from every folder with a matching name
+	var parents []int
+	for level, name := range strings.Split(strings.Trim(value, "/"), "/") {
+		var matches []int
+		for _, folder := range folders {
+			if folder.Name != strings.TrimSpace(name) {
+				continue
+			}
+			if level == 0 && folder.ParentID == nil {
+				matches = append(matches, folder.ID)
+			}
+			for _, parentID := range parents {
+				if folder.ParentID != nil && *folder.ParentID == parentID {
+					matches = append(matches, folder.ID)
+				}
+			}
+		}
+		parents = matches
+	}
+	return single("folder", value, parents)
+}
+
+// findEnvironment returns the environment of a project with a name or ID
+func findEnvironment(db *database.DB, projectID int, value string) (int, error) {
+	environments, err := services.NewEnvironmentService(db).GetEnvironments(projectID)
+	if err != nil {
+		return 0, err
+	}
+	var matches []int
+	for _, environment := range environments {
+		if environment.Name == value || strconv.Itoa(environment.ID) == value {
+			matches = append(matches, environment.ID)
+		}
+	}
+	return single("environment", value, matches)
+}
+
+func single(kind string, value string, matches []int) (int, error) {
+	switch len(matches) {
+	case 0:
+		return 0, fmt.Errorf("no %s %q", kind, value)
+	case 1:
+		return matches[0], nil
+	}
+	return 0, fmt.Errorf("several %ss match %q, select one by ID", kind, value)
+}
+
+// writeReport writes a report to its file, or to the standard output for a path of -
+func writeReport(report report, result *models.RunResult) error {
+	if report.path == "-" {
+		return services.WriteRunReport(os.Stdout, result, report.format)
+	}
+
+	var out bytes.Buffer
+	if err := services.WriteRunReport(&out, result, report.format); err != nil {
+		return err
+	}
+	if dir := filepath.Dir(report.path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to write %s report: %w", report.format, err)
+		}
+	}
+	if err := os.WriteFile(report.path, out.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s report: %w", report.format, err)
+	}
+	return nil
+}
+
+// printResult writes a line per request and a summary
+func printResult(w io.Writer, result *models.RunResult) {
+	fmt.Fprintf(w, "%s\n\n", result.Name)
+	for _, item := range result.Results {
+		mark := "PASS"
+		if !item.Passed {
+			mark = "FAIL"
+		}
+		outcome := item.Error
+		if item.Response != nil {
+			outcome = item.Response.StatusText
+		}
+		fmt.Fprintf(w, "%s  %s %s  %s (%d ms)\n", mark, item.Method, item.Name, outcome, item.Duration)
+	}
+
+	duration := time.Duration(result.Duration) * time.Millisecond
+	fmt.Fprintf(w, "\n%d passed, %d failed in %s\n", result.Passed, result.Failed, duration)
+}
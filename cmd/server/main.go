@@ -1,11 +1,14 @@
 package main
 
 import (
+	"flag"
 	"log"
+	"path/filepath"
 	"strings"
 
 	"rikuest/internal/database"
 	"rikuest/internal/handlers"
+	"rikuest/internal/models"
 	"rikuest/internal/services"
 
 	"github.com/gin-contrib/cors"
@@ -13,16 +16,64 @@ import (
 )
 
 func main() {
-	db, err := database.NewDB("rikuest.db")
+	mockProject := flag.Int("mock-project", 0, "start the mock server for this project ID")
+	mockAddr := flag.String("mock-addr", "127.0.0.1:8090", "address the mock server listens on")
+	mockAllowRemote := flag.Bool("mock-allow-remote", false, "let the mock server listen on addresses other than loopback")
+	mockDelay := flag.Int("mock-delay", 0, "delay in milliseconds added to every mock response")
+	mockErrorRate := flag.Float64("mock-error-rate", 0, "fraction of mock responses (0-1) replaced by an injected error")
+	proxyAllowRemote := flag.Bool("proxy-allow-remote", false, "let the recording proxy listen on addresses other than loopback")
+	dbPath := flag.String("db", "rikuest.db", "path of the SQLite database")
+	filesRoot := flag.String("files", "", "directory holding uploaded files, stored projects and documentation sites, the only local files clients can use (default: files next to the database)")
+	migrateDryRun := flag.Bool("migrate-dry-run", false, "list the schema migrations the database needs, without applying them, and exit")
+	flag.Parse()
+
+	if *migrateDryRun {
+		pending, err := database.DryRunMigrations(*dbPath)
+		for _, migration := range pending {
+			log.Printf("Would apply migration %d: %s", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatal("Dry run failed:", err)
+		}
+		log.Printf("%d pending migrations, schema version %d", len(pending), database.LatestSchemaVersion())
+		return
+	}
+
+	db, err := database.NewDB(*dbPath)
 	if err != nil {
 		log.Fatal("Failed to initialize database:", err)
 	}
 	defer db.Close()
 
-	// Initialize services (webhook URL empty for server mode)
-	servicesContainer := services.NewServices(db, "")
+	// Initialize services (webhook URL empty for server mode). Clients reach the server without
+	// authentication, so they only get to the files under the files directory.
+	if *filesRoot == "" {
+		*filesRoot = filepath.Join(filepath.Dir(*dbPath), "files")
+	}
+	servicesContainer := services.NewServices(db, "", services.FileAccess{Root: *filesRoot})
+	servicesContainer.Proxy.AllowRemote = *proxyAllowRemote
+	servicesContainer.Mock.AllowRemote = *mockAllowRemote
 	handler := handlers.NewHandler(servicesContainer)
 
+	// Back up the database now and on schedule, purge expired trash and history, and keep
+	// projects stored as files in sync
+	servicesContainer.Backup.Start()
+	servicesContainer.Trash.Start()
+	servicesContainer.History.Start()
+	servicesContainer.Storage.Start()
+
+	if *mockProject != 0 {
+		err := servicesContainer.Mock.Start(models.MockServerConfig{
+			ProjectID: *mockProject,
+			Address:   *mockAddr,
+			DelayMs:   *mockDelay,
+			ErrorRate: *mockErrorRate,
+		})
+		if err != nil {
+			log.Fatal("Failed to start mock server:", err)
+		}
+	}
+
 	r := gin.Default()
 
 	r.Use(cors.New(cors.Config{
@@ -38,28 +89,136 @@ func main() {
 		// Projects routes
 		api.POST("/projects", handler.CreateProject)
 		api.GET("/projects", handler.GetProjects)
+		api.POST("/projects/import/postman", handler.ImportPostmanCollectionAsProject)
+		api.POST("/projects/import/insomnia", handler.ImportInsomnia)
+		api.POST("/projects/open", handler.OpenProjectDirectory)
+		api.POST("/projects/import", handler.ImportProject)
 		api.GET("/project/:id", handler.GetProject)
 		api.PUT("/project/:id", handler.UpdateProject)
 		api.DELETE("/project/:id", handler.DeleteProject)
+		api.POST("/project/:id/duplicate", handler.DuplicateProject)
+		api.GET("/project/:id/export", handler.ExportProject)
 		api.GET("/project/:id/requests", handler.GetRequests)
 		api.GET("/project/:id/folders", handler.GetFolders)
+		api.PUT("/project/:id/folders/order", handler.ReorderFolders)
+		api.GET("/project/:id/tags", handler.GetTags)
+		api.POST("/project/:id/tags", handler.CreateTag)
+		api.GET("/project/:id/filters", handler.GetSavedFilters)
+		api.POST("/project/:id/filters", handler.CreateSavedFilter)
+		api.POST("/project/:id/mock/start", handler.StartMockServer)
+		api.POST("/project/:id/proxy/start", handler.StartProxy)
+		api.POST("/project/:id/import/har", handler.ImportHAR)
+		api.GET("/project/:id/export/har", handler.ExportProjectHAR)
+		api.POST("/project/:id/import/curl", handler.ImportCurl)
+		api.POST("/project/:id/import/http-file", handler.ImportHTTPFile)
+		api.POST("/project/:id/import/openapi", handler.ImportOpenAPI)
+		api.GET("/project/:id/openapi/specs", handler.GetOpenAPISpecs)
+		api.GET("/project/:id/export/openapi", handler.ExportProjectOpenAPI)
+		api.GET("/project/:id/export/http-file", handler.ExportProjectHTTPFile)
+		api.POST("/project/:id/import/postman", handler.ImportPostmanCollection)
+		api.POST("/project/:id/import/postman/environment", handler.ImportPostmanEnvironment)
+		api.GET("/project/:id/export/postman", handler.ExportPostmanCollection)
+		api.GET("/project/:id/docs", handler.ExportProjectDocs)
+		api.POST("/project/:id/docs", handler.WriteProjectDocs)
+		api.GET("/project/:id/environments", handler.GetEnvironments)
+		api.PUT("/project/:id/environment/active", handler.SetActiveEnvironment)
+		api.GET("/project/:id/trash", handler.GetProjectTrash)
+		api.GET("/project/:id/history", handler.GetProjectHistory)
+		api.GET("/project/:id/storage", handler.GetProjectStorage)
+		api.PUT("/project/:id/storage", handler.SetProjectStorage)
+		api.DELETE("/project/:id/storage", handler.DeleteProjectStorage)
+		api.POST("/project/:id/storage/sync", handler.SyncProjectStorage)
 
 		// Folders routes
 		api.POST("/folders", handler.CreateFolder)
 		api.PUT("/folder/:id", handler.UpdateFolder)
 		api.DELETE("/folder/:id", handler.DeleteFolder)
+		api.POST("/folder/:id/duplicate", handler.DuplicateFolder)
+		api.POST("/folder/:id/move", handler.MoveFolder)
+		api.GET("/folder/:id/export/har", handler.ExportFolderHAR)
+		api.GET("/folder/:id/export/http-file", handler.ExportFolderHTTPFile)
+		api.GET("/folder/:id/export/openapi", handler.ExportFolderOpenAPI)
 
 		// Requests routes
 		api.POST("/requests", handler.CreateRequest)
 		api.GET("/request/:id", handler.GetRequest)
 		api.PUT("/request/:id", handler.UpdateRequest)
 		api.DELETE("/request/:id", handler.DeleteRequest)
+		api.POST("/request/:id/duplicate", handler.DuplicateRequest)
 		api.POST("/request/:id/execute", handler.ExecuteRequest)
 		api.GET("/request/:id/history", handler.GetRequestHistory)
 		api.DELETE("/request/:id/history/:historyId", handler.DeleteRequestHistoryItem)
+		api.POST("/request/:id/history/:historyId/example", handler.SaveHistoryAsExample)
+		api.GET("/request/:id/examples", handler.GetRequestExamples)
+		api.POST("/request/:id/examples", handler.CreateRequestExample)
+		api.GET("/request/:id/revisions", handler.GetRequestRevisions)
+		api.GET("/request/:id/revisions/diff", handler.DiffRequestRevisions)
+		api.POST("/request/:id/revisions/:revisionId/rollback", handler.RollbackRequest)
 		api.POST("/request/move", handler.MoveRequest)
 		api.GET("/request/:id/copy", handler.CopyRequestFormats)
 		api.GET("/request/:id/copy-all", handler.CopyAllRequestFormats)
+		api.POST("/curl/parse", handler.ParseCurl)
+		api.POST("/uploads", handler.UploadFile)
+		api.POST("/openapi/preview", handler.PreviewOpenAPI)
+
+		// Environments routes
+		api.POST("/environments", handler.CreateEnvironment)
+		api.PUT("/environment/:id", handler.UpdateEnvironment)
+		api.DELETE("/environment/:id", handler.DeleteEnvironment)
+		api.GET("/environment/:id/export/postman", handler.ExportPostmanEnvironment)
+
+		// OpenAPI documents routes
+		api.DELETE("/openapi/spec/:id", handler.DeleteOpenAPISpec)
+		api.POST("/openapi/spec/:id/diff", handler.DiffOpenAPISpec)
+		api.POST("/openapi/spec/:id/sync", handler.SyncOpenAPISpec)
+
+		// Tag and saved filter routes
+		api.PUT("/tag/:id", handler.UpdateTag)
+		api.DELETE("/tag/:id", handler.DeleteTag)
+		api.PUT("/filter/:id", handler.UpdateSavedFilter)
+		api.DELETE("/filter/:id", handler.DeleteSavedFilter)
+		api.GET("/filter/:id/requests", handler.GetSavedFilterRequests)
+		api.POST("/filter/:id/run", handler.RunSavedFilter)
+
+		// Search routes
+		api.GET("/search", handler.Search)
+
+		// History routes
+		api.POST("/history/export/har", handler.ExportHistoryHAR)
+		api.GET("/history/config", handler.GetHistoryConfig)
+		api.PUT("/history/config", handler.UpdateHistoryConfig)
+		api.GET("/history/diff/config", handler.GetResponseDiffConfig)
+		api.PUT("/history/diff/config", handler.UpdateResponseDiffConfig)
+		api.POST("/history/:id/replay", handler.ReplayHistory)
+		api.GET("/history/:id/diff/:againstId", handler.DiffHistory)
+		api.POST("/history/:id/diff/live", handler.DiffHistoryLive)
+
+		// Response examples routes
+		api.PUT("/example/:id", handler.UpdateRequestExample)
+		api.DELETE("/example/:id", handler.DeleteRequestExample)
+
+		// Mock server routes
+		api.GET("/mock/status", handler.GetMockServerStatus)
+		api.POST("/mock/stop", handler.StopMockServer)
+
+		// Trash routes
+		api.GET("/trash/projects", handler.GetTrashedProjects)
+		api.GET("/trash/config", handler.GetTrashConfig)
+		api.PUT("/trash/config", handler.UpdateTrashConfig)
+		api.POST("/trash/:id/restore", handler.RestoreTrashItem)
+		api.DELETE("/trash/:id", handler.PurgeTrashItem)
+
+		// Backup routes
+		api.GET("/backups", handler.GetBackups)
+		api.POST("/backups", handler.CreateBackup)
+		api.GET("/backups/config", handler.GetBackupConfig)
+		api.PUT("/backups/config", handler.UpdateBackupConfig)
+		api.POST("/backup/:name/restore", handler.RestoreBackup)
+
+		// Recording proxy routes
+		api.GET("/proxy/status", handler.GetProxyStatus)
+		api.POST("/proxy/stop", handler.StopProxy)
+		api.GET("/proxy/ca.pem", handler.GetProxyCACertificate)
 	}
 
 	// Serve static files for non-API routes
@@ -49,6 +49,25 @@ export class APIAdapter {
     });
   }
 
+  async duplicateProject(id, target = {}) {
+    return this.request(`/api/project/${id}/duplicate`, {
+      method: 'POST',
+      body: JSON.stringify(target)
+    });
+  }
+
+  async exportProject(id, includeHistory = false) {
+    const bundle = await this.request(`/api/project/${id}/export?history=${includeHistory}`);
+    return JSON.stringify(bundle, null, 2);
+  }
+
+  async importProject(content) {
+    return this.request('/api/projects/import', {
+      method: 'POST',
+      body: content
+    });
+  }
+
   // ===== REQUEST METHODS =====
   async getRequests(projectId) {
     return this.request(`/api/project/${projectId}/requests`);
@@ -84,8 +103,20 @@ export class APIAdapter {
     });
   }
 
-  async getRequestHistory(id) {
-    return this.request(`/api/request/${id}/history`);
+  async getRequestHistory(id, filter = {}) {
+    return this.request(`/api/request/${id}/history${this.historyQuery(filter)}`);
+  }
+
+  // historyQuery turns a history filter into query parameters, skipping empty values
+  historyQuery(filter) {
+    const params = new URLSearchParams();
+    Object.entries(filter).forEach(([key, value]) => {
+      if (value !== undefined && value !== null && value !== '') {
+        params.append(key, value);
+      }
+    });
+    const query = params.toString();
+    return query ? `?${query}` : '';
   }
 
   async deleteRequestHistoryItem(requestId, historyId) {
@@ -94,6 +125,24 @@ export class APIAdapter {
     });
   }
 
+  async duplicateRequest(id, target = {}) {
+    return this.request(`/api/request/${id}/duplicate`, {
+      method: 'POST',
+      body: JSON.stringify(target)
+    });
+  }
+
+  async moveRequestTo(requestId, target) {
+    return this.request('/api/request/move', {
+      method: 'POST',
+      body: JSON.stringify({
+        request_id: requestId,
+        project_id: target.project_id,
+        folder_id: target.folder_id ?? null
+      })
+    });
+  }
+
   async moveRequest(requestId, folderId, position) {
     return this.request('/api/request/move', {
       method: 'POST',
@@ -124,12 +173,33 @@ export class APIAdapter {
     });
   }
 
-  async deleteFolder(id) {
-    await this.request(`/api/folder/${id}`, {
+  async deleteFolder(id, keepContents = false) {
+    await this.request(`/api/folder/${id}${keepContents ? '?keep_contents=true' : ''}`, {
       method: 'DELETE'
     });
   }
 
+  async reorderFolders(projectId, parentId, folderIds) {
+    return this.request(`/api/project/${projectId}/folders/order`, {
+      method: 'PUT',
+      body: JSON.stringify({ parent_id: parentId, folder_ids: folderIds })
+    });
+  }
+
+  async duplicateFolder(id, target = {}) {
+    return this.request(`/api/folder/${id}/duplicate`, {
+      method: 'POST',
+      body: JSON.stringify(target)
+    });
+  }
+
+  async moveFolder(id, target) {
+    return this.request(`/api/folder/${id}/move`, {
+      method: 'POST',
+      body: JSON.stringify(target)
+    });
+  }
+
   async copyRequestFormats(requestID, format) {
     return this.request(`/api/request/${requestID}/copy?format=${format}`);
   }
@@ -138,6 +208,324 @@ export class APIAdapter {
     return this.request(`/api/request/${requestID}/copy-all`);
   }
 
+  // Stores a File for multipart file fields, which refer to it by the returned path
+  async uploadFile(file) {
+    const form = new FormData();
+    form.append('file', file);
+    const response = await fetch(`${this.baseURL}/api/uploads`, {
+      method: 'POST',
+      body: form
+    });
+    if (!response.ok) {
+      throw new Error(`HTTP ${response.status}: ${response.statusText}`);
+    }
+    return response.json();
+  }
+
+  // ===== OPENAPI METHODS =====
+  async previewOpenAPI(content) {
+    return this.request('/api/openapi/preview', {
+      method: 'POST',
+      body: JSON.stringify({ content })
+    });
+  }
+
+  async importOpenAPI(projectId, content, options = {}) {
+    return this.request(`/api/project/${projectId}/import/openapi`, {
+      method: 'POST',
+      body: JSON.stringify({ content, ...options })
+    });
+  }
+
+  async getOpenAPISpecs(projectId) {
+    return this.request(`/api/project/${projectId}/openapi/specs`);
+  }
+
+  async deleteOpenAPISpec(specId) {
+    await this.request(`/api/openapi/spec/${specId}`, {
+      method: 'DELETE'
+    });
+  }
+
+  async diffOpenAPISpec(specId, content) {
+    return this.request(`/api/openapi/spec/${specId}/diff`, {
+      method: 'POST',
+      body: JSON.stringify({ content })
+    });
+  }
+
+  async syncOpenAPISpec(specId, content, options = {}) {
+    return this.request(`/api/openapi/spec/${specId}/sync`, {
+      method: 'POST',
+      body: JSON.stringify({ content, ...options })
+    });
+  }
+
+  // ===== STORAGE METHODS =====
+  async getProjectStorage(projectId) {
+    try {
+      return await this.request(`/api/project/${projectId}/storage`);
+    } catch (error) {
+      // Projects kept only in the database have no storage
+      if (error.message.startsWith('HTTP 404')) {
+        return null;
+      }
+      throw error;
+    }
+  }
+
+  async setProjectStorage(projectId, path) {
+    return this.request(`/api/project/${projectId}/storage`, {
+      method: 'PUT',
+      body: JSON.stringify({ path })
+    });
+  }
+
+  async deleteProjectStorage(projectId) {
+    await this.request(`/api/project/${projectId}/storage`, {
+      method: 'DELETE'
+    });
+  }
+
+  async syncProjectStorage(projectId) {
+    return this.request(`/api/project/${projectId}/storage/sync`, {
+      method: 'POST'
+    });
+  }
+
+  async openProjectDirectory(path) {
+    return this.request('/api/projects/open', {
+      method: 'POST',
+      body: JSON.stringify({ path })
+    });
+  }
+
+  // ===== DOCS METHODS =====
+  // Writes the site to a directory of the machine running the server
+  async exportProjectDocs(projectId, path) {
+    return this.request(`/api/project/${projectId}/docs`, {
+      method: 'POST',
+      body: JSON.stringify({ path })
+    });
+  }
+
+  // Downloads the site as a ZIP file
+  projectDocsArchiveURL(projectId) {
+    return `${this.baseURL}/api/project/${projectId}/docs`;
+  }
+
+  // ===== TAG METHODS =====
+  async getTags(projectId) {
+    return this.request(`/api/project/${projectId}/tags`);
+  }
+
+  async createTag(projectId, tag) {
+    return this.request(`/api/project/${projectId}/tags`, {
+      method: 'POST',
+      body: JSON.stringify(tag)
+    });
+  }
+
+  async updateTag(id, tag) {
+    return this.request(`/api/tag/${id}`, {
+      method: 'PUT',
+      body: JSON.stringify(tag)
+    });
+  }
+
+  async deleteTag(id) {
+    await this.request(`/api/tag/${id}`, {
+      method: 'DELETE'
+    });
+  }
+
+  async findRequests(projectId, filter = {}) {
+    const params = new URLSearchParams();
+    (filter.tags || []).forEach((tag) => params.append('tag', tag));
+    ['method', 'url', 'status'].forEach((key) => {
+      if (filter[key]) params.set(key, filter[key]);
+    });
+    return this.request(`/api/project/${projectId}/requests?${params}`);
+  }
+
+  async getSavedFilters(projectId) {
+    return this.request(`/api/project/${projectId}/filters`);
+  }
+
+  async createSavedFilter(projectId, filter) {
+    return this.request(`/api/project/${projectId}/filters`, {
+      method: 'POST',
+      body: JSON.stringify(filter)
+    });
+  }
+
+  async updateSavedFilter(id, filter) {
+    return this.request(`/api/filter/${id}`, {
+      method: 'PUT',
+      body: JSON.stringify(filter)
+    });
+  }
+
+  async deleteSavedFilter(id) {
+    await this.request(`/api/filter/${id}`, {
+      method: 'DELETE'
+    });
+  }
+
+  async getSavedFilterRequests(id) {
+    return this.request(`/api/filter/${id}/requests`);
+  }
+
+  async runSavedFilter(id) {
+    return this.request(`/api/filter/${id}/run`, {
+      method: 'POST'
+    });
+  }
+
+  async runSavedFilterReport(id, format) {
+    const response = await fetch(`${this.baseURL}/api/filter/${id}/run?report=${encodeURIComponent(format)}`, {
+      method: 'POST'
+    });
+    if (!response.ok) {
+      throw new Error(`HTTP ${response.status}: ${response.statusText}`);
+    }
+    return response.text();
+  }
+
+  // ===== SEARCH METHODS =====
+  async search(query, { projectId = 0, responses = false, limit = 0 } = {}) {
+    const params = new URLSearchParams({ q: query });
+    if (projectId) params.append('project_id', projectId);
+    if (responses) params.append('responses', 'true');
+    if (limit) params.append('limit', limit);
+    return this.request(`/api/search?${params.toString()}`);
+  }
+
+  // ===== HISTORY METHODS =====
+  async getProjectHistory(projectId, filter = {}) {
+    return this.request(`/api/project/${projectId}/history${this.historyQuery(filter)}`);
+  }
+
+  async getHistoryConfig() {
+    return this.request('/api/history/config');
+  }
+
+  async setHistoryConfig(config) {
+    return this.request('/api/history/config', {
+      method: 'PUT',
+      body: JSON.stringify(config)
+    });
+  }
+
+  async replayHistory(historyId) {
+    return this.request(`/api/history/${historyId}/replay`, {
+      method: 'POST'
+    });
+  }
+
+  async diffHistory(historyId, againstId, ignorePaths = []) {
+    return this.request(`/api/history/${historyId}/diff/${againstId}${this.ignoreQuery(ignorePaths)}`);
+  }
+
+  async diffHistoryLive(historyId, ignorePaths = []) {
+    return this.request(`/api/history/${historyId}/diff/live${this.ignoreQuery(ignorePaths)}`, {
+      method: 'POST'
+    });
+  }
+
+  ignoreQuery(ignorePaths) {
+    const params = new URLSearchParams();
+    ignorePaths.forEach(path => params.append('ignore', path));
+    const query = params.toString();
+    return query ? `?${query}` : '';
+  }
+
+  async getResponseDiffConfig() {
+    return this.request('/api/history/diff/config');
+  }
+
+  async setResponseDiffConfig(config) {
+    return this.request('/api/history/diff/config', {
+      method: 'PUT',
+      body: JSON.stringify(config)
+    });
+  }
+
+  // ===== REVISION METHODS =====
+  async getRequestRevisions(requestId) {
+    return this.request(`/api/request/${requestId}/revisions`);
+  }
+
+  async diffRequestRevisions(requestId, from = 0, to = 0) {
+    return this.request(`/api/request/${requestId}/revisions/diff?from=${from}&to=${to}`);
+  }
+
+  async rollbackRequest(requestId, revisionId) {
+    return this.request(`/api/request/${requestId}/revisions/${revisionId}/rollback`, {
+      method: 'POST'
+    });
+  }
+
+  // ===== TRASH METHODS =====
+  async getProjectTrash(projectId) {
+    return this.request(`/api/project/${projectId}/trash`);
+  }
+
+  async getTrashedProjects() {
+    return this.request('/api/trash/projects');
+  }
+
+  async restoreTrashItem(id) {
+    await this.request(`/api/trash/${id}/restore`, {
+      method: 'POST'
+    });
+  }
+
+  async purgeTrashItem(id) {
+    await this.request(`/api/trash/${id}`, {
+      method: 'DELETE'
+    });
+  }
+
+  async getTrashConfig() {
+    return this.request('/api/trash/config');
+  }
+
+  async setTrashConfig(config) {
+    return this.request('/api/trash/config', {
+      method: 'PUT',
+      body: JSON.stringify(config)
+    });
+  }
+
+  // ===== BACKUP METHODS =====
+  async getBackups() {
+    return this.request('/api/backups');
+  }
+
+  async createBackup() {
+    return this.request('/api/backups', {
+      method: 'POST'
+    });
+  }
+
+  async restoreBackup(name) {
+    return this.request(`/api/backup/${encodeURIComponent(name)}/restore`, {
+      method: 'POST'
+    });
+  }
+
+  async getBackupConfig() {
+    return this.request('/api/backups/config');
+  }
+
+  async setBackupConfig(config) {
+    return this.request('/api/backups/config', {
+      method: 'PUT',
+      body: JSON.stringify(config)
+    });
+  }
+
   // ===== CONFIG METHODS =====
   async getRequestTimeout() {
     // In web mode, get from localStorage or default to 300
@@ -30,6 +30,18 @@ export class WailsAdapter {
     await this.app.DeleteProject(id);
   }
 
+  async duplicateProject(id, target = {}) {
+    return await this.app.DuplicateProject(id, target);
+  }
+
+  async exportProject(id, includeHistory = false) {
+    return await this.app.ExportProject(id, includeHistory);
+  }
+
+  async importProject(content) {
+    return await this.app.ImportProject(content);
+  }
+
   // ===== REQUEST METHODS =====
   async getRequests(projectId) {
     return await this.app.GetRequests(projectId);
@@ -67,8 +79,8 @@ export class WailsAdapter {
     }
   }
 
-  async getRequestHistory(id) {
-    return await this.app.GetRequestHistory(id);
+  async getRequestHistory(id, filter = {}) {
+    return await this.app.GetRequestHistory(id, filter);
   }
 
   async deleteRequestHistoryItem(requestId, historyId) {
@@ -84,6 +96,14 @@ export class WailsAdapter {
     await this.app.MoveRequest(requestId, folderId, position);
   }
 
+  async duplicateRequest(id, target = {}) {
+    return await this.app.DuplicateRequest(id, target);
+  }
+
+  async moveRequestTo(requestId, target) {
+    await this.app.MoveRequestTo(requestId, target);
+  }
+
   // ===== FOLDER METHODS =====
   async getFolders(projectId) {
     return await this.app.GetFolders(projectId);
@@ -99,8 +119,24 @@ export class WailsAdapter {
     return await this.app.UpdateFolder(folder);
   }
 
-  async deleteFolder(id) {
-    await this.app.DeleteFolder(id);
+  async deleteFolder(id, keepContents = false) {
+    if (keepContents) {
+      await this.app.DeleteFolderKeepingContents(id);
+    } else {
+      await this.app.DeleteFolder(id);
+    }
+  }
+
+  async reorderFolders(projectId, parentId, folderIds) {
+    await this.app.ReorderFolders(projectId, parentId, folderIds);
+  }
+
+  async duplicateFolder(id, target = {}) {
+    return await this.app.DuplicateFolder(id, target);
+  }
+
+  async moveFolder(id, target) {
+    await this.app.MoveFolder(id, target);
   }
 
   async copyRequestFormats(requestID, format) {
@@ -115,6 +151,216 @@ export class WailsAdapter {
     return { formats };
   }
 
+  // Stores a File for multipart file fields, which refer to it by the returned path
+  async uploadFile(file) {
+    // Byte slices cross the bindings as base64
+    const bytes = new Uint8Array(await file.arrayBuffer());
+    let binary = '';
+    for (let i = 0; i < bytes.length; i += 0x8000) {
+      binary += String.fromCharCode(...bytes.subarray(i, i + 0x8000));
+    }
+    const path = await this.app.UploadFile(file.name, btoa(binary));
+    return { path };
+  }
+
+  // ===== OPENAPI METHODS =====
+  async previewOpenAPI(content) {
+    return await this.app.PreviewOpenAPI(content);
+  }
+
+  async importOpenAPI(projectId, content, options = {}) {
+    return await this.app.ImportOpenAPI(projectId, content, options);
+  }
+
+  async getOpenAPISpecs(projectId) {
+    return await this.app.GetOpenAPISpecs(projectId);
+  }
+
+  async deleteOpenAPISpec(specId) {
+    await this.app.DeleteOpenAPISpec(specId);
+  }
+
+  async diffOpenAPISpec(specId, content) {
+    return await this.app.DiffOpenAPISpec(specId, content);
+  }
+
+  async syncOpenAPISpec(specId, content, options = {}) {
+    return await this.app.SyncOpenAPISpec(specId, content, options);
+  }
+
+  // ===== STORAGE METHODS =====
+  async getProjectStorage(projectId) {
+    return await this.app.GetProjectStorage(projectId);
+  }
+
+  async setProjectStorage(projectId, path) {
+    return await this.app.SetProjectStorage(projectId, path);
+  }
+
+  async deleteProjectStorage(projectId) {
+    await this.app.DeleteProjectStorage(projectId);
+  }
+
+  async syncProjectStorage(projectId) {
+    return await this.app.SyncProjectStorage(projectId);
+  }
+
+  async openProjectDirectory(path) {
+    return await this.app.OpenProjectDirectory(path);
+  }
+
+  // ===== DOCS METHODS =====
+  async exportProjectDocs(projectId, path) {
+    const index = await this.app.ExportProjectDocs(projectId, path);
+    return { path: index };
+  }
+
+  // ===== TAG METHODS =====
+  async getTags(projectId) {
+    return await this.app.GetTags(projectId);
+  }
+
+  async createTag(projectId, tag) {
+    return await this.app.CreateTag({ ...tag, project_id: projectId });
+  }
+
+  async updateTag(id, tag) {
+    return await this.app.UpdateTag({ ...tag, id });
+  }
+
+  async deleteTag(id) {
+    await this.app.DeleteTag(id);
+  }
+
+  async findRequests(projectId, filter = {}) {
+    return await this.app.FindRequests(projectId, filter);
+  }
+
+  async getSavedFilters(projectId) {
+    return await this.app.GetSavedFilters(projectId);
+  }
+
+  async createSavedFilter(projectId, filter) {
+    return await this.app.CreateSavedFilter({ ...filter, project_id: projectId });
+  }
+
+  async updateSavedFilter(id, filter) {
+    return await this.app.UpdateSavedFilter({ ...filter, id });
+  }
+
+  async deleteSavedFilter(id) {
+    await this.app.DeleteSavedFilter(id);
+  }
+
+  async getSavedFilterRequests(id) {
+    return await this.app.GetSavedFilterRequests(id);
+  }
+
+  async runSavedFilter(id) {
+    return await this.app.RunSavedFilter(id);
+  }
+
+  async runSavedFilterReport(id, format) {
+    return await this.app.RunSavedFilterReport(id, format);
+  }
+
+  // ===== SEARCH METHODS =====
+  async search(query, { projectId = 0, responses = false, limit = 0 } = {}) {
+    return await this.app.Search({ query, project_id: projectId, responses, limit });
+  }
+
+  // ===== HISTORY METHODS =====
+  async getProjectHistory(projectId, filter = {}) {
+    return await this.app.GetProjectHistory(projectId, filter);
+  }
+
+  async getHistoryConfig() {
+    return await this.app.GetHistoryConfig();
+  }
+
+  async setHistoryConfig(config) {
+    await this.app.SetHistoryConfig(config);
+  }
+
+  async replayHistory(historyId) {
+    return await this.app.ReplayHistory(historyId);
+  }
+
+  async diffHistory(historyId, againstId, ignorePaths = []) {
+    return await this.app.DiffHistory(historyId, againstId, ignorePaths);
+  }
+
+  async diffHistoryLive(historyId, ignorePaths = []) {
+    return await this.app.DiffHistoryLive(historyId, ignorePaths);
+  }
+
+  async getResponseDiffConfig() {
+    return await this.app.GetResponseDiffConfig();
+  }
+
+  async setResponseDiffConfig(config) {
+    await this.app.SetResponseDiffConfig(config);
+  }
+
+  // ===== REVISION METHODS =====
+  async getRequestRevisions(requestId) {
+    return await this.app.GetRequestRevisions(requestId);
+  }
+
+  async diffRequestRevisions(requestId, from = 0, to = 0) {
+    return await this.app.DiffRequestRevisions(requestId, from, to);
+  }
+
+  async rollbackRequest(requestId, revisionId) {
+    return await this.app.RollbackRequest(requestId, revisionId);
+  }
+
+  // ===== TRASH METHODS =====
+  async getProjectTrash(projectId) {
+    return await this.app.GetProjectTrash(projectId);
+  }
+
+  async getTrashedProjects() {
+    return await this.app.GetTrashedProjects();
+  }
+
+  async restoreTrashItem(id) {
+    await this.app.RestoreTrashItem(id);
+  }
+
+  async purgeTrashItem(id) {
+    await this.app.PurgeTrashItem(id);
+  }
+
+  async getTrashConfig() {
+    return await this.app.GetTrashConfig();
+  }
+
+  async setTrashConfig(config) {
+    await this.app.SetTrashConfig(config);
+  }
+
+  // ===== BACKUP METHODS =====
+  async getBackups() {
+    return await this.app.GetBackups();
+  }
+
+  async createBackup() {
+    return await this.app.CreateBackup();
+  }
+
+  async restoreBackup(name) {
+    return await this.app.RestoreBackup(name);
+  }
+
+  async getBackupConfig() {
+    return await this.app.GetBackupConfig();
+  }
+
+  async setBackupConfig(config) {
+    await this.app.SetBackupConfig(config);
+  }
+
   // ===== CONFIG METHODS =====
   async getRequestTimeout() {
     return await this.app.GetRequestTimeout();
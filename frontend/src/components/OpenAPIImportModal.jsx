@@ -5,13 +5,13 @@ import { useUISize } from '../hooks/useUISize';
 import { useTranslation } from '../hooks/useTranslation';
 import { useRequestStore } from '../stores/requestStore';
 import { useFolderStore } from '../stores/folderStore';
-import { parseOpenAPI, convertToRequests } from '../utils/OpenAPIImporter';
+import { adapterFactory } from '../adapters/adapterFactory.js';
 
 const OpenAPIImportModal = ({ isOpen, onClose, projectId }) => {
   const { text, spacing, button } = useUISize();
   const { t } = useTranslation();
-  const { createRequest, fetchRequests } = useRequestStore();
-  const { createFolder, fetchFolders } = useFolderStore();
+  const { fetchRequests } = useRequestStore();
+  const { fetchFolders } = useFolderStore();
   const fileInputRef = useRef(null);
   
   const [file, setFile] = useState(null);
@@ -34,15 +34,21 @@ const OpenAPIImportModal = ({ isOpen, onClose, projectId }) => {
 
     try {
       const fileContent = await file.text();
-      const parsed = await parseOpenAPI(fileContent);
-      const requests = convertToRequests(parsed, projectId);
+      const adapter = await adapterFactory.getAdapter();
+      const parsed = await adapter.previewOpenAPI(fileContent);
+      const requests = parsed.operations || [];
       
       if (requests.length === 0) {
         throw new Error(t('openapi.errors.noEndpoints'));
       }
       
       setPreview({
-        info: parsed.info || {},
+        info: {
+          title: parsed.title,
+          description: parsed.description,
+          version: parsed.version
+        },
+        content: fileContent,
         requests: requests,
         total: requests.length
       });
@@ -70,63 +76,26 @@ const OpenAPIImportModal = ({ isOpen, onClose, projectId }) => {
     setError(null);
 
     try {
-      const requestsToImport = preview.requests.filter((_, idx) => 
-        selectedEndpoints.has(idx)
-      );
+      const operations = preview.requests
+        .filter((_, idx) => selectedEndpoints.has(idx))
+        .map(req => req.id);
 
-      // Create folders from tags if enabled
-      const folderMap = new Map();
-      if (createFoldersFromTags) {
-        const tags = new Set();
-        requestsToImport.forEach(req => {
-          if (req.tag) tags.add(req.tag);
-        });
-
-        for (const tag of tags) {
-          try {
-            const folder = await createFolder({
-              project_id: projectId,
-              name: tag,
-              parent_id: null
-            });
-            folderMap.set(tag, folder.id);
-          } catch (folderError) {
-            // Folder might already exist, continue
-            console.warn(`Failed to create folder for tag "${tag}":`, folderError);
-          }
-        }
-      }
+      // Folders, environments and requests are created by the backend
+      const adapter = await adapterFactory.getAdapter();
+      const result = await adapter.importOpenAPI(projectId, preview.content, {
+        operations,
+        create_folders: createFoldersFromTags
+      });
 
-      // Create requests
-      let successCount = 0;
-      let errorCount = 0;
-      
-      for (const request of requestsToImport) {
-        try {
-          const folderId = request.tag && folderMap.has(request.tag) 
-            ? folderMap.get(request.tag) 
-            : null;
-          
-          // Remove tag from request object before creating
-          const { tag, ...requestData } = request;
-          
-          await createRequest({
-            ...requestData,
-            folder_id: folderId
-          });
-          successCount++;
-        } catch (reqError) {
-          console.error('Failed to create request:', reqError);
-          errorCount++;
-        }
+      if (result?.warnings?.length) {
+        console.warn('OpenAPI import warnings:', result.warnings);
       }
 
       // Refresh data
       await fetchRequests(projectId);
       await fetchFolders(projectId);
 
-      // Show success message
-      if (successCount > 0) {
+      if (result && result.requests > 0) {
         // Close modal on success
         onClose();
         // Reset state
@@ -134,7 +103,7 @@ const OpenAPIImportModal = ({ isOpen, onClose, projectId }) => {
         setPreview(null);
         setSelectedEndpoints(new Set());
       } else {
-        setError(t('openapi.errors.importPartialFailed').replace('{count}', errorCount));
+        setError(t('openapi.errors.importPartialFailed').replace('{count}', operations.length));
       }
     } catch (err) {
       setError(err.message || t('openapi.errors.importFailed'));
@@ -382,7 +351,7 @@ const OpenAPIImportModal = ({ isOpen, onClose, projectId }) => {
                           )}
                         </div>
                         <p className={`${text('xs')} text-muted-foreground truncate font-mono`}>
-                          {req.url}
+                          {req.path}
                         </p>
                       </div>
                     </div>
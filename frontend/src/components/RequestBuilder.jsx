@@ -219,6 +219,7 @@ function RequestBuilder() {
     id: null,
     project_id: null,
     name: '',
+    description: '',
     method: 'GET',
     url: '',
     headers: {},
@@ -229,7 +230,8 @@ function RequestBuilder() {
     bearer_token: '',
     basic_auth: { username: '', password: '' },
     body_type: 'none',
-    form_data: []
+    form_data: [],
+    expected_status: 0
   });
 
   // Tabs
@@ -371,6 +373,7 @@ function RequestBuilder() {
         project_id: currentRequest.project_id,
         folder_id: currentRequest.folder_id || null,
         name: currentRequest.name || '',
+        description: currentRequest.description || '',
         method: currentRequest.method || 'GET',
         url: currentRequest.url || '',
         headers: { ...(currentRequest.headers || {}) },
@@ -383,7 +386,8 @@ function RequestBuilder() {
         bearer_token: currentRequest.bearer_token || '',
         basic_auth: { ...(currentRequest.basic_auth || { username: '', password: '' }) },
         body_type: currentRequest.body_type || 'none',
-        form_data: currentRequest.form_data ? [...currentRequest.form_data] : []
+        form_data: currentRequest.form_data ? [...currentRequest.form_data] : [],
+        expected_status: currentRequest.expected_status || 0
       };
       
       setRequestData(newRequestData);
@@ -722,8 +726,8 @@ function RequestBuilder() {
     
     try {
       const adapter = await adapterFactory.getAdapter();
-      const history = await adapter.getRequestHistory(requestData.id);
-      setHistory(history || []);
+      const page = await adapter.getRequestHistory(requestData.id);
+      setHistory(page?.items || []);
     } catch (error) {
       console.error('Failed to load history:', error);
     }
@@ -816,7 +820,9 @@ function RequestBuilder() {
       count: requestData.headers_array.filter(h => h.key && h.value).length || null 
     },
     { id: 'body', label: 'Body' },
-    { id: 'auth', label: 'Authorization' }
+    { id: 'auth', label: 'Authorization' },
+    { id: 'tests', label: 'Tests' },
+    { id: 'docs', label: 'Docs' }
   ];
 
   const responseTabs = [
@@ -1182,6 +1188,37 @@ function RequestBuilder() {
                 </div>
               </div>
             )}
+
+            {/* Tests Tab */}
+            {activeRequestTab === 'tests' && (
+              <div className="p-4 space-y-3">
+                <label className={`${text('sm')} font-medium`}>Expected status</label>
+                <Input
+                  type="number"
+                  min="100"
+                  max="599"
+                  value={requestData.expected_status || ''}
+                  onChange={(e) => updateRequestData({ expected_status: parseInt(e.target.value, 10) || 0 })}
+                  placeholder="Any status below 400"
+                  className={input}
+                />
+                <p className={`${text('xs')} text-muted-foreground`}>
+                  Runs fail when the response has another status.
+                </p>
+              </div>
+            )}
+
+            {/* Docs Tab */}
+            {activeRequestTab === 'docs' && (
+              <div className="h-full flex flex-col p-4">
+                <Textarea
+                  value={requestData.description}
+                  onChange={(e) => updateRequestData({ description: e.target.value })}
+                  placeholder="Describe this request in Markdown"
+                  className={`flex-1 min-h-[200px] font-mono ${text('sm')} resize-none ${input}`}
+                />
+              </div>
+            )}
           </div>
         </div>
 
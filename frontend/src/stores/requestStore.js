@@ -31,6 +31,15 @@ export const useRequestStore = create((set, get) => ({
     return newRequest;
   },
 
+  duplicateRequest: async (id, target = {}) => {
+    const adapter = await adapterFactory.getAdapter();
+    const newRequest = await adapter.duplicateRequest(id, target);
+    set((state) => ({
+      requests: [newRequest, ...state.requests]
+    }));
+    return newRequest;
+  },
+
   updateRequest: async (id, request) => {
     const adapter = await adapterFactory.getAdapter();
     const updatedRequest = await adapter.updateRequest(id, request);
@@ -109,7 +118,8 @@ export const useRequestStore = create((set, get) => ({
     if (request && request.id) {
       try {
         const adapter = await adapterFactory.getAdapter();
-        const history = await adapter.getRequestHistory(request.id);
+        const page = await adapter.getRequestHistory(request.id, { limit: 1 });
+        const history = page?.items;
         if (history && history.length > 0) {
           // Set the most recent response (first item in history)
           const lastResponse = {
@@ -26,7 +26,7 @@ function Project({ layout, onNewProject, onSettings }) {
   const { t } = useTranslation();
   
   const { currentProject, fetchProject } = useProjectStore();
-  const { requests, loading, currentRequest, fetchRequests, createRequest, deleteRequest, setCurrentRequest } = useRequestStore();
+  const { requests, loading, currentRequest, fetchRequests, createRequest, duplicateRequest, deleteRequest, setCurrentRequest } = useRequestStore();
   const { fetchFolders } = useFolderStore();
   
   const [showRequestDialog, setShowRequestDialog] = useState(false);
@@ -198,24 +198,7 @@ function Project({ layout, onNewProject, onSettings }) {
     if (!selectedRequest) return;
     
     try {
-      const duplicatedRequest = {
-        project_id: selectedRequest.project_id,
-        folder_id: selectedRequest.folder_id,
-        name: `${selectedRequest.name} (Copy)`,
-        method: selectedRequest.method,
-        url: selectedRequest.url,
-        headers: selectedRequest.headers,
-        query_params: selectedRequest.query_params || [],
-        auth_type: selectedRequest.auth_type || 'none',
-        bearer_token: selectedRequest.bearer_token || '',
-        basic_auth: selectedRequest.basic_auth || { username: '', password: '' },
-        body_type: selectedRequest.body_type || 'none',
-        body: selectedRequest.body || '',
-        form_data: selectedRequest.form_data || [],
-        position: selectedRequest.position + 1
-      };
-      
-      const newRequest = await createRequest(duplicatedRequest);
+      const newRequest = await duplicateRequest(selectedRequest.id);
       setCurrentRequest(newRequest);
       setShowMenu(false);
       setSelectedRequest(null);
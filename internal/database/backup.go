@@ -0,0 +1,206 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// databasePath returns the file behind a data source name such as "file:rikuest.db?_fk=1",
+// or "" for in-memory databases
+func databasePath(dataSourceName string) string {
+	path := strings.TrimPrefix(dataSourceName, "file:")
+	if i := strings.Index(path, "?"); i >= 0 {
+		path = path[:i]
+	}
+	if path == "" || path == ":memory:" {
+		return ""
+	}
+	return path
+}
+
+const (
+	backupPrefix     = "rikuest-"
+	backupExtension  = ".db"
+	backupTimeFormat = "20060102-150405.000"
+)
+
+// BackupDir returns the directory holding the backups of the database at path
+func BackupDir(path string) string {
+	return filepath.Join(filepath.Dir(path), "backups")
+}
+
+// ParseBackupName reads the time and reason from a backup name such as
+// rikuest-20240131-093000.000-startup.db
+func ParseBackupName(name string) (time.Time, string, bool) {
+	if !strings.HasPrefix(name, backupPrefix) || !strings.HasSuffix(name, backupExtension) {
+		return time.Time{}, "", false
+	}
+	rest := strings.TrimSuffix(strings.TrimPrefix(name, backupPrefix), backupExtension)
+	if len(rest) < len(backupTimeFormat)+2 || rest[len(backupTimeFormat)] != '-' {
+		return time.Time{}, "", false
+	}
+
+	createdAt, err := time.Parse(backupTimeFormat, rest[:len(backupTimeFormat)])
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	return createdAt, rest[len(backupTimeFormat)+1:], true
+}
+
+// BackupInto snapshots the database into dir under a name holding the time and reason, and
+// returns the name and time
+func (db *DB) BackupInto(dir, reason string) (string, time.Time, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to create backups directory: %w", err)
+	}
+
+	createdAt := time.Now().UTC()
+	name := backupPrefix + createdAt.Format(backupTimeFormat) + "-" + reason + backupExtension
+	target := filepath.Join(dir, name)
+
+	// Write to a temporary file so an interrupted backup is never listed
+	temporary := target + ".tmp"
+	os.Remove(temporary)
+	if err := db.BackupTo(temporary); err != nil {
+		os.Remove(temporary)
+		return "", time.Time{}, err
+	}
+	if err := os.Rename(temporary, target); err != nil {
+		os.Remove(temporary)
+		return "", time.Time{}, err
+	}
+	return name, createdAt, nil
+}
+
+// backupBeforeMigrating snapshots a database that holds data before migrations change it. New
+// and in-memory databases have nothing to lose.
+func (db *DB) backupBeforeMigrating() error {
+	if db.path == "" {
+		return nil
+	}
+	var tables int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'requests'").Scan(&tables); err != nil {
+		return err
+	}
+	if tables == 0 {
+		return nil
+	}
+	if _, _, err := db.BackupInto(BackupDir(db.path), "pre-migration"); err != nil {
+		return fmt.Errorf("failed to back up the database before migrating it: %w", err)
+	}
+	return nil
+}
+
+// Path returns the file the database is stored in
+func (db *DB) Path() string {
+	return db.path
+}
+
+// BackupTo copies the database into a new file at path using SQLite's online backup API, which
+// gives a consistent snapshot while the app keeps using the database
+func (db *DB) BackupTo(path string) error {
+	target, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return fmt.Errorf("failed to open backup file: %w", err)
+	}
+	defer target.Close()
+
+	return copyDatabase(target, db.DB)
+}
+
+// CopyFile copies the database at source into a new file at target using SQLite's online
+// backup API. The source is opened read-only, so it is neither migrated nor written.
+func CopyFile(source, target string) error {
+	from, err := sql.Open("sqlite3", "file:"+source+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer from.Close()
+
+	to, err := sql.Open("sqlite3", target)
+	if err != nil {
+		return fmt.Errorf("failed to open copy: %w", err)
+	}
+	defer to.Close()
+
+	return copyDatabase(to, from)
+}
+
+// RestoreFrom replaces the content of the database with the backup at path. A backup written by
+// a newer version of the app is refused, and an older one is migrated to the current schema.
+func (db *DB) RestoreFrom(path string) error {
+	source, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("failed to open backup file: %w", err)
+	}
+	defer source.Close()
+
+	applied, err := backupMigrations(source)
+	if err != nil {
+		return err
+	}
+	if err := checkSchemaVersion(applied); err != nil {
+		return fmt.Errorf("cannot restore the backup: %w", err)
+	}
+
+	if err := copyDatabase(db.DB, source); err != nil {
+		return err
+	}
+	if err := db.Migrate(); err != nil {
+		return fmt.Errorf("failed to migrate the restored database: %w", err)
+	}
+	return nil
+}
+
+// backupMigrations returns the migrations applied to a backup, which has none recorded when it
+// predates migration tracking
+func backupMigrations(source *sql.DB) (map[int]time.Time, error) {
+	var count int
+	err := source.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'").Scan(&count)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup file: %w", err)
+	}
+	if count == 0 {
+		return map[int]time.Time{}, nil
+	}
+	return appliedMigrations(source)
+}
+
+func copyDatabase(target, source *sql.DB) error {
+	ctx := context.Background()
+
+	targetConn, err := target.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer targetConn.Close()
+
+	sourceConn, err := source.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer sourceConn.Close()
+
+	return targetConn.Raw(func(targetDriver interface{}) error {
+		return sourceConn.Raw(func(sourceDriver interface{}) error {
+			backup, err := targetDriver.(*sqlite3.SQLiteConn).Backup("main", sourceDriver.(*sqlite3.SQLiteConn), "main")
+			if err != nil {
+				return fmt.Errorf("failed to start backup: %w", err)
+			}
+
+			// Copy all pages in one step so the snapshot is consistent
+			if _, err := backup.Step(-1); err != nil {
+				backup.Finish()
+				return fmt.Errorf("failed to copy database: %w", err)
+			}
+			return backup.Finish()
+		})
+	})
+}
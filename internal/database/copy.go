@@ -0,0 +1,401 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"rikuest/internal/models"
+)
+
+// Copies are deep: a folder is copied with every nested folder and request, and a request with
+// its response examples. History and revisions stay with the original. Each operation runs in
+// one transaction and leaves the items at the destination numbered from zero.
+
+// requestColumns are the columns copied from one request row to another
+const requestColumns = `name, description, method, url, headers, body, query_params, auth_type, bearer_token,
+			  basic_auth, body_type, form_data, expected_status`
+
+// CopyRequest copies a request and its examples to a folder, possibly in another project, and
+// returns the ID of the copy
+func (db *DB) CopyRequest(requestID int, target models.CopyTarget) (int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := checkTarget(tx, target); err != nil {
+		return 0, err
+	}
+	position, err := nextPosition(tx, "requests", "folder_id", target.ProjectID, target.FolderID)
+	if err != nil {
+		return 0, err
+	}
+	id, err := copyRequestRow(tx, requestID, target.ProjectID, target.FolderID, position, target.Name)
+	if err != nil {
+		return 0, err
+	}
+	if err := place(tx, "requests", "folder_id", target, id); err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+// CopyFolder copies a folder with everything nested in it to a folder, possibly in another
+// project, and returns the ID of the copy. Copying a folder into one of its own
+// subfolders copies what the folder holds before the copy.
+func (db *DB) CopyFolder(folderID int, target models.CopyTarget) (int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := checkTarget(tx, target); err != nil {
+		return 0, err
+	}
+	tree, err := loadFolderTree(tx, folderID)
+	if err != nil {
+		return 0, err
+	}
+	position, err := nextPosition(tx, "folders", "parent_id", target.ProjectID, target.FolderID)
+	if err != nil {
+		return 0, err
+	}
+	id, err := tree.copy(tx, folderID, target.ProjectID, target.FolderID, position, target.Name)
+	if err != nil {
+		return 0, err
+	}
+	if err := place(tx, "folders", "parent_id", target, id); err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+// CopyProjectContents copies the folders and requests of a project to the end of a folder of
+// another project, or of the same one. Environments, tags and saved filters are copied as well
+// when whole is set, which is meant for copies into a new project.
+func (db *DB) CopyProjectContents(projectID int, target models.CopyTarget, whole bool) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkTarget(tx, target); err != nil {
+		return err
+	}
+
+	// The project root is read as a whole first, so a project copied into itself is copied once
+	tree, err := loadTree(tx, `SELECT id, name, parent_id FROM folders WHERE project_id = ? AND deleted_at IS NULL
+			  ORDER BY position, id`, projectID)
+	if err != nil {
+		return err
+	}
+	rootRequests, err := queryIDs(tx, `SELECT id FROM requests WHERE project_id = ? AND folder_id IS NULL AND deleted_at IS NULL
+			  ORDER BY position, id`, projectID)
+	if err != nil {
+		return err
+	}
+
+	position, err := nextPosition(tx, "folders", "parent_id", target.ProjectID, target.FolderID)
+	if err != nil {
+		return err
+	}
+	for i, id := range tree.children[0] {
+		if _, err := tree.copy(tx, id, target.ProjectID, target.FolderID, position+i, ""); err != nil {
+			return err
+		}
+	}
+	position, err = nextPosition(tx, "requests", "folder_id", target.ProjectID, target.FolderID)
+	if err != nil {
+		return err
+	}
+	for i, id := range rootRequests {
+		if _, err := copyRequestRow(tx, id, target.ProjectID, target.FolderID, position+i, ""); err != nil {
+			return err
+		}
+	}
+
+	if whole {
+		queries := []string{
+			`INSERT INTO environments (project_id, name, variables, is_active)
+				  SELECT ?, name, variables, is_active FROM environments WHERE project_id = ? ORDER BY id`,
+			`INSERT OR IGNORE INTO tags (project_id, name, color)
+				  SELECT ?, name, color FROM tags WHERE project_id = ? ORDER BY id`,
+			`INSERT INTO saved_filters (project_id, name, filter)
+				  SELECT ?, name, filter FROM saved_filters WHERE project_id = ? ORDER BY id`,
+		}
+		for _, query := range queries {
+			if _, err := tx.Exec(query, target.ProjectID, projectID); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := renumber(tx, "folders", "parent_id", target.ProjectID, target.FolderID); err != nil {
+		return err
+	}
+	if err := renumber(tx, "requests", "folder_id", target.ProjectID, target.FolderID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// MoveFolder moves a folder with everything nested in it, trashed items included, to another
+// folder or project root, at the target position or at the end. A folder cannot be moved into itself or its subfolders.
+func (db *DB) MoveFolder(folderID int, target models.CopyTarget) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var projectID int
+	var parentID *int
+	err = tx.QueryRow("SELECT project_id, parent_id FROM folders WHERE id = ? AND deleted_at IS NULL", folderID).Scan(
+		&projectID, &parentID)
+	if err != nil {
+		return fmt.Errorf("folder not found: %w", err)
+	}
+	if err := checkTarget(tx, target); err != nil {
+		return err
+	}
+
+	if target.FolderID != nil {
+		if err := checkFolderCycle(tx, folderID, *target.FolderID); err != nil {
+			return err
+		}
+	}
+
+	position, err := nextPosition(tx, "folders", "parent_id", target.ProjectID, target.FolderID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("UPDATE folders SET parent_id = ?, position = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?",
+		target.FolderID, position, folderID)
+	if err != nil {
+		return err
+	}
+
+	if target.ProjectID != projectID {
+		subtree := `WITH RECURSIVE subtree(id) AS (
+				SELECT ? UNION SELECT f.id FROM folders f JOIN subtree s ON f.parent_id = s.id
+			)`
+		requests := `(SELECT id FROM requests WHERE folder_id IN (SELECT id FROM subtree))`
+		queries := []struct {
+			query string
+			args  []interface{}
+		}{
+			// Operations imported from an OpenAPI document stay with the project of the document
+			{subtree + ` DELETE FROM openapi_operations WHERE request_id IN ` + requests, []interface{}{folderID}},
+			{subtree + ` UPDATE trash SET project_id = ? WHERE id IN
+				(SELECT trash_id FROM folders WHERE id IN (SELECT id FROM subtree)
+				 UNION SELECT trash_id FROM requests WHERE id IN ` + requests + `)`, []interface{}{folderID, target.ProjectID}},
+			{subtree + ` UPDATE requests SET project_id = ? WHERE id IN ` + requests, []interface{}{folderID, target.ProjectID}},
+			{subtree + ` UPDATE folders SET project_id = ? WHERE id IN (SELECT id FROM subtree)`, []interface{}{folderID, target.ProjectID}},
+		}
+		for _, q := range queries {
+			if _, err := tx.Exec(q.query, q.args...); err != nil {
+				return err
+			}
+		}
+
+		// Tags are defined per project, so moved requests get the tags of the new one
+		moved, err := queryIDs(tx, subtree+` SELECT id FROM requests WHERE folder_id IN (SELECT id FROM subtree)`, folderID)
+		if err != nil {
+			return err
+		}
+		for _, requestID := range moved {
+			if err := moveRequestTags(tx, requestID, target.ProjectID); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := renumber(tx, "folders", "parent_id", projectID, parentID); err != nil {
+		return err
+	}
+	if err := place(tx, "folders", "parent_id", target, folderID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// MoveRequestTo moves a request to a folder or project root, possibly in another project, at the
+// target position or at the end
+func (db *DB) MoveRequestTo(requestID int, target models.CopyTarget) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var projectID int
+	var folderID *int
+	err = tx.QueryRow("SELECT project_id, folder_id FROM requests WHERE id = ? AND deleted_at IS NULL", requestID).Scan(
+		&projectID, &folderID)
+	if err != nil {
+		return fmt.Errorf("request not found: %w", err)
+	}
+	if err := checkTarget(tx, target); err != nil {
+		return err
+	}
+
+	position, err := nextPosition(tx, "requests", "folder_id", target.ProjectID, target.FolderID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`UPDATE requests SET project_id = ?, folder_id = ?, position = ?, updated_at = CURRENT_TIMESTAMP
+			  WHERE id = ?`, target.ProjectID, target.FolderID, position, requestID)
+	if err != nil {
+		return err
+	}
+	if target.ProjectID != projectID {
+		if _, err := tx.Exec("DELETE FROM openapi_operations WHERE request_id = ?", requestID); err != nil {
+			return err
+		}
+		if err := moveRequestTags(tx, requestID, target.ProjectID); err != nil {
+			return err
+		}
+	}
+
+	if err := renumber(tx, "requests", "folder_id", projectID, folderID); err != nil {
+		return err
+	}
+	if err := place(tx, "requests", "folder_id", target, requestID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// checkTarget makes sure the target project exists and the target folder belongs to it
+func checkTarget(tx *Tx, target models.CopyTarget) error {
+	var exists bool
+	err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM projects WHERE id = ? AND deleted_at IS NULL)", target.ProjectID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("target project not found")
+	}
+	if target.FolderID == nil {
+		return nil
+	}
+
+	err = tx.QueryRow("SELECT EXISTS (SELECT 1 FROM folders WHERE id = ? AND project_id = ? AND deleted_at IS NULL)",
+		*target.FolderID, target.ProjectID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("target folder not found in the target project")
+	}
+	return nil
+}
+
+// copyRequestRow inserts a copy of a request with its examples and tags. An empty name keeps the name of
+// the original.
+func copyRequestRow(tx *Tx, requestID, projectID int, folderID *int, position int, name string) (int, error) {
+	result, err := tx.Exec(`INSERT INTO requests (project_id, folder_id, position, `+requestColumns+`)
+			  SELECT ?, ?, ?, `+requestColumns+` FROM requests WHERE id = ? AND deleted_at IS NULL`,
+		projectID, folderID, position, requestID)
+	if err != nil {
+		return 0, err
+	}
+	if copied, err := result.RowsAffected(); err != nil || copied == 0 {
+		return 0, fmt.Errorf("request not found")
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	if name != "" {
+		if _, err := tx.Exec("UPDATE requests SET name = ? WHERE id = ?", name, id); err != nil {
+			return 0, err
+		}
+	}
+	_, err = tx.Exec(`INSERT INTO request_examples (request_id, name, status, headers, body)
+			  SELECT ?, name, status, headers, body FROM request_examples WHERE request_id = ? ORDER BY created_at, id`,
+		id, requestID)
+	if err != nil {
+		return 0, err
+	}
+	return int(id), copyRequestTags(tx, requestID, int(id), projectID)
+}
+
+// folderTree is a snapshot of folders taken before copying them. Root folders are listed under
+// the parent 0.
+type folderTree struct {
+	names    map[int]string
+	children map[int][]int
+}
+
+// loadFolderTree reads a folder and its subfolders
+func loadFolderTree(tx *Tx, folderID int) (*folderTree, error) {
+	tree, err := loadTree(tx, `WITH RECURSIVE subtree(id) AS (
+				SELECT id FROM folders WHERE id = ? AND deleted_at IS NULL
+				UNION SELECT f.id FROM folders f JOIN subtree s ON f.parent_id = s.id WHERE f.deleted_at IS NULL
+			)
+			SELECT id, name, parent_id FROM folders WHERE id IN (SELECT id FROM subtree) ORDER BY position, id`, folderID)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := tree.names[folderID]; !ok {
+		return nil, fmt.Errorf("folder not found")
+	}
+	return tree, nil
+}
+
+func loadTree(tx *Tx, query string, args ...interface{}) (*folderTree, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tree := &folderTree{names: make(map[int]string), children: make(map[int][]int)}
+	for rows.Next() {
+		var id int
+		var name string
+		var parentID sql.NullInt64
+		if err := rows.Scan(&id, &name, &parentID); err != nil {
+			return nil, err
+		}
+		tree.names[id] = name
+		tree.children[int(parentID.Int64)] = append(tree.children[int(parentID.Int64)], id)
+	}
+	return tree, rows.Err()
+}
+
+// copy inserts a copy of a folder of the tree, then of its requests and subfolders in their
+// order, and returns the ID of the copy. An empty name keeps the name of the original.
+func (t *folderTree) copy(tx *Tx, folderID, projectID int, parentID *int, position int, name string) (int, error) {
+	if name == "" {
+		name = t.names[folderID]
+	}
+	var id int
+	err := tx.QueryRow(`INSERT INTO folders (project_id, name, description, parent_id, position)
+			  SELECT ?, ?, description, ?, ? FROM folders WHERE id = ? RETURNING id`,
+		projectID, name, parentID, position, folderID).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	requests, err := queryIDs(tx, `SELECT id FROM requests WHERE folder_id = ? AND deleted_at IS NULL ORDER BY position, id`, folderID)
+	if err != nil {
+		return 0, err
+	}
+	for i, requestID := range requests {
+		if _, err := copyRequestRow(tx, requestID, projectID, &id, i, ""); err != nil {
+			return 0, err
+		}
+	}
+	for i, childID := range t.children[folderID] {
+		if _, err := t.copy(tx, childID, projectID, &id, i, ""); err != nil {
+			return 0, err
+		}
+	}
+	return id, nil
+}
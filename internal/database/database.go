@@ -4,7 +4,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
-	"time"
+	"strings"
 
 	"rikuest/internal/models"
 
@@ -13,10 +13,18 @@ import (
 
 type DB struct {
 	*sql.DB
+	path string
+
+	// tx is the transaction of a database passed to an InTransaction function, in which every
+	// query runs, and savepoints counts the savepoints opened in it
+	tx         *sql.Tx
+	savepoints *int
 }
 
+// NewDB opens the database and brings its schema up to date. Foreign keys are enforced on every
+// connection.
 func NewDB(dataSourceName string) (*DB, error) {
-	db, err := sql.Open("sqlite3", dataSourceName)
+	db, err := sql.Open("sqlite3", withForeignKeys(dataSourceName))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -25,14 +33,9 @@ func NewDB(dataSourceName string) (*DB, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	database := &DB{db}
-	if err := database.createTables(); err != nil {
-		return nil, fmt.Errorf("failed to create tables: %w", err)
-	}
-
-	// Run migrations for new columns
-	if err := database.migrateRequestsTable(); err != nil {
-		return nil, fmt.Errorf("failed to migrate requests table: %w", err)
+	database := &DB{DB: db, path: databasePath(dataSourceName)}
+	if err := database.Migrate(); err != nil {
+		return nil, err
 	}
 
 	// Initialize default settings
@@ -48,6 +51,15 @@ func NewDB(dataSourceName string) (*DB, error) {
 	return database, nil
 }
 
+// withForeignKeys adds the option enabling foreign keys to a data source name. SQLite turns them
+// off by default, and the option applies them to each connection of the pool.
+func withForeignKeys(dataSourceName string) string {
+	if strings.Contains(dataSourceName, "?") {
+		return dataSourceName + "&_foreign_keys=1"
+	}
+	return dataSourceName + "?_foreign_keys=1"
+}
+
 func (db *DB) initializeTelemetryConfig() error {
 	// Check if config exists
 	var count int
@@ -65,120 +77,21 @@ func (db *DB) initializeTelemetryConfig() error {
 	return nil
 }
 
-func (db *DB) createTables() error {
-	queries := []string{
-		`CREATE TABLE IF NOT EXISTS projects (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			name TEXT NOT NULL UNIQUE,
-			description TEXT,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE TABLE IF NOT EXISTS folders (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			project_id INTEGER NOT NULL,
-			name TEXT NOT NULL,
-			parent_id INTEGER,
-			position INTEGER DEFAULT 0,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
-			FOREIGN KEY (parent_id) REFERENCES folders(id) ON DELETE CASCADE
-		)`,
-		`CREATE TABLE IF NOT EXISTS requests (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			project_id INTEGER NOT NULL,
-			folder_id INTEGER,
-			name TEXT NOT NULL,
-			method TEXT NOT NULL DEFAULT 'GET',
-			url TEXT NOT NULL,
-			headers TEXT DEFAULT '{}',
-			body TEXT DEFAULT '',
-			query_params TEXT DEFAULT '[]',
-			auth_type TEXT DEFAULT 'none',
-			bearer_token TEXT DEFAULT '',
-			basic_auth TEXT DEFAULT '{}',
-			body_type TEXT DEFAULT 'none',
-			form_data TEXT DEFAULT '[]',
-			position INTEGER DEFAULT 0,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
-			FOREIGN KEY (folder_id) REFERENCES folders(id) ON DELETE SET NULL
-		)`,
-		`CREATE TABLE IF NOT EXISTS request_history (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			request_id INTEGER NOT NULL,
-			response TEXT NOT NULL,
-			executed_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (request_id) REFERENCES requests(id) ON DELETE CASCADE
-		)`,
-		`CREATE TABLE IF NOT EXISTS settings (
-			key TEXT PRIMARY KEY,
-			value TEXT NOT NULL,
-			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE TABLE IF NOT EXISTS telemetry_config (
-			id INTEGER PRIMARY KEY,
-			enabled INTEGER DEFAULT 1,
-			webhook_url TEXT DEFAULT '',
-			installation_id TEXT DEFAULT ''
-		)`,
-		`CREATE TABLE IF NOT EXISTS telemetry_events_cache (
-			event_hash TEXT PRIMARY KEY,
-			timestamp DATETIME DEFAULT CURRENT_TIMESTAMP
-		)`,
-	}
-
-	for _, query := range queries {
-		if _, err := db.Exec(query); err != nil {
-			return fmt.Errorf("failed to execute query: %w", err)
-		}
-	}
-
-	return nil
-}
-
-func (db *DB) migrateRequestsTable() error {
-	// Add new columns if they don't exist
-	migrations := []string{
-		`ALTER TABLE requests ADD COLUMN query_params TEXT DEFAULT '[]'`,
-		`ALTER TABLE requests ADD COLUMN auth_type TEXT DEFAULT 'none'`,
-		`ALTER TABLE requests ADD COLUMN bearer_token TEXT DEFAULT ''`,
-		`ALTER TABLE requests ADD COLUMN basic_auth TEXT DEFAULT '{}'`,
-		`ALTER TABLE requests ADD COLUMN body_type TEXT DEFAULT 'none'`,
-		`ALTER TABLE requests ADD COLUMN form_data TEXT DEFAULT '[]'`,
-		`ALTER TABLE requests ADD COLUMN folder_id INTEGER`,
-		`ALTER TABLE requests ADD COLUMN position INTEGER DEFAULT 0`,
-	}
-
-	for _, migration := range migrations {
-		_, err := db.Exec(migration)
-		if err != nil && !isColumnExistsError(err) {
-			return fmt.Errorf("migration failed: %w", err)
-		}
-	}
-
-	return nil
-}
-
-func isColumnExistsError(err error) bool {
-	errStr := fmt.Sprintf("%s", err)
-	return err != nil && (errStr == "duplicate column name: query_params" ||
-		errStr == "duplicate column name: auth_type" ||
-		errStr == "duplicate column name: bearer_token" ||
-		errStr == "duplicate column name: basic_auth" ||
-		errStr == "duplicate column name: body_type" ||
-		errStr == "duplicate column name: form_data" ||
-		errStr == "duplicate column name: folder_id" ||
-		errStr == "duplicate column name: position")
-}
-
 func (db *DB) initializeDefaultSettings() error {
-	// Set default timeout to 5 minutes (300 seconds) if not exists
+	// Set default timeout to 5 minutes (300 seconds), daily backups keeping the last 10,
+	// 30 days in the trash, unlimited history and response diffs ignoring the Date header if
+	// not exists
 	_, err := db.Exec(`
 		INSERT OR IGNORE INTO settings (key, value) 
-		VALUES ('request_timeout_seconds', '300')
+		VALUES ('request_timeout_seconds', '300'),
+		       ('backup_interval_hours', '24'),
+		       ('backup_retention', '10'),
+		       ('trash_retention_days', '30'),
+		       ('history_max_entries', '0'),
+		       ('history_max_age_days', '0'),
+		       ('history_max_size_mb', '0'),
+		       ('diff_ignore_headers', '["Date"]'),
+		       ('diff_ignore_paths', '[]')
 	`)
 	return err
 }
@@ -201,6 +114,12 @@ func (db *DB) SetSetting(key, value string) error {
 	return err
 }
 
+// DeleteSetting removes a setting, if set
+func (db *DB) DeleteSetting(key string) error {
+	_, err := db.Exec("DELETE FROM settings WHERE key = ?", key)
+	return err
+}
+
 func (db *DB) CreateProject(project *models.Project) error {
 	query := `INSERT INTO projects (name, description) VALUES (?, ?) RETURNING id, created_at, updated_at`
 	err := db.QueryRow(query, project.Name, project.Description).Scan(
@@ -210,7 +129,7 @@ func (db *DB) CreateProject(project *models.Project) error {
 }
 
 func (db *DB) GetProjects() ([]models.Project, error) {
-	query := `SELECT id, name, description, created_at, updated_at FROM projects ORDER BY created_at DESC`
+	query := `SELECT id, name, description, created_at, updated_at FROM projects WHERE deleted_at IS NULL ORDER BY created_at DESC`
 	rows, err := db.Query(query)
 	if err != nil {
 		return nil, err
@@ -231,7 +150,7 @@ func (db *DB) GetProjects() ([]models.Project, error) {
 }
 
 func (db *DB) GetProject(id int) (*models.Project, error) {
-	query := `SELECT id, name, description, created_at, updated_at FROM projects WHERE id = ?`
+	query := `SELECT id, name, description, created_at, updated_at FROM projects WHERE id = ? AND deleted_at IS NULL`
 	var project models.Project
 	err := db.QueryRow(query, id).Scan(
 		&project.ID, &project.Name, &project.Description, &project.CreatedAt, &project.UpdatedAt,
@@ -248,46 +167,51 @@ func (db *DB) UpdateProject(project *models.Project) error {
 	return err
 }
 
-func (db *DB) DeleteProject(id int) error {
-	query := `DELETE FROM projects WHERE id = ?`
-	_, err := db.Exec(query, id)
-	return err
-}
-
+// CreateRequest adds a request at the end of its folder, with its tags in the same transaction
 func (db *DB) CreateRequest(request *models.Request) error {
 	headersJSON, _ := json.Marshal(request.Headers)
 	queryParamsJSON, _ := json.Marshal(request.QueryParams)
 	basicAuthJSON, _ := json.Marshal(request.BasicAuth)
 	formDataJSON, _ := json.Marshal(request.FormData)
 
-	// Get the next position for this folder (or root level)
-	var maxPosition int
-	if request.FolderID == nil {
-		db.QueryRow("SELECT COALESCE(MAX(position), -1) FROM requests WHERE project_id = ? AND folder_id IS NULL",
-			request.ProjectID).Scan(&maxPosition)
-	} else {
-		db.QueryRow("SELECT COALESCE(MAX(position), -1) FROM requests WHERE project_id = ? AND folder_id = ?",
-			request.ProjectID, request.FolderID).Scan(&maxPosition)
-	}
-	request.Position = maxPosition + 1
-
-	query := `INSERT INTO requests (project_id, folder_id, name, method, url, headers, body, 
-			  query_params, auth_type, bearer_token, basic_auth, body_type, form_data, position) 
-			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id, created_at, updated_at`
-	err := db.QueryRow(query, request.ProjectID, request.FolderID, request.Name, request.Method,
-		request.URL, string(headersJSON), request.Body, string(queryParamsJSON),
-		request.AuthType, request.BearerToken, string(basicAuthJSON),
-		request.BodyType, string(formDataJSON), request.Position).Scan(
-		&request.ID, &request.CreatedAt, &request.UpdatedAt,
-	)
-	return err
+	return db.InTransaction(func(tx *DB) error {
+		// Get the next position for this folder (or root level)
+		var maxPosition int
+		if request.FolderID == nil {
+			tx.QueryRow("SELECT COALESCE(MAX(position), -1) FROM requests WHERE project_id = ? AND folder_id IS NULL",
+				request.ProjectID).Scan(&maxPosition)
+		} else {
+			tx.QueryRow("SELECT COALESCE(MAX(position), -1) FROM requests WHERE project_id = ? AND folder_id = ?",
+				request.ProjectID, request.FolderID).Scan(&maxPosition)
+		}
+		request.Position = maxPosition + 1
+
+		query := `INSERT INTO requests (project_id, folder_id, name, description, method, url, headers, body, 
+			  query_params, auth_type, bearer_token, basic_auth, body_type, form_data, expected_status, position) 
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id, created_at, updated_at`
+		err := tx.QueryRow(query, request.ProjectID, request.FolderID, request.Name, request.Description, request.Method,
+			request.URL, string(headersJSON), request.Body, string(queryParamsJSON),
+			request.AuthType, request.BearerToken, string(basicAuthJSON),
+			request.BodyType, string(formDataJSON), request.ExpectedStatus, request.Position).Scan(
+			&request.ID, &request.CreatedAt, &request.UpdatedAt,
+		)
+		if err != nil || request.Tags == nil {
+			return err
+		}
+		return tx.SetRequestTags(request.ID, request.Tags)
+	})
 }
 
 func (db *DB) GetRequests(projectID int) ([]models.Request, error) {
-	query := `SELECT id, project_id, folder_id, name, method, url, headers, body, query_params, 
-			  auth_type, bearer_token, basic_auth, body_type, form_data, position, created_at, updated_at 
-			  FROM requests WHERE project_id = ? ORDER BY position ASC, created_at DESC`
-	rows, err := db.Query(query, projectID)
+	return db.queryRequests("project_id = ? AND deleted_at IS NULL", projectID)
+}
+
+// queryRequests returns the requests matching where, in their folder order, with their tags
+func (db *DB) queryRequests(where string, args ...interface{}) ([]models.Request, error) {
+	query := `SELECT id, project_id, folder_id, name, description, method, url, headers, body, query_params, 
+			  auth_type, bearer_token, basic_auth, body_type, form_data, expected_status, position, created_at, updated_at 
+			  FROM requests WHERE ` + where + ` ORDER BY position ASC, created_at DESC`
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -298,10 +222,10 @@ func (db *DB) GetRequests(projectID int) ([]models.Request, error) {
 		var request models.Request
 		var headersJSON, queryParamsJSON, basicAuthJSON, formDataJSON string
 		var folderID *int
-		err := rows.Scan(&request.ID, &request.ProjectID, &folderID, &request.Name, &request.Method,
+		err := rows.Scan(&request.ID, &request.ProjectID, &folderID, &request.Name, &request.Description, &request.Method,
 			&request.URL, &headersJSON, &request.Body, &queryParamsJSON,
 			&request.AuthType, &request.BearerToken, &basicAuthJSON,
-			&request.BodyType, &formDataJSON, &request.Position, &request.CreatedAt, &request.UpdatedAt)
+			&request.BodyType, &formDataJSON, &request.ExpectedStatus, &request.Position, &request.CreatedAt, &request.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -312,22 +236,29 @@ func (db *DB) GetRequests(projectID int) ([]models.Request, error) {
 		json.Unmarshal([]byte(formDataJSON), &request.FormData)
 		requests = append(requests, request)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
 
+	if err := db.attachTags(requests); err != nil {
+		return nil, err
+	}
 	return requests, nil
 }
 
 func (db *DB) GetRequest(id int) (*models.Request, error) {
-	query := `SELECT id, project_id, folder_id, name, method, url, headers, body, query_params, 
-			  auth_type, bearer_token, basic_auth, body_type, form_data, position, created_at, updated_at 
-			  FROM requests WHERE id = ?`
+	query := `SELECT id, project_id, folder_id, name, description, method, url, headers, body, query_params, 
+			  auth_type, bearer_token, basic_auth, body_type, form_data, expected_status, position, created_at, updated_at 
+			  FROM requests WHERE id = ? AND deleted_at IS NULL`
 	var request models.Request
 	var headersJSON, queryParamsJSON, basicAuthJSON, formDataJSON string
 	var folderID *int
 	err := db.QueryRow(query, id).Scan(
-		&request.ID, &request.ProjectID, &folderID, &request.Name, &request.Method,
+		&request.ID, &request.ProjectID, &folderID, &request.Name, &request.Description, &request.Method,
 		&request.URL, &headersJSON, &request.Body, &queryParamsJSON,
 		&request.AuthType, &request.BearerToken, &basicAuthJSON,
-		&request.BodyType, &formDataJSON, &request.Position, &request.CreatedAt, &request.UpdatedAt,
+		&request.BodyType, &formDataJSON, &request.ExpectedStatus, &request.Position, &request.CreatedAt, &request.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -337,79 +268,94 @@ func (db *DB) GetRequest(id int) (*models.Request, error) {
 	json.Unmarshal([]byte(queryParamsJSON), &request.QueryParams)
 	json.Unmarshal([]byte(basicAuthJSON), &request.BasicAuth)
 	json.Unmarshal([]byte(formDataJSON), &request.FormData)
-	return &request, nil
+
+	requests := []models.Request{request}
+	if err := db.attachTags(requests); err != nil {
+		return nil, err
+	}
+	return &requests[0], nil
 }
 
+// UpdateRequest saves a request, keeping a revision of its previous state
 func (db *DB) UpdateRequest(request *models.Request) error {
-	headersJSON, _ := json.Marshal(request.Headers)
-	queryParamsJSON, _ := json.Marshal(request.QueryParams)
-	basicAuthJSON, _ := json.Marshal(request.BasicAuth)
-	formDataJSON, _ := json.Marshal(request.FormData)
+	return db.UpdateRequestBy(request, "")
+}
 
-	query := `UPDATE requests SET name = ?, method = ?, url = ?, headers = ?, body = ?, 
-			  query_params = ?, auth_type = ?, bearer_token = ?, basic_auth = ?, 
-			  body_type = ?, form_data = ?, folder_id = ?, position = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
-	_, err := db.Exec(query, request.Name, request.Method, request.URL,
-		string(headersJSON), request.Body, string(queryParamsJSON),
-		request.AuthType, request.BearerToken, string(basicAuthJSON),
-		request.BodyType, string(formDataJSON), request.FolderID, request.Position, request.ID)
+// Response example operations
+func (db *DB) CreateRequestExample(example *models.ResponseExample) error {
+	headersJSON, _ := json.Marshal(example.Headers)
+	query := `INSERT INTO request_examples (request_id, name, status, headers, body) 
+			  VALUES (?, ?, ?, ?, ?) RETURNING id, created_at, updated_at`
+	err := db.QueryRow(query, example.RequestID, example.Name, example.Status,
+		string(headersJSON), example.Body).Scan(
+		&example.ID, &example.CreatedAt, &example.UpdatedAt,
+	)
 	return err
 }
 
-func (db *DB) DeleteRequest(id int) error {
-	query := `DELETE FROM requests WHERE id = ?`
-	_, err := db.Exec(query, id)
-	return err
+func (db *DB) GetRequestExamples(requestID int) ([]models.ResponseExample, error) {
+	query := `SELECT id, request_id, name, status, headers, body, created_at, updated_at 
+			  FROM request_examples WHERE request_id = ? ORDER BY created_at ASC, id ASC`
+	return db.queryRequestExamples(query, requestID)
 }
 
-func (db *DB) SaveRequestHistory(history *models.RequestHistory) error {
-	responseJSON, _ := json.Marshal(history.Response)
-	query := `INSERT INTO request_history (request_id, response, executed_at) VALUES (?, ?, ?)`
-	_, err := db.Exec(query, history.RequestID, string(responseJSON), time.Now())
-	return err
+// GetProjectExamples returns the examples of every request in a project
+func (db *DB) GetProjectExamples(projectID int) ([]models.ResponseExample, error) {
+	query := `SELECT e.id, e.request_id, e.name, e.status, e.headers, e.body, e.created_at, e.updated_at 
+			  FROM request_examples e JOIN requests r ON r.id = e.request_id 
+			  WHERE r.project_id = ? AND r.deleted_at IS NULL ORDER BY e.created_at ASC, e.id ASC`
+	return db.queryRequestExamples(query, projectID)
 }
 
-func (db *DB) GetRequestHistory(requestID int) ([]models.RequestHistory, error) {
-	query := `SELECT id, request_id, response, executed_at FROM request_history 
-			  WHERE request_id = ? ORDER BY executed_at DESC LIMIT 10`
-	rows, err := db.Query(query, requestID)
+func (db *DB) queryRequestExamples(query string, args ...interface{}) ([]models.ResponseExample, error) {
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var history []models.RequestHistory
+	var examples []models.ResponseExample
 	for rows.Next() {
-		var h models.RequestHistory
-		var responseJSON string
-		err := rows.Scan(&h.ID, &h.RequestID, &responseJSON, &h.ExecutedAt)
+		var example models.ResponseExample
+		var headersJSON string
+		err := rows.Scan(&example.ID, &example.RequestID, &example.Name, &example.Status,
+			&headersJSON, &example.Body, &example.CreatedAt, &example.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
-		json.Unmarshal([]byte(responseJSON), &h.Response)
-		history = append(history, h)
+		json.Unmarshal([]byte(headersJSON), &example.Headers)
+		examples = append(examples, example)
 	}
 
-	return history, nil
+	return examples, nil
 }
 
-func (db *DB) DeleteRequestHistoryItem(requestID int, historyID int) error {
-	query := `DELETE FROM request_history WHERE id = ? AND request_id = ?`
-	result, err := db.Exec(query, historyID, requestID)
-	if err != nil {
-		return err
-	}
-
-	rowsAffected, err := result.RowsAffected()
+func (db *DB) GetRequestExample(id int) (*models.ResponseExample, error) {
+	query := `SELECT id, request_id, name, status, headers, body, created_at, updated_at 
+			  FROM request_examples WHERE id = ?`
+	var example models.ResponseExample
+	var headersJSON string
+	err := db.QueryRow(query, id).Scan(&example.ID, &example.RequestID, &example.Name, &example.Status,
+		&headersJSON, &example.Body, &example.CreatedAt, &example.UpdatedAt)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	json.Unmarshal([]byte(headersJSON), &example.Headers)
+	return &example, nil
+}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("history item not found or does not belong to this request")
-	}
+func (db *DB) UpdateRequestExample(example *models.ResponseExample) error {
+	headersJSON, _ := json.Marshal(example.Headers)
+	query := `UPDATE request_examples SET name = ?, status = ?, headers = ?, body = ?, 
+			  updated_at = CURRENT_TIMESTAMP WHERE id = ?`
+	_, err := db.Exec(query, example.Name, example.Status, string(headersJSON), example.Body, example.ID)
+	return err
+}
 
-	return nil
+func (db *DB) DeleteRequestExample(id int) error {
+	query := `DELETE FROM request_examples WHERE id = ?`
+	_, err := db.Exec(query, id)
+	return err
 }
 
 // Folder operations
@@ -425,17 +371,17 @@ func (db *DB) CreateFolder(folder *models.Folder) error {
 	}
 	folder.Position = maxPosition + 1
 
-	query := `INSERT INTO folders (project_id, name, parent_id, position) 
-			  VALUES (?, ?, ?, ?) RETURNING id, created_at, updated_at`
-	err := db.QueryRow(query, folder.ProjectID, folder.Name, folder.ParentID, folder.Position).Scan(
+	query := `INSERT INTO folders (project_id, name, description, parent_id, position) 
+			  VALUES (?, ?, ?, ?, ?) RETURNING id, created_at, updated_at`
+	err := db.QueryRow(query, folder.ProjectID, folder.Name, folder.Description, folder.ParentID, folder.Position).Scan(
 		&folder.ID, &folder.CreatedAt, &folder.UpdatedAt,
 	)
 	return err
 }
 
 func (db *DB) GetFolders(projectID int) ([]models.Folder, error) {
-	query := `SELECT id, project_id, name, parent_id, position, created_at, updated_at 
-			  FROM folders WHERE project_id = ? ORDER BY position ASC`
+	query := `SELECT id, project_id, name, description, parent_id, position, created_at, updated_at 
+			  FROM folders WHERE project_id = ? AND deleted_at IS NULL ORDER BY position ASC`
 	rows, err := db.Query(query, projectID)
 	if err != nil {
 		return nil, err
@@ -446,7 +392,7 @@ func (db *DB) GetFolders(projectID int) ([]models.Folder, error) {
 	for rows.Next() {
 		var folder models.Folder
 		var parentID *int
-		err := rows.Scan(&folder.ID, &folder.ProjectID, &folder.Name, &parentID,
+		err := rows.Scan(&folder.ID, &folder.ProjectID, &folder.Name, &folder.Description, &parentID,
 			&folder.Position, &folder.CreatedAt, &folder.UpdatedAt)
 		if err != nil {
 			return nil, err
@@ -458,28 +404,359 @@ func (db *DB) GetFolders(projectID int) ([]models.Folder, error) {
 	return folders, nil
 }
 
+func (db *DB) GetFolder(id int) (*models.Folder, error) {
+	query := `SELECT id, project_id, name, description, parent_id, position, created_at, updated_at 
+			  FROM folders WHERE id = ? AND deleted_at IS NULL`
+	var folder models.Folder
+	var parentID *int
+	err := db.QueryRow(query, id).Scan(&folder.ID, &folder.ProjectID, &folder.Name, &folder.Description, &parentID,
+		&folder.Position, &folder.CreatedAt, &folder.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	folder.ParentID = parentID
+	return &folder, nil
+}
+
+// UpdateFolder saves a folder. Its parent must be a folder of the same project outside of its
+// own subtree.
 func (db *DB) UpdateFolder(folder *models.Folder) error {
-	query := `UPDATE folders SET name = ?, parent_id = ?, position = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
-	_, err := db.Exec(query, folder.Name, folder.ParentID, folder.Position, folder.ID)
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var projectID int
+	if err := tx.QueryRow("SELECT project_id FROM folders WHERE id = ?", folder.ID).Scan(&projectID); err != nil {
+		return fmt.Errorf("folder not found: %w", err)
+	}
+	if folder.ParentID != nil {
+		if err := checkTarget(tx, models.CopyTarget{ProjectID: projectID, FolderID: folder.ParentID}); err != nil {
+			return err
+		}
+		if err := checkFolderCycle(tx, folder.ID, *folder.ParentID); err != nil {
+			return err
+		}
+	}
+
+	query := `UPDATE folders SET name = ?, description = ?, parent_id = ?, position = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
+	if _, err := tx.Exec(query, folder.Name, folder.Description, folder.ParentID, folder.Position, folder.ID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// MoveRequest moves a request to a folder or the root of its project, at position among the
+// requests there, and renumbers both folders in one transaction
+func (db *DB) MoveRequest(requestID int, folderID *int, position int) error {
+	return db.InTransaction(func(tx *DB) error {
+		var projectID int
+		err := tx.QueryRow("SELECT project_id FROM requests WHERE id = ? AND deleted_at IS NULL", requestID).Scan(&projectID)
+		if err != nil {
+			return fmt.Errorf("request not found: %w", err)
+		}
+		return tx.MoveRequestTo(requestID, models.CopyTarget{ProjectID: projectID, FolderID: folderID, Position: &position})
+	})
+}
+
+// Environment operations
+func (db *DB) CreateEnvironment(environment *models.Environment) error {
+	variablesJSON, _ := json.Marshal(environment.Variables)
+	query := `INSERT INTO environments (project_id, name, variables, is_active) 
+			  VALUES (?, ?, ?, ?) RETURNING id, created_at, updated_at`
+	err := db.QueryRow(query, environment.ProjectID, environment.Name, string(variablesJSON),
+		environment.IsActive).Scan(
+		&environment.ID, &environment.CreatedAt, &environment.UpdatedAt,
+	)
 	return err
 }
 
-func (db *DB) DeleteFolder(id int) error {
-	// First, move all requests in this folder to root level
-	_, err := db.Exec("UPDATE requests SET folder_id = NULL WHERE folder_id = ?", id)
+func (db *DB) GetEnvironments(projectID int) ([]models.Environment, error) {
+	query := `SELECT id, project_id, name, variables, is_active, created_at, updated_at 
+			  FROM environments WHERE project_id = ? ORDER BY name ASC`
+	rows, err := db.Query(query, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var environments []models.Environment
+	for rows.Next() {
+		var environment models.Environment
+		var variablesJSON string
+		err := rows.Scan(&environment.ID, &environment.ProjectID, &environment.Name, &variablesJSON,
+			&environment.IsActive, &environment.CreatedAt, &environment.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		json.Unmarshal([]byte(variablesJSON), &environment.Variables)
+		environments = append(environments, environment)
+	}
+
+	return environments, nil
+}
+
+func (db *DB) GetEnvironment(id int) (*models.Environment, error) {
+	query := `SELECT id, project_id, name, variables, is_active, created_at, updated_at 
+			  FROM environments WHERE id = ?`
+	var environment models.Environment
+	var variablesJSON string
+	err := db.QueryRow(query, id).Scan(&environment.ID, &environment.ProjectID, &environment.Name,
+		&variablesJSON, &environment.IsActive, &environment.CreatedAt, &environment.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	json.Unmarshal([]byte(variablesJSON), &environment.Variables)
+	return &environment, nil
+}
+
+// GetActiveEnvironment returns the active environment of a project, or nil if none is active
+func (db *DB) GetActiveEnvironment(projectID int) (*models.Environment, error) {
+	var id int
+	err := db.QueryRow("SELECT id FROM environments WHERE project_id = ? AND is_active = 1 LIMIT 1", projectID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return db.GetEnvironment(id)
+}
+
+func (db *DB) UpdateEnvironment(environment *models.Environment) error {
+	variablesJSON, _ := json.Marshal(environment.Variables)
+	query := `UPDATE environments SET name = ?, variables = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
+	_, err := db.Exec(query, environment.Name, string(variablesJSON), environment.ID)
+	return err
+}
+
+func (db *DB) DeleteEnvironment(id int) error {
+	query := `DELETE FROM environments WHERE id = ?`
+	_, err := db.Exec(query, id)
+	return err
+}
+
+// SetActiveEnvironment makes environmentID the only active environment of the project.
+// A nil environmentID deactivates all of them.
+func (db *DB) SetActiveEnvironment(projectID int, environmentID *int) error {
+	tx, err := db.Begin()
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("UPDATE environments SET is_active = 0 WHERE project_id = ?", projectID); err != nil {
+		return err
+	}
+
+	if environmentID != nil {
+		result, err := tx.Exec("UPDATE environments SET is_active = 1 WHERE id = ? AND project_id = ?", *environmentID, projectID)
+		if err != nil {
+			return err
+		}
+		if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+			return fmt.Errorf("environment not found or does not belong to this project")
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Project storage operations
+func (db *DB) SetProjectStorage(storage *models.ProjectStorage) error {
+	query := `INSERT INTO project_storage (project_id, path) VALUES (?, ?) 
+			  ON CONFLICT(project_id) DO UPDATE SET path = excluded.path RETURNING created_at`
+	return db.QueryRow(query, storage.ProjectID, storage.Path).Scan(&storage.CreatedAt)
+}
+
+// GetProjectStorage returns the directory a project is stored in, or nil if it has none
+func (db *DB) GetProjectStorage(projectID int) (*models.ProjectStorage, error) {
+	var storage models.ProjectStorage
+	err := db.QueryRow("SELECT project_id, path, created_at FROM project_storage WHERE project_id = ?", projectID).Scan(
+		&storage.ProjectID, &storage.Path, &storage.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &storage, nil
+}
+
+func (db *DB) GetProjectStorages() ([]models.ProjectStorage, error) {
+	// Projects in the trash are not synced until they are restored
+	rows, err := db.Query(`SELECT s.project_id, s.path, s.created_at FROM project_storage s 
+			  JOIN projects p ON p.id = s.project_id WHERE p.deleted_at IS NULL ORDER BY s.project_id ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-	// Then delete the folder
-	query := `DELETE FROM folders WHERE id = ?`
-	_, err = db.Exec(query, id)
+	var storages []models.ProjectStorage
+	for rows.Next() {
+		var storage models.ProjectStorage
+		if err := rows.Scan(&storage.ProjectID, &storage.Path, &storage.CreatedAt); err != nil {
+			return nil, err
+		}
+		storages = append(storages, storage)
+	}
+
+	return storages, nil
+}
+
+// DeleteProjectStorage unlinks a project from its directory, forgetting which files held what
+func (db *DB) DeleteProjectStorage(projectID int) error {
+	if _, err := db.Exec("DELETE FROM storage_entries WHERE project_id = ?", projectID); err != nil {
+		return err
+	}
+	_, err := db.Exec("DELETE FROM project_storage WHERE project_id = ?", projectID)
 	return err
 }
 
-func (db *DB) MoveRequest(requestID int, folderID *int, position int) error {
-	query := `UPDATE requests SET folder_id = ?, position = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
-	_, err := db.Exec(query, folderID, position, requestID)
+func (db *DB) GetStorageEntries(projectID int) ([]models.StorageEntry, error) {
+	rows, err := db.Query("SELECT project_id, path, kind, item_id FROM storage_entries WHERE project_id = ? ORDER BY path ASC", projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.StorageEntry
+	for rows.Next() {
+		var entry models.StorageEntry
+		if err := rows.Scan(&entry.ProjectID, &entry.Path, &entry.Kind, &entry.ItemID); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// ReplaceStorageEntries swaps the entries of a project for a new set in a single transaction
+func (db *DB) ReplaceStorageEntries(projectID int, entries []models.StorageEntry) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM storage_entries WHERE project_id = ?", projectID); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		_, err := tx.Exec("INSERT INTO storage_entries (project_id, path, kind, item_id) VALUES (?, ?, ?, ?)",
+			projectID, entry.Path, entry.Kind, entry.ItemID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// OpenAPI spec operations
+func (db *DB) CreateOpenAPISpec(spec *models.OpenAPISpec) error {
+	query := `INSERT INTO openapi_specs (project_id, title, version, content, folder_id, base_url, create_folders) 
+			  VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING id, created_at, updated_at`
+	err := db.QueryRow(query, spec.ProjectID, spec.Title, spec.Version, spec.Content, spec.FolderID,
+		spec.BaseURL, spec.CreateFolders).Scan(
+		&spec.ID, &spec.CreatedAt, &spec.UpdatedAt,
+	)
+	return err
+}
+
+// GetOpenAPISpecs lists the specs imported into a project, without their content
+func (db *DB) GetOpenAPISpecs(projectID int) ([]models.OpenAPISpec, error) {
+	query := `SELECT s.id, s.project_id, s.title, s.version, s.folder_id, s.base_url, s.create_folders, 
+			  (SELECT COUNT(*) FROM openapi_operations o JOIN requests r ON r.id = o.request_id WHERE o.spec_id = s.id AND r.deleted_at IS NULL), 
+			  s.created_at, s.updated_at 
+			  FROM openapi_specs s WHERE s.project_id = ? ORDER BY s.title ASC, s.id ASC`
+	rows, err := db.Query(query, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var specs []models.OpenAPISpec
+	for rows.Next() {
+		var spec models.OpenAPISpec
+		err := rows.Scan(&spec.ID, &spec.ProjectID, &spec.Title, &spec.Version, &spec.FolderID,
+			&spec.BaseURL, &spec.CreateFolders, &spec.Operations, &spec.CreatedAt, &spec.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, spec)
+	}
+
+	return specs, nil
+}
+
+func (db *DB) GetOpenAPISpec(id int) (*models.OpenAPISpec, error) {
+	query := `SELECT id, project_id, title, version, content, folder_id, base_url, create_folders, created_at, updated_at 
+			  FROM openapi_specs WHERE id = ?`
+	var spec models.OpenAPISpec
+	err := db.QueryRow(query, id).Scan(&spec.ID, &spec.ProjectID, &spec.Title, &spec.Version, &spec.Content,
+		&spec.FolderID, &spec.BaseURL, &spec.CreateFolders, &spec.CreatedAt, &spec.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+func (db *DB) UpdateOpenAPISpec(spec *models.OpenAPISpec) error {
+	query := `UPDATE openapi_specs SET title = ?, version = ?, content = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
+	_, err := db.Exec(query, spec.Title, spec.Version, spec.Content, spec.ID)
+	return err
+}
+
+// DeleteOpenAPISpec forgets a spec; the requests it created are kept
+func (db *DB) DeleteOpenAPISpec(id int) error {
+	if _, err := db.Exec(`DELETE FROM openapi_operations WHERE spec_id = ?`, id); err != nil {
+		return err
+	}
+	_, err := db.Exec(`DELETE FROM openapi_specs WHERE id = ?`, id)
+	return err
+}
+
+// SaveOpenAPIOperationLink creates or replaces the link of a request to its operation
+func (db *DB) SaveOpenAPIOperationLink(link *models.OpenAPIOperationLink) error {
+	snapshotJSON, _ := json.Marshal(link.Snapshot)
+	query := `INSERT OR REPLACE INTO openapi_operations (request_id, spec_id, operation_id, method, path, snapshot) 
+			  VALUES (?, ?, ?, ?, ?, ?)`
+	_, err := db.Exec(query, link.RequestID, link.SpecID, link.OperationID, link.Method, link.Path, string(snapshotJSON))
+	return err
+}
+
+// GetOpenAPIOperationLinks returns the links of a spec whose requests still exist
+func (db *DB) GetOpenAPIOperationLinks(specID int) ([]models.OpenAPIOperationLink, error) {
+	query := `SELECT o.request_id, o.spec_id, o.operation_id, o.method, o.path, o.snapshot 
+			  FROM openapi_operations o JOIN requests r ON r.id = o.request_id 
+			  WHERE o.spec_id = ? AND r.deleted_at IS NULL ORDER BY r.id ASC`
+	rows, err := db.Query(query, specID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var links []models.OpenAPIOperationLink
+	for rows.Next() {
+		var link models.OpenAPIOperationLink
+		var snapshotJSON string
+		err := rows.Scan(&link.RequestID, &link.SpecID, &link.OperationID, &link.Method, &link.Path, &snapshotJSON)
+		if err != nil {
+			return nil, err
+		}
+		json.Unmarshal([]byte(snapshotJSON), &link.Snapshot)
+		links = append(links, link)
+	}
+
+	return links, nil
+}
+
+func (db *DB) DeleteOpenAPIOperationLink(requestID int) error {
+	_, err := db.Exec(`DELETE FROM openapi_operations WHERE request_id = ?`, requestID)
 	return err
 }
 
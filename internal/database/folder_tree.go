@@ -0,0 +1,145 @@
+package database
+
+import (
+	"fmt"
+
+	"rikuest/internal/models"
+)
+
+// Folders and requests are ordered by position within their folder, or within the project root
+// when they have none. Operations that change the tree renumber the positions of the folders
+// they touch from zero, in the same transaction as the change.
+
+// ReorderFolders sets the order of the folders of a folder or project root. folderIDs must list
+// every folder there exactly once.
+func (db *DB) ReorderFolders(projectID int, parentID *int, folderIDs []int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	current, err := siblingIDs(tx, "folders", "parent_id", projectID, parentID)
+	if err != nil {
+		return err
+	}
+	if err := checkSameIDs(current, folderIDs); err != nil {
+		return err
+	}
+	if err := writePositions(tx, "folders", folderIDs); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// checkSameIDs makes sure an order lists every item of a folder exactly once
+func checkSameIDs(current, order []int) error {
+	listed := make(map[int]bool, len(order))
+	for _, id := range order {
+		if listed[id] {
+			return fmt.Errorf("folder %d is listed more than once", id)
+		}
+		listed[id] = true
+	}
+	for _, id := range current {
+		if !listed[id] {
+			return fmt.Errorf("folder %d is missing from the order", id)
+		}
+		delete(listed, id)
+	}
+	for id := range listed {
+		return fmt.Errorf("folder %d is not in this folder", id)
+	}
+	return nil
+}
+
+// checkFolderCycle makes sure parentID is neither the folder itself nor one of its subfolders
+func checkFolderCycle(tx *Tx, folderID, parentID int) error {
+	var inside bool
+	err := tx.QueryRow(`WITH RECURSIVE subtree(id) AS (
+				SELECT ? UNION SELECT f.id FROM folders f JOIN subtree s ON f.parent_id = s.id
+			)
+			SELECT EXISTS (SELECT 1 FROM subtree WHERE id = ?)`, folderID, parentID).Scan(&inside)
+	if err != nil {
+		return err
+	}
+	if inside {
+		return fmt.Errorf("a folder cannot be moved into itself or one of its subfolders")
+	}
+	return nil
+}
+
+// nextPosition returns the position after the last item of a folder or project root. parent is
+// the column holding the folder: folder_id for requests and parent_id for folders.
+func nextPosition(tx *Tx, table, parent string, projectID int, parentID *int) (int, error) {
+	var position int
+	err := tx.QueryRow(fmt.Sprintf(`SELECT COALESCE(MAX(position), -1) + 1 FROM %s
+			  WHERE project_id = ? AND %s IS ? AND deleted_at IS NULL`, table, parent), projectID, parentID).Scan(&position)
+	return position, err
+}
+
+// renumber numbers the items of a folder or project root from zero, keeping their order
+func renumber(tx *Tx, table, parent string, projectID int, parentID *int) error {
+	ids, err := siblingIDs(tx, table, parent, projectID, parentID)
+	if err != nil {
+		return err
+	}
+	return writePositions(tx, table, ids)
+}
+
+// place puts an item of the target folder at the target position, or leaves it where it is
+// without one, and renumbers the folder
+func place(tx *Tx, table, parent string, target models.CopyTarget, id int) error {
+	ids, err := siblingIDs(tx, table, parent, target.ProjectID, target.FolderID)
+	if err != nil {
+		return err
+	}
+	if target.Position != nil {
+		others := make([]int, 0, len(ids))
+		for _, sibling := range ids {
+			if sibling != id {
+				others = append(others, sibling)
+			}
+		}
+		position := *target.Position
+		if position < 0 || position > len(others) {
+			position = len(others)
+		}
+		ids = append(others[:position:position], append([]int{id}, others[position:]...)...)
+	}
+	return writePositions(tx, table, ids)
+}
+
+func siblingIDs(tx *Tx, table, parent string, projectID int, parentID *int) ([]int, error) {
+	return queryIDs(tx, fmt.Sprintf(`SELECT id FROM %s WHERE project_id = ? AND %s IS ? AND deleted_at IS NULL
+			  ORDER BY position, id`, table, parent), projectID, parentID)
+}
+
+// writePositions numbers items in the given order, leaving alone the ones already in place
+func writePositions(tx *Tx, table string, ids []int) error {
+	for position, id := range ids {
+		if _, err := tx.Exec(fmt.Sprintf("UPDATE %s SET position = ? WHERE id = ? AND position IS NOT ?", table),
+			position, id, position); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func queryIDs(tx *Tx, query string, args ...interface{}) ([]int, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,241 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"rikuest/internal/models"
+)
+
+// Each history entry stores the response and a snapshot of the request that was sent as JSON.
+// Status, duration and size are copied out of the response into their own columns so that
+// entries can be filtered and pruned without decoding them.
+
+const historyColumns = `h.id, h.request_id, h.request, h.response, h.executed_at`
+
+func (db *DB) SaveRequestHistory(history *models.RequestHistory) error {
+	responseJSON, _ := json.Marshal(history.Response)
+	var requestJSON *string
+	if history.Request != nil {
+		snapshot := *history.Request
+		snapshot.Response = nil
+		data, _ := json.Marshal(snapshot)
+		value := string(data)
+		requestJSON = &value
+	}
+
+	// Imported history keeps its original execution time. Times are stored in UTC so that they
+	// compare in order.
+	if history.ExecutedAt.IsZero() {
+		history.ExecutedAt = time.Now()
+	}
+	history.ExecutedAt = history.ExecutedAt.UTC()
+
+	query := `INSERT INTO request_history (request_id, request, response, status, duration, size, executed_at)
+			  VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING id`
+	err := db.QueryRow(query, history.RequestID, requestJSON, string(responseJSON), history.Response.Status,
+		history.Response.Duration, history.Response.Size, history.ExecutedAt).Scan(&history.ID)
+	return err
+}
+
+// GetRequestHistory lists every execution of a request, newest first
+func (db *DB) GetRequestHistory(requestID int) ([]models.RequestHistory, error) {
+	return db.queryHistory(`WHERE h.request_id = ? ORDER BY h.executed_at DESC, h.id DESC`, requestID)
+}
+
+// GetLatestRequestHistory lists the last executions of a request, newest first
+func (db *DB) GetLatestRequestHistory(requestID int, limit int) ([]models.RequestHistory, error) {
+	return db.queryHistory(`WHERE h.request_id = ? ORDER BY h.executed_at DESC, h.id DESC LIMIT ?`, requestID, limit)
+}
+
+// FindRequestHistory returns a page of the executions of a request matching a filter
+func (db *DB) FindRequestHistory(requestID int, filter models.HistoryFilter) (*models.HistoryPage, error) {
+	return db.findHistory(`h.request_id = ?`, []interface{}{requestID}, filter)
+}
+
+// FindProjectHistory returns a page of the executions of every request in a project matching a
+// filter. Requests in the trash are left out.
+func (db *DB) FindProjectHistory(projectID int, filter models.HistoryFilter) (*models.HistoryPage, error) {
+	where := `h.request_id IN (SELECT id FROM requests WHERE project_id = ? AND deleted_at IS NULL)`
+	return db.findHistory(where, []interface{}{projectID}, filter)
+}
+
+func (db *DB) findHistory(where string, args []interface{}, filter models.HistoryFilter) (*models.HistoryPage, error) {
+	conditions := []string{where}
+
+	if filter.Status != "" {
+		low, high, err := parseStatusFilter(filter.Status)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, `h.status BETWEEN ? AND ?`)
+		args = append(args, low, high)
+	}
+	if filter.From != nil {
+		conditions = append(conditions, `h.executed_at >= ?`)
+		args = append(args, filter.From.UTC())
+	}
+	if filter.To != nil {
+		conditions = append(conditions, `h.executed_at <= ?`)
+		args = append(args, filter.To.UTC())
+	}
+	if filter.MinDuration > 0 {
+		conditions = append(conditions, `h.duration >= ?`)
+		args = append(args, filter.MinDuration)
+	}
+	if filter.MaxDuration > 0 {
+		conditions = append(conditions, `h.duration <= ?`)
+		args = append(args, filter.MaxDuration)
+	}
+	whereClause := `WHERE ` + strings.Join(conditions, ` AND `)
+
+	page := &models.HistoryPage{Limit: filter.Limit, Offset: filter.Offset}
+	if err := db.QueryRow(`SELECT COUNT(*) FROM request_history h `+whereClause, args...).Scan(&page.Total); err != nil {
+		return nil, err
+	}
+
+	items, err := db.queryHistory(whereClause+` ORDER BY h.executed_at DESC, h.id DESC LIMIT ? OFFSET ?`,
+		append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, err
+	}
+	page.Items = items
+	return page, nil
+}
+
+// parseStatusFilter turns a status code such as "404" or a class such as "4xx" into a range
+func parseStatusFilter(status string) (int, int, error) {
+	status = strings.ToLower(strings.TrimSpace(status))
+	if len(status) == 3 && strings.HasSuffix(status, "xx") && status[0] >= '1' && status[0] <= '5' {
+		low := int(status[0]-'0') * 100
+		return low, low + 99, nil
+	}
+	code, err := strconv.Atoi(status)
+	if err != nil || code < 0 || code > 999 {
+		return 0, 0, fmt.Errorf("invalid status filter %q, use a code such as 404 or a class such as 4xx", status)
+	}
+	return code, code, nil
+}
+
+func (db *DB) DeleteRequestHistoryItem(requestID int, historyID int) error {
+	query := `DELETE FROM request_history WHERE id = ? AND request_id = ?`
+	result, err := db.Exec(query, historyID, requestID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("history item not found or does not belong to this request")
+	}
+
+	return nil
+}
+
+func (db *DB) GetRequestHistoryItem(requestID int, historyID int) (*models.RequestHistory, error) {
+	return db.getHistory(`WHERE h.id = ? AND h.request_id = ?`, historyID, requestID)
+}
+
+// GetHistoryEntry returns a history entry by ID regardless of the request it belongs to
+func (db *DB) GetHistoryEntry(historyID int) (*models.RequestHistory, error) {
+	return db.getHistory(`WHERE h.id = ?`, historyID)
+}
+
+func (db *DB) getHistory(where string, args ...interface{}) (*models.RequestHistory, error) {
+	var requestJSON *string
+	var responseJSON string
+	var h models.RequestHistory
+	err := db.QueryRow(`SELECT `+historyColumns+` FROM request_history h `+where, args...).Scan(
+		&h.ID, &h.RequestID, &requestJSON, &responseJSON, &h.ExecutedAt)
+	if err != nil {
+		return nil, err
+	}
+	decodeHistory(&h, requestJSON, responseJSON)
+	return &h, nil
+}
+
+func (db *DB) queryHistory(where string, args ...interface{}) ([]models.RequestHistory, error) {
+	rows, err := db.Query(`SELECT `+historyColumns+` FROM request_history h `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []models.RequestHistory{}
+	for rows.Next() {
+		var requestJSON *string
+		var responseJSON string
+		var h models.RequestHistory
+		if err := rows.Scan(&h.ID, &h.RequestID, &requestJSON, &responseJSON, &h.ExecutedAt); err != nil {
+			return nil, err
+		}
+		decodeHistory(&h, requestJSON, responseJSON)
+		history = append(history, h)
+	}
+	return history, rows.Err()
+}
+
+func decodeHistory(h *models.RequestHistory, requestJSON *string, responseJSON string) {
+	json.Unmarshal([]byte(responseJSON), &h.Response)
+	if requestJSON != nil {
+		var request models.Request
+		if json.Unmarshal([]byte(*requestJSON), &request) == nil {
+			h.Request = &request
+		}
+	}
+}
+
+// PruneHistory deletes the executions beyond the newest maxEntries of each request, those older
+// than maxAge and the oldest ones beyond maxSize bytes in total. A zero limit is not applied.
+// It returns how many entries were deleted.
+func (db *DB) PruneHistory(maxEntries int, maxAge time.Duration, maxSize int64) (int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var deleted int64
+	prune := func(query string, args ...interface{}) error {
+		result, err := tx.Exec(query, args...)
+		if err != nil {
+			return err
+		}
+		rows, err := result.RowsAffected()
+		deleted += rows
+		return err
+	}
+
+	if maxEntries > 0 {
+		err := prune(`DELETE FROM request_history WHERE id IN (
+				SELECT id FROM (SELECT id, ROW_NUMBER() OVER (
+					PARTITION BY request_id ORDER BY executed_at DESC, id DESC) AS n FROM request_history)
+				WHERE n > ?)`, maxEntries)
+		if err != nil {
+			return 0, err
+		}
+	}
+	if maxAge > 0 {
+		if err := prune(`DELETE FROM request_history WHERE executed_at < ?`, time.Now().UTC().Add(-maxAge)); err != nil {
+			return 0, err
+		}
+	}
+	if maxSize > 0 {
+		err := prune(`DELETE FROM request_history WHERE id IN (
+				SELECT id FROM (SELECT id, SUM(LENGTH(response) + COALESCE(LENGTH(request), 0)) OVER (
+					ORDER BY executed_at DESC, id DESC) AS total FROM request_history)
+				WHERE total > ?)`, maxSize)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return deleted, tx.Commit()
+}
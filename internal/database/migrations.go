@@ -0,0 +1,579 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"rikuest/internal/models"
+)
+
+// migration is one step of the schema. Migrations run in version order, each in its own
+// transaction, and are recorded in schema_migrations so they only run once. Never edit a
+// migration that has been released; add a new one instead.
+type migration struct {
+	version int
+	name    string
+	up      func(tx *sql.Tx) error
+}
+
+var migrations = []migration{
+	{1, "create base tables", func(tx *sql.Tx) error {
+		return execAll(tx,
+			`CREATE TABLE IF NOT EXISTS projects (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				name TEXT NOT NULL UNIQUE,
+				description TEXT,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			)`,
+			`CREATE TABLE IF NOT EXISTS folders (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				project_id INTEGER NOT NULL,
+				name TEXT NOT NULL,
+				parent_id INTEGER,
+				position INTEGER DEFAULT 0,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+				FOREIGN KEY (parent_id) REFERENCES folders(id) ON DELETE CASCADE
+			)`,
+			`CREATE TABLE IF NOT EXISTS requests (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				project_id INTEGER NOT NULL,
+				name TEXT NOT NULL,
+				method TEXT NOT NULL DEFAULT 'GET',
+				url TEXT NOT NULL,
+				headers TEXT DEFAULT '{}',
+				body TEXT DEFAULT '',
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+			)`,
+			`CREATE TABLE IF NOT EXISTS request_history (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				request_id INTEGER NOT NULL,
+				response TEXT NOT NULL,
+				executed_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (request_id) REFERENCES requests(id) ON DELETE CASCADE
+			)`,
+			`CREATE TABLE IF NOT EXISTS settings (
+				key TEXT PRIMARY KEY,
+				value TEXT NOT NULL,
+				updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			)`,
+			`CREATE TABLE IF NOT EXISTS telemetry_config (
+				id INTEGER PRIMARY KEY,
+				enabled INTEGER DEFAULT 1,
+				webhook_url TEXT DEFAULT '',
+				installation_id TEXT DEFAULT ''
+			)`,
+			`CREATE TABLE IF NOT EXISTS telemetry_events_cache (
+				event_hash TEXT PRIMARY KEY,
+				timestamp DATETIME DEFAULT CURRENT_TIMESTAMP
+			)`,
+		)
+	}},
+	{2, "add request auth, body and folder columns", func(tx *sql.Tx) error {
+		columns := []struct{ name, definition string }{
+			{"query_params", "TEXT DEFAULT '[]'"},
+			{"auth_type", "TEXT DEFAULT 'none'"},
+			{"bearer_token", "TEXT DEFAULT ''"},
+			{"basic_auth", "TEXT DEFAULT '{}'"},
+			{"body_type", "TEXT DEFAULT 'none'"},
+			{"form_data", "TEXT DEFAULT '[]'"},
+			{"folder_id", "INTEGER REFERENCES folders(id) ON DELETE SET NULL"},
+			{"position", "INTEGER DEFAULT 0"},
+		}
+		for _, column := range columns {
+			if err := addColumn(tx, "requests", column.name, column.definition); err != nil {
+				return err
+			}
+		}
+		return nil
+	}},
+	{3, "create response examples and environments", func(tx *sql.Tx) error {
+		return execAll(tx,
+			`CREATE TABLE IF NOT EXISTS request_examples (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				request_id INTEGER NOT NULL,
+				name TEXT NOT NULL,
+				status INTEGER NOT NULL DEFAULT 200,
+				headers TEXT DEFAULT '{}',
+				body TEXT DEFAULT '',
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (request_id) REFERENCES requests(id) ON DELETE CASCADE
+			)`,
+			`CREATE TABLE IF NOT EXISTS environments (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				project_id INTEGER NOT NULL,
+				name TEXT NOT NULL,
+				variables TEXT DEFAULT '[]',
+				is_active INTEGER DEFAULT 0,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+			)`,
+		)
+	}},
+	{4, "create openapi specs", func(tx *sql.Tx) error {
+		return execAll(tx,
+			`CREATE TABLE IF NOT EXISTS openapi_specs (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				project_id INTEGER NOT NULL,
+				title TEXT NOT NULL,
+				version TEXT DEFAULT '',
+				content TEXT NOT NULL,
+				folder_id INTEGER,
+				base_url TEXT DEFAULT '',
+				create_folders INTEGER DEFAULT 0,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+				FOREIGN KEY (folder_id) REFERENCES folders(id) ON DELETE SET NULL
+			)`,
+			`CREATE TABLE IF NOT EXISTS openapi_operations (
+				request_id INTEGER PRIMARY KEY,
+				spec_id INTEGER NOT NULL,
+				operation_id TEXT NOT NULL,
+				method TEXT NOT NULL,
+				path TEXT NOT NULL,
+				snapshot TEXT NOT NULL,
+				FOREIGN KEY (request_id) REFERENCES requests(id) ON DELETE CASCADE,
+				FOREIGN KEY (spec_id) REFERENCES openapi_specs(id) ON DELETE CASCADE
+			)`,
+		)
+	}},
+	{5, "create project storage", func(tx *sql.Tx) error {
+		return execAll(tx,
+			`CREATE TABLE IF NOT EXISTS project_storage (
+				project_id INTEGER PRIMARY KEY,
+				path TEXT NOT NULL UNIQUE,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+			)`,
+			`CREATE TABLE IF NOT EXISTS storage_entries (
+				project_id INTEGER NOT NULL,
+				path TEXT NOT NULL,
+				kind TEXT NOT NULL,
+				item_id INTEGER NOT NULL,
+				PRIMARY KEY (project_id, path),
+				FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+			)`,
+		)
+	}},
+	{6, "replace null JSON in requests with empty values", func(tx *sql.Tx) error {
+		// Requests saved with nil maps or slices stored "null", which older clients choke on
+		empty := map[string]string{
+			"headers":      "{}",
+			"query_params": "[]",
+			"basic_auth":   "{}",
+			"form_data":    "[]",
+		}
+		for column, value := range empty {
+			err := transformColumn(tx, "requests", column, func(current string) (string, error) {
+				if current == "" || current == "null" {
+					return value, nil
+				}
+				return current, nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}},
+	{7, "add trash", func(tx *sql.Tx) error {
+		for _, table := range []string{"projects", "folders", "requests"} {
+			if err := addColumn(tx, table, "deleted_at", "DATETIME"); err != nil {
+				return err
+			}
+			if err := addColumn(tx, table, "trash_id", "INTEGER"); err != nil {
+				return err
+			}
+		}
+		return execAll(tx,
+			`CREATE TABLE IF NOT EXISTS trash (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				project_id INTEGER NOT NULL,
+				kind TEXT NOT NULL,
+				item_id INTEGER NOT NULL,
+				name TEXT NOT NULL,
+				deleted_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_folders_trash ON folders(trash_id)`,
+			`CREATE INDEX IF NOT EXISTS idx_requests_trash ON requests(trash_id)`,
+		)
+	}},
+	{8, "add request revisions", func(tx *sql.Tx) error {
+		return execAll(tx,
+			`CREATE TABLE IF NOT EXISTS request_revisions (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				request_id INTEGER NOT NULL,
+				snapshot TEXT NOT NULL,
+				author TEXT NOT NULL DEFAULT '',
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (request_id) REFERENCES requests(id) ON DELETE CASCADE
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_request_revisions_request ON request_revisions(request_id)`,
+		)
+	}},
+	{9, "add request snapshots and filter columns to history", func(tx *sql.Tx) error {
+		columns := map[string]string{
+			"request":  "TEXT",
+			"status":   "INTEGER NOT NULL DEFAULT 0",
+			"duration": "INTEGER NOT NULL DEFAULT 0",
+			"size":     "INTEGER NOT NULL DEFAULT 0",
+		}
+		for column, definition := range columns {
+			if err := addColumn(tx, "request_history", column, definition); err != nil {
+				return err
+			}
+		}
+		return execAll(tx,
+			// Older entries only have the response, which holds everything the filters need
+			`UPDATE request_history SET
+				status = COALESCE(json_extract(response, '$.status'), 0),
+				duration = COALESCE(json_extract(response, '$.duration'), 0),
+				size = COALESCE(json_extract(response, '$.size'), 0)
+			 WHERE json_valid(response)`,
+			`CREATE INDEX IF NOT EXISTS idx_request_history_request ON request_history(request_id, executed_at)`,
+			`CREATE INDEX IF NOT EXISTS idx_request_history_executed ON request_history(executed_at)`,
+		)
+	}},
+	{10, "queue changes for the search index", func(tx *sql.Tx) error {
+		// The index itself is only available in builds with FTS5 and is created when the
+		// database is opened. Every build queues changes, so the index catches up with edits
+		// made by builds without it.
+		queries := []string{
+			`CREATE TABLE IF NOT EXISTS search_changes (
+				kind TEXT NOT NULL,
+				item_id INTEGER NOT NULL,
+				PRIMARY KEY (kind, item_id)
+			)`,
+		}
+		tables := map[string]string{"requests": "request", "folders": "folder", "request_history": "response"}
+		for table, kind := range tables {
+			for _, event := range []string{"INSERT", "UPDATE", "DELETE"} {
+				row := "NEW"
+				if event == "DELETE" {
+					row = "OLD"
+				}
+				queries = append(queries, fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS search_%s_%s AFTER %s ON %s BEGIN
+						INSERT OR IGNORE INTO search_changes (kind, item_id) VALUES ('%s', %s.id);
+					END`, table, strings.ToLower(event), event, table, kind, row))
+			}
+		}
+		// Responses are indexed with the project of their request
+		queries = append(queries, `CREATE TRIGGER IF NOT EXISTS search_requests_project AFTER UPDATE OF project_id ON requests BEGIN
+				INSERT OR IGNORE INTO search_changes (kind, item_id) SELECT 'response', id FROM request_history WHERE request_id = NEW.id;
+			END`)
+		return execAll(tx, queries...)
+	}},
+	{11, "clean up rows left behind while foreign keys were off", func(tx *sql.Tx) error {
+		// Foreign keys are enforced from now on, and deletes no longer cascade to rows that
+		// already point to nothing. Items left in deleted projects go away with them, and items
+		// in deleted folders move to the root of their project.
+		return execAll(tx,
+			`DELETE FROM requests WHERE project_id NOT IN (SELECT id FROM projects)`,
+			`DELETE FROM folders WHERE project_id NOT IN (SELECT id FROM projects)`,
+			`DELETE FROM environments WHERE project_id NOT IN (SELECT id FROM projects)`,
+			`DELETE FROM openapi_specs WHERE project_id NOT IN (SELECT id FROM projects)`,
+			`DELETE FROM project_storage WHERE project_id NOT IN (SELECT id FROM projects)`,
+			`DELETE FROM storage_entries WHERE project_id NOT IN (SELECT id FROM projects)`,
+			`UPDATE folders SET parent_id = NULL WHERE parent_id NOT IN (SELECT id FROM folders)`,
+			`UPDATE requests SET folder_id = NULL WHERE folder_id NOT IN (SELECT id FROM folders)`,
+			`UPDATE openapi_specs SET folder_id = NULL WHERE folder_id NOT IN (SELECT id FROM folders)`,
+			`DELETE FROM request_history WHERE request_id NOT IN (SELECT id FROM requests)`,
+			`DELETE FROM request_examples WHERE request_id NOT IN (SELECT id FROM requests)`,
+			`DELETE FROM request_revisions WHERE request_id NOT IN (SELECT id FROM requests)`,
+			`DELETE FROM openapi_operations WHERE request_id NOT IN (SELECT id FROM requests)
+				OR spec_id NOT IN (SELECT id FROM openapi_specs)`,
+		)
+	}},
+	{12, "add tags and saved filters", func(tx *sql.Tx) error {
+		return execAll(tx,
+			`CREATE TABLE IF NOT EXISTS tags (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				project_id INTEGER NOT NULL,
+				name TEXT NOT NULL COLLATE NOCASE,
+				color TEXT NOT NULL,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				UNIQUE (project_id, name),
+				FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+			)`,
+			`CREATE TABLE IF NOT EXISTS request_tags (
+				request_id INTEGER NOT NULL,
+				tag_id INTEGER NOT NULL,
+				PRIMARY KEY (request_id, tag_id),
+				FOREIGN KEY (request_id) REFERENCES requests(id) ON DELETE CASCADE,
+				FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_request_tags_tag ON request_tags(tag_id)`,
+			`CREATE TABLE IF NOT EXISTS saved_filters (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				project_id INTEGER NOT NULL,
+				name TEXT NOT NULL,
+				filter TEXT NOT NULL,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+			)`,
+		)
+	}},
+	{13, "add descriptions to folders and requests", func(tx *sql.Tx) error {
+		for _, table := range []string{"folders", "requests"} {
+			if err := addColumn(tx, table, "description", "TEXT NOT NULL DEFAULT ''"); err != nil {
+				return err
+			}
+		}
+		// Descriptions are searched, so the index picks up every item again
+		return execAll(tx,
+			`INSERT OR IGNORE INTO search_changes (kind, item_id) SELECT 'request', id FROM requests`,
+			`INSERT OR IGNORE INTO search_changes (kind, item_id) SELECT 'folder', id FROM folders`,
+		)
+	}},
+	{14, "store history times in UTC", func(tx *sql.Tx) error {
+		// Entries recorded before times were stored in UTC carry the offset of the machine,
+		// which breaks ordering and date filters. SQLite converts such times to UTC.
+		_, err := tx.Exec(`UPDATE request_history
+			SET executed_at = strftime('%Y-%m-%d %H:%M:%f', executed_at) || '+00:00'
+			WHERE executed_at NOT LIKE '%+00:00' AND strftime('%Y-%m-%d %H:%M:%f', executed_at) IS NOT NULL`)
+		return err
+	}},
+	{15, "add expected status to requests", func(tx *sql.Tx) error {
+		return addColumn(tx, "requests", "expected_status", "INTEGER NOT NULL DEFAULT 0")
+	}},
+}
+
+// LatestSchemaVersion is the schema version this build creates and understands
+func LatestSchemaVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+// Migrate applies the migrations the database has not run yet, after backing up a database
+// that holds data. A database written by a newer version of the app is refused rather than
+// risking data loss.
+func (db *DB) Migrate() error {
+	if _, err := db.Exec(schemaMigrationsTable); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	applied, err := appliedMigrations(db.DB)
+	if err != nil {
+		return err
+	}
+	if err := checkSchemaVersion(applied); err != nil {
+		return err
+	}
+	if len(applied) < len(migrations) {
+		if err := db.backupBeforeMigrating(); err != nil {
+			return err
+		}
+	}
+
+	for _, m := range migrations {
+		if _, ok := applied[m.version]; ok {
+			continue
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if err := runMigration(tx.Tx, m); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %w", m.version, m.name, err)
+		}
+	}
+
+	return nil
+}
+
+// Migrations lists every migration with the time it was applied, or nil if it is pending
+func (db *DB) Migrations() ([]models.MigrationStatus, error) {
+	applied, err := appliedMigrations(db.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]models.MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		status := models.MigrationStatus{Version: m.version, Name: m.name}
+		if appliedAt, ok := applied[m.version]; ok {
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// DryRunMigrations opens a database, runs its pending migrations in a transaction that is
+// rolled back, and returns them. Nothing is written to the database.
+func DryRunMigrations(dataSourceName string) ([]models.MigrationStatus, error) {
+	db, err := sql.Open("sqlite3", dataSourceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(schemaMigrationsTable); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	applied, err := appliedMigrations(tx)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSchemaVersion(applied); err != nil {
+		return nil, err
+	}
+
+	pending := []models.MigrationStatus{}
+	for _, m := range migrations {
+		if _, ok := applied[m.version]; ok {
+			continue
+		}
+		if err := runMigration(tx, m); err != nil {
+			return pending, err
+		}
+		pending = append(pending, models.MigrationStatus{Version: m.version, Name: m.name})
+	}
+	return pending, nil
+}
+
+const schemaMigrationsTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version INTEGER PRIMARY KEY,
+	name TEXT NOT NULL,
+	applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+)`
+
+func runMigration(tx *sql.Tx, m migration) error {
+	if err := m.up(tx); err != nil {
+		return fmt.Errorf("migration %d (%s) failed: %w", m.version, m.name, err)
+	}
+	if _, err := tx.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+		m.version, m.name, time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to record migration %d: %w", m.version, err)
+	}
+	return nil
+}
+
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+func appliedMigrations(db queryer) (map[int]time.Time, error) {
+	rows, err := db.Query("SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
+
+func checkSchemaVersion(applied map[int]time.Time) error {
+	latest := LatestSchemaVersion()
+	for version := range applied {
+		if version > latest {
+			return fmt.Errorf("the database uses schema version %d but this version of Rikuest only supports up to %d, update Rikuest to open it", version, latest)
+		}
+	}
+	return nil
+}
+
+func execAll(tx *sql.Tx, queries ...string) error {
+	for _, query := range queries {
+		if _, err := tx.Exec(query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addColumn adds a column unless the table already has it, which is the case for databases
+// created before migrations were tracked
+func addColumn(tx *sql.Tx, table, column, definition string) error {
+	rows, err := tx.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	exists := false
+	for rows.Next() {
+		var cid, notNull, primaryKey int
+		var name, columnType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &columnType, &notNull, &defaultValue, &primaryKey); err != nil {
+			rows.Close()
+			return err
+		}
+		if name == column {
+			exists = true
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil || exists {
+		return err
+	}
+
+	_, err = tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
+
+// transformColumn rewrites every value of a text column, for migrations that change how data
+// is stored rather than the schema
+func transformColumn(tx *sql.Tx, table, column string, transform func(string) (string, error)) error {
+	rows, err := tx.Query(fmt.Sprintf("SELECT rowid, COALESCE(%s, '') FROM %s", column, table))
+	if err != nil {
+		return err
+	}
+
+	updates := make(map[int64]string)
+	for rows.Next() {
+		var rowID int64
+		var value string
+		if err := rows.Scan(&rowID, &value); err != nil {
+			rows.Close()
+			return err
+		}
+		transformed, err := transform(value)
+		if err != nil {
+			rows.Close()
+			return fmt.Errorf("row %d of %s: %w", rowID, table, err)
+		}
+		if transformed != value {
+			updates[rowID] = transformed
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for rowID, value := range updates {
+		if _, err := tx.Exec(fmt.Sprintf("UPDATE %s SET %s = ? WHERE rowid = ?", table, column), value, rowID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"rikuest/internal/models"
+)
+
+// Every edit of a request stores the state it replaces as a revision, so that an earlier
+// version can be compared or brought back. Moving a request and saving it unchanged store
+// nothing, and neither does an edit by the author of the latest revision that replaces the very
+// content that revision holds.
+
+// maxRequestRevisions is how many revisions a request keeps, the oldest being dropped
+const maxRequestRevisions = 100
+
+// UpdateRequestBy saves a request, keeping a revision of its previous state made by author
+func (db *DB) UpdateRequestBy(request *models.Request, author string) error {
+	headersJSON, _ := json.Marshal(request.Headers)
+	queryParamsJSON, _ := json.Marshal(request.QueryParams)
+	basicAuthJSON, _ := json.Marshal(request.BasicAuth)
+	formDataJSON, _ := json.Marshal(request.FormData)
+
+	return db.InTransaction(func(tx *DB) error {
+		// The previous state is read in the transaction, so that concurrent edits each keep
+		// the state they replace
+		previous, err := tx.GetRequest(request.ID)
+		if err != nil {
+			return err
+		}
+		if requestContentChanged(previous, request) {
+			if err := tx.addRevision(previous, author); err != nil {
+				return err
+			}
+		}
+
+		query := `UPDATE requests SET name = ?, description = ?, method = ?, url = ?, headers = ?, body = ?, 
+			  query_params = ?, auth_type = ?, bearer_token = ?, basic_auth = ?, 
+			  body_type = ?, form_data = ?, expected_status = ?, folder_id = ?, position = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
+		_, err = tx.Exec(query, request.Name, request.Description, request.Method, request.URL,
+			string(headersJSON), request.Body, string(queryParamsJSON),
+			request.AuthType, request.BearerToken, string(basicAuthJSON),
+			request.BodyType, string(formDataJSON), request.ExpectedStatus, request.FolderID, request.Position, request.ID)
+		if err != nil {
+			return err
+		}
+
+		// Tags are left alone unless the request carries them
+		if request.Tags != nil {
+			return tx.SetRequestTags(request.ID, request.Tags)
+		}
+		return nil
+	})
+}
+
+// addRevision stores the state an edit replaces and drops the revisions beyond
+// maxRequestRevisions. Nothing is stored when author made the latest revision and it holds the
+// same content, as when autosaves go back and forth.
+func (db *DB) addRevision(previous *models.Request, author string) error {
+	var lastAuthor, lastSnapshot string
+	err := db.QueryRow(`SELECT author, snapshot FROM request_revisions WHERE request_id = ?
+			  ORDER BY created_at DESC, id DESC LIMIT 1`, previous.ID).Scan(&lastAuthor, &lastSnapshot)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if err == nil && lastAuthor == author {
+		var last models.Request
+		if json.Unmarshal([]byte(lastSnapshot), &last) == nil && !requestContentChanged(&last, previous) {
+			return nil
+		}
+	}
+
+	previous.Response = nil
+	snapshot, err := json.Marshal(previous)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`INSERT INTO request_revisions (request_id, snapshot, author, created_at) VALUES (?, ?, ?, ?)`,
+		previous.ID, string(snapshot), author, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`DELETE FROM request_revisions WHERE request_id = ? AND id NOT IN (
+			  SELECT id FROM request_revisions WHERE request_id = ? ORDER BY created_at DESC, id DESC LIMIT ?)`,
+		previous.ID, previous.ID, maxRequestRevisions)
+	return err
+}
+
+// requestContentChanged reports whether an edit changes more than where the request sits
+func requestContentChanged(before, after *models.Request) bool {
+	content := func(r *models.Request) string {
+		data, _ := json.Marshal([]interface{}{
+			r.Name, r.Description, r.Method, r.URL, r.Headers, r.Body, r.QueryParams,
+			r.AuthType, r.BearerToken, r.BasicAuth, r.BodyType, r.FormData, r.ExpectedStatus,
+		})
+		return string(data)
+	}
+	return content(before) != content(after)
+}
+
+// GetRequestRevisions lists the revisions of a request, newest first
+func (db *DB) GetRequestRevisions(requestID int) ([]models.RequestRevision, error) {
+	return db.queryRevisions(`WHERE request_id = ?`, requestID)
+}
+
+// GetRequestRevision returns a revision, or nil if there is none with that ID
+func (db *DB) GetRequestRevision(id int) (*models.RequestRevision, error) {
+	revisions, err := db.queryRevisions(`WHERE id = ?`, id)
+	if err != nil || len(revisions) == 0 {
+		return nil, err
+	}
+	return &revisions[0], nil
+}
+
+func (db *DB) queryRevisions(where string, args ...interface{}) ([]models.RequestRevision, error) {
+	query := `SELECT id, request_id, snapshot, author, created_at FROM request_revisions ` + where +
+		` ORDER BY created_at DESC, id DESC`
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []models.RequestRevision{}
+	for rows.Next() {
+		var revision models.RequestRevision
+		var snapshot string
+		if err := rows.Scan(&revision.ID, &revision.RequestID, &snapshot, &revision.Author, &revision.CreatedAt); err != nil {
+			return nil, err
+		}
+		json.Unmarshal([]byte(snapshot), &revision.Request)
+		revisions = append(revisions, revision)
+	}
+	return revisions, rows.Err()
+}
@@ -0,0 +1,224 @@
+package database
+
+import (
+	"database/sql"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"rikuest/internal/models"
+)
+
+// Search is backed by an FTS5 index in builds with the sqlite_fts5 tag (search_fts5.go) and by
+// LIKE queries otherwise (search_like.go). Both find the items matching every term of a query;
+// this file turns them into results.
+
+const (
+	searchDefaultLimit = 50
+	searchMaxLimit     = 200
+	snippetContext     = 40
+	snippetLength      = 120
+)
+
+// searchHit is an item found by the search backend
+type searchHit struct {
+	kind string
+	id   int
+}
+
+// Search finds the requests, folders and, when asked, history responses matching every term of
+// a query, best matches first. Trashed items are left out.
+func (db *DB) Search(query models.SearchQuery) ([]models.SearchResult, error) {
+	results := []models.SearchResult{}
+	terms := strings.Fields(strings.ToLower(query.Query))
+	if len(terms) == 0 {
+		return results, nil
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = searchDefaultLimit
+	}
+	if limit > searchMaxLimit {
+		limit = searchMaxLimit
+	}
+
+	hits, err := db.searchHits(terms, query.ProjectID, query.Responses, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	folders := make(map[int]map[int]models.Folder)
+	for _, hit := range hits {
+		if len(results) == limit {
+			break
+		}
+		result, err := db.searchResult(hit, terms)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := folders[result.ProjectID]; !ok {
+			projectFolders, err := db.GetFolders(result.ProjectID)
+			if err != nil {
+				return nil, err
+			}
+			folders[result.ProjectID] = make(map[int]models.Folder, len(projectFolders))
+			for _, folder := range projectFolders {
+				folders[result.ProjectID][folder.ID] = folder
+			}
+		}
+		result.Path = folderPath(folders[result.ProjectID], result.FolderID)
+		results = append(results, *result)
+	}
+	return results, nil
+}
+
+func (db *DB) searchResult(hit searchHit, terms []string) (*models.SearchResult, error) {
+	switch hit.kind {
+	case "request":
+		request, err := db.GetRequest(hit.id)
+		if err != nil {
+			return nil, err
+		}
+		result := requestSearchResult(request)
+		result.ID = request.ID
+		result.Field, result.Snippet = matchSnippet(terms, []searchField{
+			{"name", request.Name},
+			{"url", request.URL},
+			{"headers", headersText(request.Headers)},
+			{"body", request.Body},
+			{"description", request.Description},
+		})
+		return result, nil
+
+	case "folder":
+		folder, err := db.GetFolder(hit.id)
+		if err != nil {
+			return nil, err
+		}
+		result := &models.SearchResult{
+			Kind:      "folder",
+			ID:        folder.ID,
+			ProjectID: folder.ProjectID,
+			FolderID:  folder.ParentID,
+			Name:      folder.Name,
+			Time:      folder.UpdatedAt,
+		}
+		result.Field, result.Snippet = matchSnippet(terms, []searchField{
+			{"name", folder.Name},
+			{"description", folder.Description},
+		})
+		return result, nil
+
+	case "response":
+		entry, err := db.GetHistoryEntry(hit.id)
+		if err != nil {
+			return nil, err
+		}
+		request, err := db.GetRequest(entry.RequestID)
+		if err != nil {
+			return nil, err
+		}
+		result := requestSearchResult(request)
+		result.Kind = "response"
+		result.ID = entry.ID
+		result.Status = entry.Response.Status
+		result.Time = entry.ExecutedAt
+		result.Field, result.Snippet = matchSnippet(terms, []searchField{{"response", entry.Response.Body}})
+		return result, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func requestSearchResult(request *models.Request) *models.SearchResult {
+	return &models.SearchResult{
+		Kind:      "request",
+		ProjectID: request.ProjectID,
+		FolderID:  request.FolderID,
+		RequestID: request.ID,
+		Name:      request.Name,
+		Method:    request.Method,
+		URL:       request.URL,
+		Time:      request.UpdatedAt,
+	}
+}
+
+// folderPath lists the names of the folders from the root down to folderID
+func folderPath(folders map[int]models.Folder, folderID *int) []string {
+	path := []string{}
+	seen := make(map[int]bool)
+	for folderID != nil && !seen[*folderID] {
+		folder, ok := folders[*folderID]
+		if !ok {
+			break
+		}
+		seen[folder.ID] = true
+		path = append([]string{folder.Name}, path...)
+		folderID = folder.ParentID
+	}
+	return path
+}
+
+func headersText(headers map[string]string) string {
+	lines := make([]string, 0, len(headers))
+	for key, value := range headers {
+		lines = append(lines, key+": "+value)
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
+
+type searchField struct {
+	name string
+	text string
+}
+
+// matchSnippet finds the first field containing one of the terms and returns its name with the
+// text around the match on a single line
+func matchSnippet(terms []string, fields []searchField) (string, string) {
+	for _, term := range terms {
+		pattern := regexp.MustCompile(`(?i)` + regexp.QuoteMeta(term))
+		for _, field := range fields {
+			location := pattern.FindStringIndex(field.text)
+			if location == nil {
+				continue
+			}
+			return field.name, snippet(field.text, location[0])
+		}
+	}
+	if len(fields) > 0 {
+		return fields[0].name, snippet(fields[0].text, 0)
+	}
+	return "", ""
+}
+
+func snippet(text string, at int) string {
+	start := at - snippetContext
+	if start < 0 {
+		start = 0
+	}
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	end := start + snippetLength
+	if end > len(text) {
+		end = len(text)
+	}
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end++
+	}
+
+	result := strings.Join(strings.Fields(text[start:end]), " ")
+	if start > 0 {
+		result = "…" + result
+	}
+	if end < len(text) {
+		result += "…"
+	}
+	return result
+}
@@ -0,0 +1,151 @@
+//go:build sqlite_fts5
+
+package database
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// SearchBackend names how Search finds matches in this build
+const SearchBackend = "fts5"
+
+// The index has one row per request, folder and history response. Its rowid encodes the kind
+// and the ID of the item, so that a changed item is replaced without scanning the index.
+var searchKinds = map[string]int{"request": 1, "folder": 2, "response": 3}
+
+const searchKindCount = 4
+
+// searchDocuments selects what is indexed for each kind of item, by ID. Trashed items are
+// indexed too and left out when searching, so that restoring them needs no reindexing.
+// Descriptions are indexed with the body.
+var searchDocuments = map[string]string{
+	"request": `SELECT project_id, name, url, headers, body || char(10) || description FROM requests WHERE id = ?`,
+	"folder":  `SELECT project_id, name, '', '', description FROM folders WHERE id = ?`,
+	"response": `SELECT r.project_id, '', '', '', COALESCE(json_extract(h.response, '$.body'), '')
+				 FROM request_history h JOIN requests r ON r.id = h.request_id WHERE h.id = ?`,
+}
+
+// refreshSearchIndex creates the index when it is missing, filling it with every item, and
+// applies the changes queued since the last search
+func (db *DB) refreshSearchIndex() error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var exists int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'search_index'`).Scan(&exists); err != nil {
+		return err
+	}
+	if exists == 0 {
+		err := execAll(tx.Tx,
+			`CREATE VIRTUAL TABLE search_index USING fts5(
+				project_id UNINDEXED, name, url, headers, body, tokenize = 'unicode61'
+			)`,
+			`INSERT OR IGNORE INTO search_changes (kind, item_id) SELECT 'request', id FROM requests`,
+			`INSERT OR IGNORE INTO search_changes (kind, item_id) SELECT 'folder', id FROM folders`,
+			`INSERT OR IGNORE INTO search_changes (kind, item_id) SELECT 'response', id FROM request_history`,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	rows, err := tx.Query(`SELECT kind, item_id FROM search_changes`)
+	if err != nil {
+		return err
+	}
+	var changes []searchHit
+	for rows.Next() {
+		var change searchHit
+		if err := rows.Scan(&change.kind, &change.id); err != nil {
+			rows.Close()
+			return err
+		}
+		changes = append(changes, change)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		code, ok := searchKinds[change.kind]
+		if !ok {
+			continue
+		}
+		rowid := change.id*searchKindCount + code
+		if _, err := tx.Exec(`DELETE FROM search_index WHERE rowid = ?`, rowid); err != nil {
+			return err
+		}
+
+		var projectID int
+		var name, url, headers, body string
+		err := tx.QueryRow(searchDocuments[change.kind], change.id).Scan(&projectID, &name, &url, &headers, &body)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`INSERT INTO search_index (rowid, project_id, name, url, headers, body) VALUES (?, ?, ?, ?, ?, ?)`,
+			rowid, projectID, name, url, headers, body)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM search_changes`); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// searchHits finds matches in the FTS5 index, ranked by relevance. Every term matches as a
+// prefix, so "user" finds "users".
+func (db *DB) searchHits(terms []string, projectID int, responses bool, limit int) ([]searchHit, error) {
+	if err := db.refreshSearchIndex(); err != nil {
+		return nil, err
+	}
+
+	phrases := make([]string, len(terms))
+	for i, term := range terms {
+		phrases[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"*`
+	}
+
+	query := `SELECT rowid FROM search_index WHERE search_index MATCH ?`
+	args := []interface{}{strings.Join(phrases, " ")}
+	if projectID != 0 {
+		query += ` AND project_id = ?`
+		args = append(args, projectID)
+	}
+	if !responses {
+		query += ` AND rowid % ? != ?`
+		args = append(args, searchKindCount, searchKinds["response"])
+	}
+	// Trashed items are skipped when results are built, so ask for a few more
+	query += ` ORDER BY rank LIMIT ?`
+	args = append(args, limit*2)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hits []searchHit
+	for rows.Next() {
+		var rowid int
+		if err := rows.Scan(&rowid); err != nil {
+			return nil, err
+		}
+		for kind, code := range searchKinds {
+			if rowid%searchKindCount == code {
+				hits = append(hits, searchHit{kind: kind, id: rowid / searchKindCount})
+			}
+		}
+	}
+	return hits, rows.Err()
+}
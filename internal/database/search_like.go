@@ -0,0 +1,73 @@
+//go:build !sqlite_fts5
+
+package database
+
+import "strings"
+
+// SearchBackend names how Search finds matches in this build
+const SearchBackend = "like"
+
+// likeSearch finds one kind of item: query selects the IDs of the items that are not trashed,
+// and each term must appear in one of columns
+type likeSearch struct {
+	kind    string
+	query   string
+	project string
+	columns []string
+	order   string
+}
+
+var likeSearches = []likeSearch{
+	{"request", `SELECT r.id FROM requests r WHERE r.deleted_at IS NULL`, "r.project_id",
+		[]string{"r.name", "r.url", "r.headers", "r.body", "r.description"}, `r.updated_at DESC`},
+	{"folder", `SELECT f.id FROM folders f WHERE f.deleted_at IS NULL`, "f.project_id",
+		[]string{"f.name", "f.description"}, `f.updated_at DESC`},
+	{"response", `SELECT h.id FROM request_history h JOIN requests r ON r.id = h.request_id WHERE r.deleted_at IS NULL`, "r.project_id",
+		[]string{"json_extract(h.response, '$.body')"}, `h.executed_at DESC`},
+}
+
+// searchHits finds matches with LIKE, which scans every row: requests first, then folders and
+// the newest responses, each kind newest first
+func (db *DB) searchHits(terms []string, projectID int, responses bool, limit int) ([]searchHit, error) {
+	var hits []searchHit
+	for _, search := range likeSearches {
+		if len(hits) >= limit || (search.kind == "response" && !responses) {
+			continue
+		}
+
+		query := search.query
+		var args []interface{}
+		if projectID != 0 {
+			query += ` AND ` + search.project + ` = ?`
+			args = append(args, projectID)
+		}
+		for _, term := range terms {
+			conditions := make([]string, len(search.columns))
+			for i, column := range search.columns {
+				conditions[i] = column + ` LIKE ? ESCAPE '\'`
+				args = append(args, "%"+likeEscaper.Replace(term)+"%")
+			}
+			query += ` AND (` + strings.Join(conditions, ` OR `) + `)`
+		}
+		query += ` ORDER BY ` + search.order + ` LIMIT ?`
+		args = append(args, limit-len(hits))
+
+		rows, err := db.Query(query, args...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			hits = append(hits, searchHit{kind: search.kind, id: id})
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return hits, nil
+}
@@ -0,0 +1,303 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"rikuest/internal/models"
+)
+
+// DefaultTagColor is the color of tags created by tagging a request
+const DefaultTagColor = "#64748b"
+
+// GetTags lists the tags of a project by name, with the number of requests carrying each
+func (db *DB) GetTags(projectID int) ([]models.Tag, error) {
+	return db.queryTags("t.project_id = ?", projectID)
+}
+
+// GetTag returns a tag, or nil if there is none with that ID
+func (db *DB) GetTag(id int) (*models.Tag, error) {
+	tags, err := db.queryTags("t.id = ?", id)
+	if err != nil || len(tags) == 0 {
+		return nil, err
+	}
+	return &tags[0], nil
+}
+
+func (db *DB) queryTags(where string, args ...interface{}) ([]models.Tag, error) {
+	query := `SELECT t.id, t.project_id, t.name, t.color, t.created_at,
+			  (SELECT COUNT(*) FROM request_tags rt JOIN requests r ON r.id = rt.request_id
+			   WHERE rt.tag_id = t.id AND r.deleted_at IS NULL)
+			  FROM tags t WHERE ` + where + ` ORDER BY t.name`
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []models.Tag{}
+	for rows.Next() {
+		var tag models.Tag
+		if err := rows.Scan(&tag.ID, &tag.ProjectID, &tag.Name, &tag.Color, &tag.CreatedAt, &tag.Requests); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+func (db *DB) CreateTag(tag *models.Tag) error {
+	query := `INSERT INTO tags (project_id, name, color) VALUES (?, ?, ?) RETURNING id, created_at`
+	return db.QueryRow(query, tag.ProjectID, tag.Name, tag.Color).Scan(&tag.ID, &tag.CreatedAt)
+}
+
+// UpdateTag renames or recolors a tag. The requests carrying it follow the new name.
+func (db *DB) UpdateTag(tag *models.Tag) error {
+	_, err := db.Exec(`UPDATE tags SET name = ?, color = ? WHERE id = ?`, tag.Name, tag.Color, tag.ID)
+	return err
+}
+
+// DeleteTag deletes a tag and removes it from the requests carrying it
+func (db *DB) DeleteTag(id int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM request_tags WHERE tag_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM tags WHERE id = ?`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SetRequestTags replaces the tags of a request, creating the ones its project does not have
+func (db *DB) SetRequestTags(requestID int, tags []string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var projectID int
+	if err := tx.QueryRow(`SELECT project_id FROM requests WHERE id = ?`, requestID).Scan(&projectID); err != nil {
+		return fmt.Errorf("request not found: %w", err)
+	}
+	if err := setRequestTags(tx, requestID, projectID, tags); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func setRequestTags(tx *Tx, requestID, projectID int, tags []string) error {
+	if _, err := tx.Exec(`DELETE FROM request_tags WHERE request_id = ?`, requestID); err != nil {
+		return err
+	}
+	for _, name := range tags {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		_, err := tx.Exec(`INSERT OR IGNORE INTO tags (project_id, name, color) VALUES (?, ?, ?)`, projectID, name, DefaultTagColor)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`INSERT OR IGNORE INTO request_tags (request_id, tag_id)
+				  SELECT ?, id FROM tags WHERE project_id = ? AND name = ?`, requestID, projectID, name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyRequestTags gives a request the tags of another, creating them in its project when the
+// other request belongs to another project
+func copyRequestTags(tx *Tx, fromID, toID, projectID int) error {
+	_, err := tx.Exec(`INSERT OR IGNORE INTO tags (project_id, name, color)
+			  SELECT ?, t.name, t.color FROM request_tags rt JOIN tags t ON t.id = rt.tag_id WHERE rt.request_id = ?`,
+		projectID, fromID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`INSERT OR IGNORE INTO request_tags (request_id, tag_id)
+			  SELECT ?, target.id FROM request_tags rt JOIN tags t ON t.id = rt.tag_id
+			  JOIN tags target ON target.project_id = ? AND target.name = t.name WHERE rt.request_id = ?`,
+		toID, projectID, fromID)
+	return err
+}
+
+// moveRequestTags points the tags of a request moved to another project to the tags of that
+// project with the same names
+func moveRequestTags(tx *Tx, requestID, projectID int) error {
+	if err := copyRequestTags(tx, requestID, requestID, projectID); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`DELETE FROM request_tags WHERE request_id = ? AND tag_id NOT IN (SELECT id FROM tags WHERE project_id = ?)`,
+		requestID, projectID)
+	return err
+}
+
+// attachTags fills in the tags of requests
+func (db *DB) attachTags(requests []models.Request) error {
+	index := make(map[int]*models.Request, len(requests))
+	projects := make(map[int]bool)
+	for i := range requests {
+		requests[i].Tags = []string{}
+		index[requests[i].ID] = &requests[i]
+		projects[requests[i].ProjectID] = true
+	}
+
+	for projectID := range projects {
+		rows, err := db.Query(`SELECT rt.request_id, t.name FROM request_tags rt JOIN tags t ON t.id = rt.tag_id
+				  WHERE t.project_id = ? ORDER BY t.name`, projectID)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var requestID int
+			var name string
+			if err := rows.Scan(&requestID, &name); err != nil {
+				rows.Close()
+				return err
+			}
+			if request, ok := index[requestID]; ok {
+				request.Tags = append(request.Tags, name)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindRequests returns the requests of a project matching a filter
+func (db *DB) FindRequests(projectID int, filter models.RequestFilter) ([]models.Request, error) {
+	where := []string{"project_id = ?", "deleted_at IS NULL"}
+	args := []interface{}{projectID}
+
+	if method := strings.TrimSpace(filter.Method); method != "" {
+		where = append(where, "UPPER(method) = ?")
+		args = append(args, strings.ToUpper(method))
+	}
+	if pattern := strings.TrimSpace(filter.URL); pattern != "" {
+		where = append(where, `url LIKE ? ESCAPE '\'`)
+		args = append(args, urlPattern(pattern))
+	}
+
+	var tags []string
+	for _, tag := range filter.Tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) > 0 {
+		// Names are compared without case, as in the tags table
+		where = append(where, `(SELECT COUNT(DISTINCT t.id) FROM request_tags rt JOIN tags t ON t.id = rt.tag_id
+				  WHERE rt.request_id = requests.id AND t.name IN (?`+strings.Repeat(", ?", len(tags)-1)+`)) = ?`)
+		for _, tag := range tags {
+			args = append(args, tag)
+		}
+		args = append(args, len(uniqueFold(tags)))
+	}
+
+	if status := strings.TrimSpace(filter.Status); status != "" {
+		latest := `(SELECT h.status FROM request_history h WHERE h.request_id = requests.id
+				  ORDER BY h.executed_at DESC, h.id DESC LIMIT 1)`
+		if strings.EqualFold(status, "none") {
+			where = append(where, latest+" IS NULL")
+		} else {
+			low, high, err := parseStatusFilter(status)
+			if err != nil {
+				return nil, err
+			}
+			where = append(where, latest+" BETWEEN ? AND ?")
+			args = append(args, low, high)
+		}
+	}
+
+	return db.queryRequests(strings.Join(where, " AND "), args...)
+}
+
+// likeEscaper escapes the wildcards of LIKE in a value, for patterns using ESCAPE '\'
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// urlPattern turns a URL pattern where * matches anything into a LIKE pattern
+func urlPattern(pattern string) string {
+	escaped := likeEscaper.Replace(pattern)
+	if !strings.Contains(pattern, "*") {
+		return "%" + escaped + "%"
+	}
+	return strings.ReplaceAll(escaped, "*", "%")
+}
+
+func uniqueFold(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var unique []string
+	for _, value := range values {
+		if key := strings.ToLower(value); !seen[key] {
+			seen[key] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
+
+// Saved filter operations
+func (db *DB) GetSavedFilters(projectID int) ([]models.SavedFilter, error) {
+	return db.querySavedFilters("project_id = ? ORDER BY name", projectID)
+}
+
+// GetSavedFilter returns a saved filter, or nil if there is none with that ID
+func (db *DB) GetSavedFilter(id int) (*models.SavedFilter, error) {
+	filters, err := db.querySavedFilters("id = ?", id)
+	if err != nil || len(filters) == 0 {
+		return nil, err
+	}
+	return &filters[0], nil
+}
+
+func (db *DB) querySavedFilters(where string, args ...interface{}) ([]models.SavedFilter, error) {
+	rows, err := db.Query(`SELECT id, project_id, name, filter, created_at, updated_at FROM saved_filters WHERE `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	filters := []models.SavedFilter{}
+	for rows.Next() {
+		var filter models.SavedFilter
+		var filterJSON string
+		if err := rows.Scan(&filter.ID, &filter.ProjectID, &filter.Name, &filterJSON, &filter.CreatedAt, &filter.UpdatedAt); err != nil {
+			return nil, err
+		}
+		json.Unmarshal([]byte(filterJSON), &filter.Filter)
+		filters = append(filters, filter)
+	}
+	return filters, rows.Err()
+}
+
+func (db *DB) CreateSavedFilter(filter *models.SavedFilter) error {
+	filterJSON, _ := json.Marshal(filter.Filter)
+	query := `INSERT INTO saved_filters (project_id, name, filter) VALUES (?, ?, ?) RETURNING id, created_at, updated_at`
+	return db.QueryRow(query, filter.ProjectID, filter.Name, string(filterJSON)).Scan(
+		&filter.ID, &filter.CreatedAt, &filter.UpdatedAt)
+}
+
+func (db *DB) UpdateSavedFilter(filter *models.SavedFilter) error {
+	filterJSON, _ := json.Marshal(filter.Filter)
+	query := `UPDATE saved_filters SET name = ?, filter = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
+	_, err := db.Exec(query, filter.Name, string(filterJSON), filter.ID)
+	return err
+}
+
+func (db *DB) DeleteSavedFilter(id int) error {
+	_, err := db.Exec(`DELETE FROM saved_filters WHERE id = ?`, id)
+	return err
+}
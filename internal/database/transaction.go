@@ -0,0 +1,104 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Tx is a transaction started by Begin. Inside InTransaction it is a savepoint of the enclosing
+// transaction, so that methods keep committing and rolling back their own work.
+type Tx struct {
+	*sql.Tx
+	savepoint string
+	done      bool
+}
+
+// Commit commits the transaction, or releases the savepoint into the enclosing transaction
+func (tx *Tx) Commit() error {
+	if tx.savepoint == "" {
+		return tx.Tx.Commit()
+	}
+	if tx.done {
+		return sql.ErrTxDone
+	}
+	tx.done = true
+	_, err := tx.Tx.Exec("RELEASE SAVEPOINT " + tx.savepoint)
+	return err
+}
+
+// Rollback aborts the transaction, or undoes the work of the savepoint only. Like sql.Tx, it does
+// nothing after Commit.
+func (tx *Tx) Rollback() error {
+	if tx.savepoint == "" {
+		return tx.Tx.Rollback()
+	}
+	if tx.done {
+		return sql.ErrTxDone
+	}
+	tx.done = true
+	if _, err := tx.Tx.Exec("ROLLBACK TO SAVEPOINT " + tx.savepoint); err != nil {
+		return err
+	}
+	_, err := tx.Tx.Exec("RELEASE SAVEPOINT " + tx.savepoint)
+	return err
+}
+
+// Begin starts a transaction, or a savepoint when the database is bound to one by InTransaction
+func (db *DB) Begin() (*Tx, error) {
+	if db.tx == nil {
+		tx, err := db.DB.Begin()
+		if err != nil {
+			return nil, err
+		}
+		return &Tx{Tx: tx}, nil
+	}
+
+	*db.savepoints++
+	savepoint := fmt.Sprintf("sp%d", *db.savepoints)
+	if _, err := db.tx.Exec("SAVEPOINT " + savepoint); err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: db.tx, savepoint: savepoint}, nil
+}
+
+func (db *DB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	if db.tx != nil {
+		return db.tx.Exec(query, args...)
+	}
+	return db.DB.Exec(query, args...)
+}
+
+func (db *DB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	if db.tx != nil {
+		return db.tx.Query(query, args...)
+	}
+	return db.DB.Query(query, args...)
+}
+
+func (db *DB) QueryRow(query string, args ...interface{}) *sql.Row {
+	if db.tx != nil {
+		return db.tx.QueryRow(query, args...)
+	}
+	return db.DB.QueryRow(query, args...)
+}
+
+// InTransaction runs fn with a database whose every query runs in a single transaction, which is
+// committed when fn returns nil and rolled back otherwise. Work spanning several methods, such as
+// an import, then either happens completely or not at all. Calls nest: inside a transaction, fn
+// runs in a savepoint of it.
+func (db *DB) InTransaction(fn func(tx *DB) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	bound := &DB{DB: db.DB, path: db.path, tx: tx.Tx, savepoints: db.savepoints}
+	if bound.savepoints == nil {
+		bound.savepoints = new(int)
+	}
+	if err := fn(bound); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
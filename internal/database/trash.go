@@ -0,0 +1,348 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"rikuest/internal/models"
+)
+
+// Deleting a project, folder or request moves it to the trash: its rows are marked with
+// deleted_at and the trash_id of a trash entry, and every query that lists them skips marked
+// rows. A folder is trashed with the subfolders and requests it holds, under the same entry,
+// unless it is deleted keeping its contents.
+
+// DeleteProject moves a project to the trash
+func (db *DB) DeleteProject(id int) error {
+	project, err := db.GetProject(id)
+	if err != nil {
+		return err
+	}
+
+	return db.trash(project.ID, "project", project.ID, project.Name, func(tx *Tx, trashID int, now time.Time) error {
+		_, err := tx.Exec("UPDATE projects SET deleted_at = ?, trash_id = ? WHERE id = ?", now, trashID, id)
+		return err
+	})
+}
+
+// DeleteFolder moves a folder to the trash along with its subfolders and requests
+func (db *DB) DeleteFolder(id int) error {
+	folder, err := db.GetFolder(id)
+	if err != nil {
+		return err
+	}
+
+	return db.trash(folder.ProjectID, "folder", folder.ID, folder.Name, func(tx *Tx, trashID int, now time.Time) error {
+		subtree := `WITH RECURSIVE subtree(id) AS (
+				SELECT ? UNION SELECT f.id FROM folders f JOIN subtree s ON f.parent_id = s.id WHERE f.deleted_at IS NULL
+			)`
+		if _, err := tx.Exec(subtree+` UPDATE requests SET deleted_at = ?, trash_id = ?
+				  WHERE deleted_at IS NULL AND folder_id IN (SELECT id FROM subtree)`, id, now, trashID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(subtree+` UPDATE folders SET deleted_at = ?, trash_id = ?
+				  WHERE deleted_at IS NULL AND id IN (SELECT id FROM subtree)`, id, now, trashID); err != nil {
+			return err
+		}
+		return renumber(tx, "folders", "parent_id", folder.ProjectID, folder.ParentID)
+	})
+}
+
+// DeleteFolderKeepingContents moves a folder to the trash on its own. Its subfolders take its
+// place in its parent, and its requests go after those of the parent.
+func (db *DB) DeleteFolderKeepingContents(id int) error {
+	folder, err := db.GetFolder(id)
+	if err != nil {
+		return err
+	}
+
+	return db.trash(folder.ProjectID, "folder", folder.ID, folder.Name, func(tx *Tx, trashID int, now time.Time) error {
+		siblings, err := siblingIDs(tx, "folders", "parent_id", folder.ProjectID, folder.ParentID)
+		if err != nil {
+			return err
+		}
+		children, err := siblingIDs(tx, "folders", "parent_id", folder.ProjectID, &folder.ID)
+		if err != nil {
+			return err
+		}
+		order := make([]int, 0, len(siblings)+len(children))
+		for _, sibling := range siblings {
+			if sibling == folder.ID {
+				order = append(order, children...)
+			} else {
+				order = append(order, sibling)
+			}
+		}
+
+		position, err := nextPosition(tx, "requests", "folder_id", folder.ProjectID, folder.ParentID)
+		if err != nil {
+			return err
+		}
+		queries := []struct {
+			query string
+			args  []interface{}
+		}{
+			{`UPDATE folders SET parent_id = ?, updated_at = CURRENT_TIMESTAMP WHERE parent_id = ? AND deleted_at IS NULL`,
+				[]interface{}{folder.ParentID, folder.ID}},
+			{`UPDATE requests SET folder_id = ?, position = position + ?, updated_at = CURRENT_TIMESTAMP
+				  WHERE folder_id = ? AND deleted_at IS NULL`, []interface{}{folder.ParentID, position, folder.ID}},
+			{"UPDATE folders SET deleted_at = ?, trash_id = ? WHERE id = ?", []interface{}{now, trashID, folder.ID}},
+		}
+		for _, q := range queries {
+			if _, err := tx.Exec(q.query, q.args...); err != nil {
+				return err
+			}
+		}
+
+		if err := writePositions(tx, "folders", order); err != nil {
+			return err
+		}
+		return renumber(tx, "requests", "folder_id", folder.ProjectID, folder.ParentID)
+	})
+}
+
+// DeleteRequest moves a request to the trash
+func (db *DB) DeleteRequest(id int) error {
+	request, err := db.GetRequest(id)
+	if err != nil {
+		return err
+	}
+
+	return db.trash(request.ProjectID, "request", request.ID, request.Name, func(tx *Tx, trashID int, now time.Time) error {
+		if _, err := tx.Exec("UPDATE requests SET deleted_at = ?, trash_id = ? WHERE id = ?", now, trashID, id); err != nil {
+			return err
+		}
+		return renumber(tx, "requests", "folder_id", request.ProjectID, request.FolderID)
+	})
+}
+
+func (db *DB) trash(projectID int, kind string, itemID int, name string, mark func(tx *Tx, trashID int, now time.Time) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	var trashID int
+	err = tx.QueryRow(`INSERT INTO trash (project_id, kind, item_id, name, deleted_at) VALUES (?, ?, ?, ?, ?) RETURNING id`,
+		projectID, kind, itemID, name, now).Scan(&trashID)
+	if err != nil {
+		return err
+	}
+	if err := mark(tx, trashID, now); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetTrash lists the folders and requests deleted from a project, newest first
+func (db *DB) GetTrash(projectID int) ([]models.TrashItem, error) {
+	return db.queryTrash(`WHERE t.project_id = ? AND t.kind != 'project'`, projectID)
+}
+
+// GetTrashedProjects lists the deleted projects, newest first
+func (db *DB) GetTrashedProjects() ([]models.TrashItem, error) {
+	return db.queryTrash(`WHERE t.kind = 'project'`)
+}
+
+// GetTrashItem returns a trash entry, or nil if there is none with that ID
+func (db *DB) GetTrashItem(id int) (*models.TrashItem, error) {
+	items, err := db.queryTrash(`WHERE t.id = ?`, id)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return &items[0], nil
+}
+
+// GetTrashBefore lists the trash entries deleted before a time, for purging
+func (db *DB) GetTrashBefore(before time.Time) ([]models.TrashItem, error) {
+	return db.queryTrash(`WHERE t.deleted_at < ?`, before.UTC())
+}
+
+func (db *DB) queryTrash(where string, args ...interface{}) ([]models.TrashItem, error) {
+	query := `SELECT t.id, t.project_id, t.kind, t.item_id, t.name, t.deleted_at,
+			  (SELECT COUNT(*) FROM requests r WHERE r.trash_id = t.id)
+			  FROM trash t ` + where + ` ORDER BY t.deleted_at DESC, t.id DESC`
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []models.TrashItem{}
+	for rows.Next() {
+		var item models.TrashItem
+		if err := rows.Scan(&item.ID, &item.ProjectID, &item.Kind, &item.ItemID, &item.Name, &item.DeletedAt, &item.Requests); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// RestoreTrash brings a trash entry back where it was. A folder or request whose parent folder
+// is gone or still in the trash is restored at the root instead.
+func (db *DB) RestoreTrash(id int) error {
+	item, err := db.GetTrashItem(id)
+	if err != nil {
+		return err
+	}
+	if item == nil {
+		return sql.ErrNoRows
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	switch item.Kind {
+	case "project":
+		if _, err := tx.Exec("UPDATE projects SET deleted_at = NULL, trash_id = NULL WHERE id = ?", item.ItemID); err != nil {
+			return err
+		}
+	case "folder":
+		if _, err := tx.Exec(`UPDATE folders SET parent_id = NULL WHERE id = ? AND parent_id IS NOT NULL AND parent_id NOT IN
+				  (SELECT id FROM folders WHERE deleted_at IS NULL)`, item.ItemID); err != nil {
+			return err
+		}
+	case "request":
+		if _, err := tx.Exec(`UPDATE requests SET folder_id = NULL WHERE id = ? AND folder_id IS NOT NULL AND folder_id NOT IN
+				  (SELECT id FROM folders WHERE deleted_at IS NULL)`, item.ItemID); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown trash item kind %q", item.Kind)
+	}
+
+	for _, table := range []string{"folders", "requests"} {
+		if _, err := tx.Exec("UPDATE "+table+" SET deleted_at = NULL, trash_id = NULL WHERE trash_id = ?", id); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec("DELETE FROM trash WHERE id = ?", id); err != nil {
+		return err
+	}
+
+	// The item gets back its position, and the items that took its place meanwhile move down
+	tables := map[string]struct{ table, parent string }{
+		"folder":  {"folders", "parent_id"},
+		"request": {"requests", "folder_id"},
+	}
+	if t, ok := tables[item.Kind]; ok {
+		var parentID *int
+		var position int
+		err := tx.QueryRow("SELECT "+t.parent+", position FROM "+t.table+" WHERE id = ?", item.ItemID).Scan(&parentID, &position)
+		if err != nil {
+			return err
+		}
+		target := models.CopyTarget{ProjectID: item.ProjectID, FolderID: parentID, Position: &position}
+		if err := place(tx, t.table, t.parent, target, item.ItemID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// PurgeTrash permanently deletes a trash entry with the history, examples and revisions of
+// its requests. Purging a project deletes everything in it.
+func (db *DB) PurgeTrash(id int) error {
+	item, err := db.GetTrashItem(id)
+	if err != nil {
+		return err
+	}
+	if item == nil {
+		return sql.ErrNoRows
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if item.Kind == "project" {
+		err = purgeProject(tx, item.ItemID)
+	} else {
+		err = purgeRows(tx, "trash_id = ?", id)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM trash WHERE id = ?", id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// PurgeProject permanently deletes a project and everything in it, trashed or not
+func (db *DB) PurgeProject(id int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := purgeProject(tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// purgeRows deletes the folders and requests matching where, and everything attached to them.
+// Folders and requests trashed on their own inside a purged folder are kept, at the root.
+func purgeRows(tx *Tx, where string, args ...interface{}) error {
+	folders := "SELECT id FROM folders WHERE " + where
+	detach := []string{
+		"UPDATE folders SET parent_id = NULL WHERE parent_id IN (" + folders + ") AND id NOT IN (" + folders + ")",
+		"UPDATE requests SET folder_id = NULL WHERE folder_id IN (" + folders + ") AND id NOT IN (SELECT id FROM requests WHERE " + where + ")",
+	}
+	for _, query := range detach {
+		if _, err := tx.Exec(query, append(append([]interface{}{}, args...), args...)...); err != nil {
+			return err
+		}
+	}
+
+	requests := "SELECT id FROM requests WHERE " + where
+	queries := []string{
+		"DELETE FROM request_history WHERE request_id IN (" + requests + ")",
+		"DELETE FROM request_examples WHERE request_id IN (" + requests + ")",
+		"DELETE FROM request_revisions WHERE request_id IN (" + requests + ")",
+		"DELETE FROM openapi_operations WHERE request_id IN (" + requests + ")",
+		"DELETE FROM request_tags WHERE request_id IN (" + requests + ")",
+		"DELETE FROM requests WHERE " + where,
+		"DELETE FROM folders WHERE " + where,
+	}
+	for _, query := range queries {
+		if _, err := tx.Exec(query, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func purgeProject(tx *Tx, projectID int) error {
+	if err := purgeRows(tx, "project_id = ?", projectID); err != nil {
+		return err
+	}
+	queries := []string{
+		"DELETE FROM openapi_operations WHERE spec_id IN (SELECT id FROM openapi_specs WHERE project_id = ?)",
+		"DELETE FROM openapi_specs WHERE project_id = ?",
+		"DELETE FROM environments WHERE project_id = ?",
+		"DELETE FROM saved_filters WHERE project_id = ?",
+		"DELETE FROM tags WHERE project_id = ?",
+		"DELETE FROM storage_entries WHERE project_id = ?",
+		"DELETE FROM project_storage WHERE project_id = ?",
+		"DELETE FROM trash WHERE project_id = ?",
+		"DELETE FROM projects WHERE id = ?",
+	}
+	for _, query := range queries {
+		if _, err := tx.Exec(query, projectID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
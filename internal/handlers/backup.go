@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+
+	"rikuest/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetBackups lists the database backups, newest first
+func (h *Handler) GetBackups(c *gin.Context) {
+	backups, err := h.services.Backup.GetBackups()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, backups)
+}
+
+func (h *Handler) CreateBackup(c *gin.Context) {
+	backup, err := h.services.Backup.CreateBackup("manual")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, backup)
+}
+
+// RestoreBackup replaces the database with a backup, after backing up the current one
+func (h *Handler) RestoreBackup(c *gin.Context) {
+	backup, err := h.services.Backup.RestoreBackup(c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, backup)
+}
+
+func (h *Handler) GetBackupConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, h.services.Backup.GetConfig())
+}
+
+func (h *Handler) UpdateBackupConfig(c *gin.Context) {
+	var config models.BackupConfig
+	if err := c.ShouldBindJSON(&config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.services.Backup.SetConfig(config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, config)
+}
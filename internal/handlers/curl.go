@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CurlPayload struct {
+	Command  string `json:"command" binding:"required"`
+	FolderID *int   `json:"folder_id"`
+	Name     string `json:"name"`
+}
+
+// ParseCurl converts a curl command into a request without saving it
+func (h *Handler) ParseCurl(c *gin.Context) {
+	var payload CurlPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	request, err := h.services.Format.ParseCurlRequest(payload.Command)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, request)
+}
+
+// ImportCurl saves a curl command as a new request in a project
+func (h *Handler) ImportCurl(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	var payload CurlPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	request, err := h.services.Request.ImportCurl(projectID, payload.FolderID, payload.Name, payload.Command)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, request)
+}
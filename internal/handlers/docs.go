@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DocsPayload struct {
+	Path string `json:"path" binding:"required"`
+}
+
+// ExportProjectDocs downloads the documentation site of a project as a ZIP file
+func (h *Handler) ExportProjectDocs(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	archive, err := h.services.Docs.ArchiveProject(projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=project-"+c.Param("id")+"-docs.zip")
+	c.Data(http.StatusOK, "application/zip", archive)
+}
+
+// WriteProjectDocs writes the documentation site of a project to a directory of the machine
+// running the server, inside its files directory, and returns the path of its index page
+func (h *Handler) WriteProjectDocs(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	var payload DocsPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	index, err := h.services.Docs.WriteProject(projectID, payload.Path)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"path": index})
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"rikuest/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CopyTargetPayload is where a duplicated or moved item goes. Without a project the item stays in
+// its project, except for a duplicated project, which then becomes a new project.
+type CopyTargetPayload struct {
+	ProjectID int    `json:"project_id"`
+	FolderID  *int   `json:"folder_id"`
+	Position  *int   `json:"position"`
+	Name      string `json:"name"`
+}
+
+func (p CopyTargetPayload) target() models.CopyTarget {
+	return models.CopyTarget{ProjectID: p.ProjectID, FolderID: p.FolderID, Position: p.Position, Name: p.Name}
+}
+
+// bindCopyTarget reads an optional target from the body
+func bindCopyTarget(c *gin.Context) (models.CopyTarget, bool) {
+	var payload CopyTargetPayload
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return models.CopyTarget{}, false
+		}
+	}
+	return payload.target(), true
+}
+
+// DuplicateRequest copies a request with its examples
+func (h *Handler) DuplicateRequest(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request ID"})
+		return
+	}
+	target, ok := bindCopyTarget(c)
+	if !ok {
+		return
+	}
+
+	request, err := h.services.Request.CopyRequest(id, target)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, request)
+}
+
+// DuplicateFolder copies a folder with all its subfolders and requests
+func (h *Handler) DuplicateFolder(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder ID"})
+		return
+	}
+	target, ok := bindCopyTarget(c)
+	if !ok {
+		return
+	}
+
+	folder, err := h.services.Folder.CopyFolder(id, target)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, folder)
+}
+
+// DuplicateProject copies a project into a new project, or its contents into another project
+func (h *Handler) DuplicateProject(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+	target, ok := bindCopyTarget(c)
+	if !ok {
+		return
+	}
+
+	project, err := h.services.Project.CopyProject(id, target)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, project)
+}
+
+// MoveFolder moves a folder with all its subfolders and requests, possibly to another project
+func (h *Handler) MoveFolder(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder ID"})
+		return
+	}
+	var payload CopyTargetPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.services.Folder.MoveFolder(id, payload.target()); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Folder moved successfully"})
+}
+
+// ReorderFoldersPayload lists every folder directly in a folder, or at the project root without
+// one, in their new order
+type ReorderFoldersPayload struct {
+	ParentID  *int  `json:"parent_id"`
+	FolderIDs []int `json:"folder_ids"`
+}
+
+// ReorderFolders sets the order of the folders of a folder or project root
+func (h *Handler) ReorderFolders(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+	var payload ReorderFoldersPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.services.Folder.ReorderFolders(id, payload.ParentID, payload.FolderIDs); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Folders reordered successfully"})
+}
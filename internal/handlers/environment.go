@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"rikuest/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) GetEnvironments(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	environments, err := h.services.Environment.GetEnvironments(projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if environments == nil {
+		environments = []models.Environment{}
+	}
+
+	c.JSON(http.StatusOK, environments)
+}
+
+func (h *Handler) CreateEnvironment(c *gin.Context) {
+	var environment models.Environment
+	if err := c.ShouldBindJSON(&environment); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.services.Environment.CreateEnvironment(&environment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, environment)
+}
+
+func (h *Handler) UpdateEnvironment(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid environment ID"})
+		return
+	}
+
+	var environment models.Environment
+	if err := c.ShouldBindJSON(&environment); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	environment.ID = id
+	if err := h.services.Environment.UpdateEnvironment(&environment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, environment)
+}
+
+func (h *Handler) DeleteEnvironment(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid environment ID"})
+		return
+	}
+
+	if err := h.services.Environment.DeleteEnvironment(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Environment deleted successfully"})
+}
+
+type SetActiveEnvironmentPayload struct {
+	EnvironmentID *int `json:"environment_id"`
+}
+
+// SetActiveEnvironment selects the environment used to execute a project's requests
+func (h *Handler) SetActiveEnvironment(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	var payload SetActiveEnvironmentPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.services.Environment.SetActiveEnvironment(projectID, payload.EnvironmentID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Active environment updated successfully"})
+}
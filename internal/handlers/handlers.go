@@ -1,13 +1,9 @@
 package handlers
 
 import (
-	"encoding/base64"
 	"io"
 	"net/http"
-	"net/url"
 	"strconv"
-	"strings"
-	"time"
 
 	"rikuest/internal/models"
 	"rikuest/internal/services"
@@ -19,36 +15,6 @@ type Handler struct {
 	services *services.Services
 }
 
-// getErrorStatusText returns a user-friendly status text based on the error message
-func getErrorStatusText(errorMsg string) string {
-	errorMsg = strings.ToLower(errorMsg)
-
-	if strings.Contains(errorMsg, "connection refused") {
-		return "Connection Refused"
-	}
-	if strings.Contains(errorMsg, "no such host") || strings.Contains(errorMsg, "no such domain") {
-		return "Host Not Found"
-	}
-	if strings.Contains(errorMsg, "timeout") || strings.Contains(errorMsg, "timed out") {
-		return "Request Timeout"
-	}
-	if strings.Contains(errorMsg, "eof") {
-		return "Connection Closed"
-	}
-	if strings.Contains(errorMsg, "certificate") || strings.Contains(errorMsg, "tls") || strings.Contains(errorMsg, "ssl") {
-		return "SSL/TLS Error"
-	}
-	if strings.Contains(errorMsg, "network") {
-		return "Network Error"
-	}
-	if strings.Contains(errorMsg, "dns") {
-		return "DNS Error"
-	}
-
-	// Default for unknown network errors
-	return "Connection Failed"
-}
-
 func NewHandler(services *services.Services) *Handler {
 	return &Handler{services: services}
 }
@@ -131,6 +97,41 @@ func (h *Handler) DeleteProject(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Project deleted successfully"})
 }
 
+// ExportProject downloads a project as a bundle file. History is included with ?history=true.
+func (h *Handler) ExportProject(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	bundle, err := h.services.Project.ExportProject(id, c.Query("history") == "true")
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=project-"+c.Param("id")+".rikuest.json")
+	c.JSON(http.StatusOK, bundle)
+}
+
+// ImportProject creates a new project from a bundle file
+func (h *Handler) ImportProject(c *gin.Context) {
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.services.Project.ImportProject(data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
 func (h *Handler) CreateRequest(c *gin.Context) {
 	var request models.Request
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -153,6 +154,17 @@ func (h *Handler) GetRequests(c *gin.Context) {
 		return
 	}
 
+	// Any of tag, method, url or status narrows the list, see requestFilter
+	if filter, ok := requestFilter(c); ok {
+		requests, err := h.services.Tag.FindRequests(projectID, filter)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, requests)
+		return
+	}
+
 	requests, err := h.services.Request.GetRequests(projectID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -192,7 +204,7 @@ func (h *Handler) UpdateRequest(c *gin.Context) {
 	}
 
 	request.ID = id
-	if err := h.services.Request.UpdateRequest(&request); err != nil {
+	if err := h.services.Request.UpdateRequest(&request, requestAuthor(c)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -222,182 +234,113 @@ func (h *Handler) ExecuteRequest(c *gin.Context) {
 		return
 	}
 
-	request, err := h.services.Request.GetRequest(id)
-	if err != nil {
+	if _, err := h.services.Request.GetRequest(id); err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Request not found"})
 		return
 	}
 
-	start := time.Now()
-
-	// Get configured timeout, default to 5 minutes
-	timeout, err := h.services.Config.GetRequestTimeout()
+	// Shares the execution path of the desktop app, including environment variables and history
+	response, err := h.services.Request.ExecuteRequest(id)
 	if err != nil {
-		timeout = 300 * time.Second // Default to 5 minutes on error
-	}
-
-	client := &http.Client{
-		Timeout: timeout,
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	// Build the complete URL with query parameters
-	finalURL := request.URL
-	if len(request.QueryParams) > 0 {
-		parsedURL, err := url.Parse(request.URL)
-		if err == nil {
-			queryValues := parsedURL.Query()
-
-			// Add query parameters from the request
-			for _, param := range request.QueryParams {
-				if param.Enabled && param.Key != "" {
-					queryValues.Add(param.Key, param.Value)
-				}
-			}
+	c.JSON(http.StatusOK, response)
+}
 
-			parsedURL.RawQuery = queryValues.Encode()
-			finalURL = parsedURL.String()
-		}
+// GetRequestHistory returns a page of the executions of a request, filtered by the query
+func (h *Handler) GetRequestHistory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request ID"})
+		return
 	}
 
-	// Prepare the request body based on body type
-	var body io.Reader
-	var bodyString string
-
-	if request.BodyType == "form" && len(request.FormData) > 0 {
-		// Handle form data
-		formValues := url.Values{}
-		for _, item := range request.FormData {
-			if item.Key != "" {
-				formValues.Add(item.Key, item.Value)
-			}
-		}
-		bodyString = formValues.Encode()
-		body = strings.NewReader(bodyString)
-	} else if request.Body != "" {
-		// Handle regular body content
-		bodyString = request.Body
-		body = strings.NewReader(request.Body)
+	filter, err := historyFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	req, err := http.NewRequest(request.Method, finalURL, body)
+	history, err := h.services.History.GetRequestHistory(id, filter)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Set custom User-Agent header
-	req.Header.Set("User-Agent", "Rikuest/1.0 (HTTP API Client)")
+	c.JSON(http.StatusOK, history)
+}
 
-	// Set headers from the request
-	for key, value := range request.Headers {
-		req.Header.Set(key, value)
+func (h *Handler) DeleteRequestHistoryItem(c *gin.Context) {
+	requestID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request ID"})
+		return
 	}
 
-	// Set authorization headers
-	switch request.AuthType {
-	case "bearer":
-		if request.BearerToken != "" {
-			req.Header.Set("Authorization", "Bearer "+request.BearerToken)
-		}
-	case "basic":
-		if request.BasicAuth.Username != "" || request.BasicAuth.Password != "" {
-			auth := request.BasicAuth.Username + ":" + request.BasicAuth.Password
-			encodedAuth := base64.StdEncoding.EncodeToString([]byte(auth))
-			req.Header.Set("Authorization", "Basic "+encodedAuth)
-		}
+	historyID, err := strconv.Atoi(c.Param("historyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid history ID"})
+		return
 	}
 
-	// Ensure Content-Type is set for form data if not already present
-	if request.BodyType == "form" && len(request.FormData) > 0 {
-		if req.Header.Get("Content-Type") == "" {
-			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-		}
+	if err := h.services.Request.DeleteRequestHistoryItem(requestID, historyID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
 
-	resp, err := client.Do(req)
-	duration := time.Since(start)
-
-	// Generate raw request
-	rawRequestString := h.services.Format.BuildRawRequest(request)
-
-	var response models.RequestResponse
+	c.JSON(http.StatusOK, gin.H{"message": "History item deleted successfully"})
+}
 
+// Response example handlers
+func (h *Handler) GetRequestExamples(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		// Handle network/connection errors as a response
-		statusText := getErrorStatusText(err.Error())
-		response = models.RequestResponse{
-			Status:     0,
-			StatusText: statusText,
-			Headers:    make(map[string]string),
-			Body:       err.Error(),
-			Duration:   duration.Milliseconds(),
-			Size:       int64(len(err.Error())),
-			RawRequest: rawRequestString,
-		}
-	} else {
-		defer resp.Body.Close()
-
-		responseBody, err := io.ReadAll(resp.Body)
-		if err != nil {
-			// Handle body read errors as a response
-			response = models.RequestResponse{
-				Status:     resp.StatusCode,
-				StatusText: resp.Status,
-				Headers:    make(map[string]string),
-				Body:       "Failed to read response body: " + err.Error(),
-				Duration:   duration.Milliseconds(),
-				Size:       0,
-				RawRequest: rawRequestString,
-			}
-		} else {
-			responseHeaders := make(map[string]string)
-			for key, values := range resp.Header {
-				responseHeaders[key] = strings.Join(values, ", ")
-			}
-
-			response = models.RequestResponse{
-				Status:     resp.StatusCode,
-				StatusText: resp.Status,
-				Headers:    responseHeaders,
-				Body:       string(responseBody),
-				Duration:   duration.Milliseconds(),
-				Size:       int64(len(responseBody)),
-				RawRequest: rawRequestString,
-			}
-		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request ID"})
+		return
 	}
 
-	history := &models.RequestHistory{
-		RequestID: id,
-		Response:  response,
+	examples, err := h.services.Request.GetExamples(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
 
-	// Save to history (ignore errors to ensure response is always returned)
-	if err := h.services.Request.SaveRequestHistory(history); err != nil {
-		// Log the error but don't fail the request execution
-		println("Warning: Failed to save request history:", err.Error())
+	if examples == nil {
+		examples = []models.ResponseExample{}
 	}
 
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, examples)
 }
 
-func (h *Handler) GetRequestHistory(c *gin.Context) {
+func (h *Handler) CreateRequestExample(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request ID"})
 		return
 	}
 
-	history, err := h.services.Request.GetRequestHistory(id)
-	if err != nil {
+	var example models.ResponseExample
+	if err := c.ShouldBindJSON(&example); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	example.RequestID = id
+	if err := h.services.Request.CreateExample(&example); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, history)
+	c.JSON(http.StatusCreated, example)
 }
 
-func (h *Handler) DeleteRequestHistoryItem(c *gin.Context) {
+type SaveHistoryExamplePayload struct {
+	Name string `json:"name"`
+}
+
+func (h *Handler) SaveHistoryAsExample(c *gin.Context) {
 	requestID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request ID"})
@@ -410,12 +353,59 @@ func (h *Handler) DeleteRequestHistoryItem(c *gin.Context) {
 		return
 	}
 
-	if err := h.services.Request.DeleteRequestHistoryItem(requestID, historyID); err != nil {
+	var payload SaveHistoryExamplePayload
+	// Every field is optional, so the body may be empty
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	example, err := h.services.Request.SaveHistoryAsExample(requestID, historyID, payload.Name)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, example)
+}
+
+func (h *Handler) UpdateRequestExample(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid example ID"})
+		return
+	}
+
+	var example models.ResponseExample
+	if err := c.ShouldBindJSON(&example); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	example.ID = id
+	if err := h.services.Request.UpdateExample(&example); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "History item deleted successfully"})
+	c.JSON(http.StatusOK, example)
+}
+
+func (h *Handler) DeleteRequestExample(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid example ID"})
+		return
+	}
+
+	if err := h.services.Request.DeleteExample(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Example deleted successfully"})
 }
 
 // Folder handlers
@@ -484,7 +474,13 @@ func (h *Handler) DeleteFolder(c *gin.Context) {
 		return
 	}
 
-	if err := h.services.Folder.DeleteFolder(id); err != nil {
+	// With keep_contents=true only the folder goes to the trash, and what it holds moves up
+	if c.Query("keep_contents") == "true" {
+		err = h.services.Folder.DeleteFolderKeepingContents(id)
+	} else {
+		err = h.services.Folder.DeleteFolder(id)
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -492,8 +488,11 @@ func (h *Handler) DeleteFolder(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Folder deleted successfully"})
 }
 
+// MoveRequestPayload moves a request within its project, or to the end of a folder of another
+// project when ProjectID is set
 type MoveRequestPayload struct {
 	RequestID int  `json:"request_id"`
+	ProjectID int  `json:"project_id"`
 	FolderID  *int `json:"folder_id"`
 	Position  int  `json:"position"`
 }
@@ -505,8 +504,14 @@ func (h *Handler) MoveRequest(c *gin.Context) {
 		return
 	}
 
-	if err := h.services.Request.MoveRequest(payload.RequestID, payload.FolderID, payload.Position); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if payload.ProjectID != 0 {
+		target := models.CopyTarget{ProjectID: payload.ProjectID, FolderID: payload.FolderID}
+		if err := h.services.Request.MoveRequestTo(payload.RequestID, target); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	} else if err := h.services.Request.MoveRequest(payload.RequestID, payload.FolderID, payload.Position); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -527,7 +532,7 @@ func (h *Handler) CopyRequestFormats(c *gin.Context) {
 		return
 	}
 
-	request, err := h.services.Request.GetRequest(id)
+	request, err := h.services.Request.GetRequestWithResponse(id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Request not found"})
 		return
@@ -553,7 +558,7 @@ func (h *Handler) CopyAllRequestFormats(c *gin.Context) {
 		return
 	}
 
-	request, err := h.services.Request.GetRequest(id)
+	request, err := h.services.Request.GetRequestWithResponse(id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Request not found"})
 		return
@@ -561,10 +566,12 @@ func (h *Handler) CopyAllRequestFormats(c *gin.Context) {
 
 	allFormats := h.services.Format.GetAllFormats(request)
 	formats := gin.H{
-		"raw":    allFormats.Raw,
-		"curl":   allFormats.Curl,
-		"fetch":  allFormats.Fetch,
-		"python": allFormats.Python,
+		"raw":       allFormats.Raw,
+		"curl":      allFormats.Curl,
+		"fetch":     allFormats.Fetch,
+		"python":    allFormats.Python,
+		"http-file": allFormats.HTTPFile,
+		"openapi":   allFormats.OpenAPI,
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"rikuest/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImportHAR imports a HAR archive sent as the request body into a project.
+// The optional folder_id query parameter selects the folder captures are filed under.
+func (h *Handler) ImportHAR(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	folderID, err := optionalIntQuery(c, "folder_id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder ID"})
+		return
+	}
+
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.services.HAR.ImportHAR(projectID, folderID, data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) ExportProjectHAR(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	file, err := h.services.HAR.ExportProject(projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	writeHAR(c, file, "project-"+c.Param("id")+".har")
+}
+
+func (h *Handler) ExportFolderHAR(c *gin.Context) {
+	folderID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder ID"})
+		return
+	}
+
+	file, err := h.services.HAR.ExportFolder(folderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	writeHAR(c, file, "folder-"+c.Param("id")+".har")
+}
+
+type ExportHistoryPayload struct {
+	HistoryIDs []int `json:"history_ids"`
+}
+
+func (h *Handler) ExportHistoryHAR(c *gin.Context) {
+	var payload ExportHistoryPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	file, err := h.services.HAR.ExportHistory(payload.HistoryIDs)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	writeHAR(c, file, "history.har")
+}
+
+func writeHAR(c *gin.Context, file *services.HARFile, filename string) {
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.JSON(http.StatusOK, file)
+}
+
+// optionalIntQuery parses an optional integer query parameter, returning nil when it is absent
+func optionalIntQuery(c *gin.Context, name string) (*int, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
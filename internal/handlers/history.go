@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"rikuest/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// historyFilter reads a history filter from the query: status, from and to as RFC 3339 times,
+// min_duration and max_duration in milliseconds, limit and offset
+func historyFilter(c *gin.Context) (models.HistoryFilter, error) {
+	filter := models.HistoryFilter{Status: c.Query("status")}
+
+	times := map[string]**time.Time{"from": &filter.From, "to": &filter.To}
+	for key, target := range times {
+		if value := c.Query(key); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s time %q, use RFC 3339 such as 2024-01-31T09:30:00Z", key, value)
+			}
+			*target = &parsed
+		}
+	}
+
+	numbers := map[string]func(int){
+		"min_duration": func(n int) { filter.MinDuration = int64(n) },
+		"max_duration": func(n int) { filter.MaxDuration = int64(n) },
+		"limit":        func(n int) { filter.Limit = n },
+		"offset":       func(n int) { filter.Offset = n },
+	}
+	for key, set := range numbers {
+		if value := c.Query(key); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s %q", key, value)
+			}
+			set(n)
+		}
+	}
+	return filter, nil
+}
+
+// GetProjectHistory returns a page of the executions of every request in a project
+func (h *Handler) GetProjectHistory(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	filter, err := historyFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	history, err := h.services.History.GetProjectHistory(projectID, filter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+func (h *Handler) GetHistoryConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, h.services.History.GetConfig())
+}
+
+// UpdateHistoryConfig changes how much history is kept and prunes it right away
+func (h *Handler) UpdateHistoryConfig(c *gin.Context) {
+	var config models.HistoryConfig
+	if err := c.ShouldBindJSON(&config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.services.History.SetConfig(config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, config)
+}
+
+// ReplayHistory sends the request of a history entry again exactly as it was sent
+func (h *Handler) ReplayHistory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid history ID"})
+		return
+	}
+
+	entry, err := h.services.History.Replay(id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// DiffHistory compares the responses of two history entries. Repeated ignore parameters leave
+// out more body paths.
+func (h *Handler) DiffHistory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid history ID"})
+		return
+	}
+	againstID, err := strconv.Atoi(c.Param("againstId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid history ID"})
+		return
+	}
+
+	diff, err := h.services.History.Diff(id, againstID, c.QueryArray("ignore"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// DiffHistoryLive replays a history entry and compares its response with the live one
+func (h *Handler) DiffHistoryLive(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid history ID"})
+		return
+	}
+
+	diff, err := h.services.History.DiffLive(id, c.QueryArray("ignore"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+func (h *Handler) GetResponseDiffConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, h.services.History.GetDiffConfig())
+}
+
+func (h *Handler) UpdateResponseDiffConfig(c *gin.Context) {
+	var config models.ResponseDiffConfig
+	if err := c.ShouldBindJSON(&config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.services.History.SetDiffConfig(config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.services.History.GetDiffConfig())
+}
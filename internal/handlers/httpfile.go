@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImportHTTPFile imports the requests of a .http/.rest file sent as the request body.
+// The optional folder_id and environment query parameters select the target folder and
+// the name of the environment created from the file variables.
+func (h *Handler) ImportHTTPFile(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	folderID, err := optionalIntQuery(c, "folder_id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder ID"})
+		return
+	}
+
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.services.HTTPFile.Import(projectID, folderID, c.Query("environment"), data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) ExportProjectHTTPFile(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	content, err := h.services.HTTPFile.ExportProject(projectID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	writeHTTPFile(c, content, "project-"+c.Param("id")+".http")
+}
+
+func (h *Handler) ExportFolderHTTPFile(c *gin.Context) {
+	folderID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder ID"})
+		return
+	}
+
+	content, err := h.services.HTTPFile.ExportFolder(folderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	writeHTTPFile(c, content, "folder-"+c.Param("id")+".http")
+}
+
+func writeHTTPFile(c *gin.Context, content string, filename string) {
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(content))
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"rikuest/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StartMockServer starts the mock server for a project
+func (h *Handler) StartMockServer(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	var config models.MockServerConfig
+	// Every field is optional, so the body may be empty
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&config); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	config.ProjectID = projectID
+	if err := h.services.Mock.Start(config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.services.Mock.Status())
+}
+
+func (h *Handler) StopMockServer(c *gin.Context) {
+	if err := h.services.Mock.Stop(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.services.Mock.Status())
+}
+
+func (h *Handler) GetMockServerStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, h.services.Mock.Status())
+}
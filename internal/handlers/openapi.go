@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"rikuest/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OpenAPIPayload struct {
+	Content string `json:"content" binding:"required"`
+	services.OpenAPIImportOptions
+}
+
+// PreviewOpenAPI lists the operations of an OpenAPI or Swagger document without importing them
+func (h *Handler) PreviewOpenAPI(c *gin.Context) {
+	var payload OpenAPIPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	preview, err := h.services.OpenAPI.Preview([]byte(payload.Content))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, preview)
+}
+
+// ImportOpenAPI imports the selected operations of an OpenAPI or Swagger document into a project
+func (h *Handler) ImportOpenAPI(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	var payload OpenAPIPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.services.OpenAPI.Import(projectID, []byte(payload.Content), payload.OpenAPIImportOptions)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+type OpenAPISyncPayload struct {
+	Content string `json:"content" binding:"required"`
+	services.OpenAPISyncOptions
+}
+
+// GetOpenAPISpecs lists the OpenAPI documents imported into a project
+func (h *Handler) GetOpenAPISpecs(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	specs, err := h.services.OpenAPI.GetSpecs(projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, specs)
+}
+
+// DeleteOpenAPISpec forgets an imported document, keeping its requests
+func (h *Handler) DeleteOpenAPISpec(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid spec ID"})
+		return
+	}
+
+	if err := h.services.OpenAPI.DeleteSpec(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "OpenAPI document deleted successfully"})
+}
+
+// DiffOpenAPISpec compares a new version of an imported document with its requests
+func (h *Handler) DiffOpenAPISpec(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid spec ID"})
+		return
+	}
+
+	var payload OpenAPISyncPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	diff, err := h.services.OpenAPI.Diff(id, []byte(payload.Content))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// SyncOpenAPISpec applies the selected changes of a new version of an imported document
+func (h *Handler) SyncOpenAPISpec(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid spec ID"})
+		return
+	}
+
+	var payload OpenAPISyncPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.services.OpenAPI.Sync(id, []byte(payload.Content), payload.OpenAPISyncOptions)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ExportProjectOpenAPI describes a project as an OpenAPI 3.1 document (?format=yaml|json)
+func (h *Handler) ExportProjectOpenAPI(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	format, ok := openAPIExportFormat(c)
+	if !ok {
+		return
+	}
+
+	content, err := h.services.OpenAPI.ExportProject(projectID, format)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	writeOpenAPIDocument(c, content, format, "project-"+c.Param("id"))
+}
+
+func (h *Handler) ExportFolderOpenAPI(c *gin.Context) {
+	folderID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder ID"})
+		return
+	}
+
+	format, ok := openAPIExportFormat(c)
+	if !ok {
+		return
+	}
+
+	content, err := h.services.OpenAPI.ExportFolder(folderID, format)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	writeOpenAPIDocument(c, content, format, "folder-"+c.Param("id"))
+}
+
+func openAPIExportFormat(c *gin.Context) (string, bool) {
+	format := c.DefaultQuery("format", "yaml")
+	if format != "yaml" && format != "json" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format must be yaml or json"})
+		return "", false
+	}
+	return format, true
+}
+
+func writeOpenAPIDocument(c *gin.Context, content string, format string, name string) {
+	contentType := "application/yaml"
+	if format == "json" {
+		contentType = "application/json"
+	}
+	c.Header("Content-Disposition", "attachment; filename="+name+".openapi."+format)
+	c.Data(http.StatusOK, contentType+"; charset=utf-8", []byte(content))
+}
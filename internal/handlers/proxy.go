@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"rikuest/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StartProxy starts the recording proxy, capturing traffic into a project
+func (h *Handler) StartProxy(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	var config models.ProxyConfig
+	if err := c.ShouldBindJSON(&config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	config.ProjectID = projectID
+	if err := h.services.Proxy.Start(config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.services.Proxy.Status())
+}
+
+func (h *Handler) StopProxy(c *gin.Context) {
+	if err := h.services.Proxy.Stop(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.services.Proxy.Status())
+}
+
+func (h *Handler) GetProxyStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, h.services.Proxy.Status())
+}
+
+// GetProxyCACertificate downloads the local CA certificate used for HTTPS interception
+func (h *Handler) GetProxyCACertificate(c *gin.Context) {
+	certPEM, err := h.services.Proxy.CACertificatePEM()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=rikuest-proxy-ca.pem")
+	c.Data(http.StatusOK, "application/x-pem-file", []byte(certPEM))
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxAuthorLength caps the user name a client reports
+const maxAuthorLength = 64
+
+// requestAuthor names who makes an edit by the address of the connection. The server has no
+// accounts, so the user a client reports in the X-Rikuest-User header is only a claim: it is
+// shown next to the address, such as "alice (192.0.2.10)", and never instead of it.
+func requestAuthor(c *gin.Context) string {
+	address := c.RemoteIP()
+	user := strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(c.GetHeader("X-Rikuest-User")))
+	if user == "" {
+		return address
+	}
+	if runes := []rune(user); len(runes) > maxAuthorLength {
+		user = string(runes[:maxAuthorLength])
+	}
+	return fmt.Sprintf("%s (%s)", user, address)
+}
+
+// GetRequestRevisions lists the earlier states of a request, newest first
+func (h *Handler) GetRequestRevisions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request ID"})
+		return
+	}
+
+	revisions, err := h.services.Request.GetRevisions(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+// DiffRequestRevisions compares two revisions of a request field by field. A missing or zero
+// from or to stands for the current state of the request.
+func (h *Handler) DiffRequestRevisions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request ID"})
+		return
+	}
+	from, err := strconv.Atoi(c.DefaultQuery("from", "0"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision ID"})
+		return
+	}
+	to, err := strconv.Atoi(c.DefaultQuery("to", "0"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision ID"})
+		return
+	}
+
+	changes, err := h.services.Request.DiffRevisions(id, from, to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, changes)
+}
+
+// RollbackRequest brings a request back to the state of one of its revisions
+func (h *Handler) RollbackRequest(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request ID"})
+		return
+	}
+	revisionID, err := strconv.Atoi(c.Param("revisionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision ID"})
+		return
+	}
+
+	request, err := h.services.Request.RollbackRequest(id, revisionID, requestAuthor(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, request)
+}
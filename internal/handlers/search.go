@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"rikuest/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Search finds requests, folders and, with responses=true, history responses matching q,
+// optionally within project_id
+func (h *Handler) Search(c *gin.Context) {
+	query := models.SearchQuery{
+		Query:     c.Query("q"),
+		Responses: c.Query("responses") == "true",
+	}
+	if value := c.Query("project_id"); value != "" {
+		projectID, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+			return
+		}
+		query.ProjectID = projectID
+	}
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		query.Limit = limit
+	}
+
+	results, err := h.services.Search.Search(query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
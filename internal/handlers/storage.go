@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StoragePayload struct {
+	Path string `json:"path" binding:"required"`
+}
+
+// GetProjectStorage returns the directory a project is stored in and when it was last synced
+func (h *Handler) GetProjectStorage(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	storage, err := h.services.Storage.GetStorage(projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if storage == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project is not stored in a directory"})
+		return
+	}
+
+	c.JSON(http.StatusOK, storage)
+}
+
+// SetProjectStorage stores a project as YAML files in a directory and keeps them in sync
+func (h *Handler) SetProjectStorage(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	var payload StoragePayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	storage, err := h.services.Storage.Link(projectID, payload.Path)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, storage)
+}
+
+// DeleteProjectStorage stops syncing a project with its directory; the files are kept
+func (h *Handler) DeleteProjectStorage(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	if err := h.services.Storage.Unlink(projectID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Project storage removed successfully"})
+}
+
+func (h *Handler) SyncProjectStorage(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	storage, err := h.services.Storage.Sync(projectID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, storage)
+}
+
+// OpenProjectDirectory creates a project from a directory of YAML files, such as a git checkout
+func (h *Handler) OpenProjectDirectory(c *gin.Context) {
+	var payload StoragePayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	project, err := h.services.Storage.OpenDirectory(payload.Path)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, project)
+}
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"rikuest/internal/models"
+	"rikuest/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestFilter reads a request filter from the query: tag (repeatable), method, url and
+// status. ok is false when none of them is set.
+func requestFilter(c *gin.Context) (models.RequestFilter, bool) {
+	filter := models.RequestFilter{
+		Tags:   c.QueryArray("tag"),
+		Method: c.Query("method"),
+		URL:    c.Query("url"),
+		Status: c.Query("status"),
+	}
+	ok := len(filter.Tags) > 0 || filter.Method != "" || filter.URL != "" || filter.Status != ""
+	return filter, ok
+}
+
+func (h *Handler) GetTags(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	tags, err := h.services.Tag.GetTags(projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+func (h *Handler) CreateTag(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	var tag models.Tag
+	if err := c.ShouldBindJSON(&tag); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tag.ProjectID = projectID
+	if err := h.services.Tag.CreateTag(&tag); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, tag)
+}
+
+func (h *Handler) UpdateTag(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tag ID"})
+		return
+	}
+
+	var tag models.Tag
+	if err := c.ShouldBindJSON(&tag); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tag.ID = id
+	updated, err := h.services.Tag.UpdateTag(&tag)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+func (h *Handler) DeleteTag(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tag ID"})
+		return
+	}
+
+	if err := h.services.Tag.DeleteTag(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tag deleted successfully"})
+}
+
+func (h *Handler) GetSavedFilters(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	filters, err := h.services.Tag.GetSavedFilters(projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, filters)
+}
+
+func (h *Handler) CreateSavedFilter(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	var filter models.SavedFilter
+	if err := c.ShouldBindJSON(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter.ProjectID = projectID
+	if err := h.services.Tag.CreateSavedFilter(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, filter)
+}
+
+func (h *Handler) UpdateSavedFilter(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filter ID"})
+		return
+	}
+
+	var filter models.SavedFilter
+	if err := c.ShouldBindJSON(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter.ID = id
+	updated, err := h.services.Tag.UpdateSavedFilter(&filter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+func (h *Handler) DeleteSavedFilter(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filter ID"})
+		return
+	}
+
+	if err := h.services.Tag.DeleteSavedFilter(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Filter deleted successfully"})
+}
+
+// GetSavedFilterRequests lists the requests a saved filter currently selects
+func (h *Handler) GetSavedFilterRequests(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filter ID"})
+		return
+	}
+
+	requests, err := h.services.Tag.FindSavedFilterRequests(id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, requests)
+}
+
+// RunSavedFilter executes the requests a saved filter selects, one after another. With a report
+// query parameter the result is returned as a report in that format.
+func (h *Handler) RunSavedFilter(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filter ID"})
+		return
+	}
+	format := c.Query("report")
+	if format != "" && services.RunReportContentType(format) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid report format, expected one of " + strings.Join(services.RunReportFormats, ", ")})
+		return
+	}
+
+	result, err := h.services.Runner.RunSavedFilter(id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if format != "" {
+		var report bytes.Buffer
+		if err := services.WriteRunReport(&report, result, format); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Data(http.StatusOK, services.RunReportContentType(format), report.Bytes())
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
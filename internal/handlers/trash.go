@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"rikuest/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetProjectTrash lists the folders and requests deleted from a project
+func (h *Handler) GetProjectTrash(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	items, err := h.services.Trash.GetTrash(projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+func (h *Handler) GetTrashedProjects(c *gin.Context) {
+	items, err := h.services.Trash.GetTrashedProjects()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// RestoreTrashItem puts a deleted project, folder or request back where it was
+func (h *Handler) RestoreTrashItem(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid trash item ID"})
+		return
+	}
+
+	if err := h.services.Trash.Restore(id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Item restored successfully"})
+}
+
+// PurgeTrashItem permanently deletes an item from the trash
+func (h *Handler) PurgeTrashItem(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid trash item ID"})
+		return
+	}
+
+	if err := h.services.Trash.Purge(id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Item deleted permanently"})
+}
+
+func (h *Handler) GetTrashConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, h.services.Trash.GetConfig())
+}
+
+func (h *Handler) UpdateTrashConfig(c *gin.Context) {
+	var config models.TrashConfig
+	if err := c.ShouldBindJSON(&config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.services.Trash.SetConfig(config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, config)
+}
@@ -18,9 +18,12 @@ type QueryParam struct {
 	Enabled bool   `json:"enabled"`
 }
 
+// FormData is a form field. Type is "text" (the default when empty) or "file", in which case
+// Value holds the path of a local file sent with multipart bodies.
 type FormData struct {
 	Key   string `json:"key"`
 	Value string `json:"value"`
+	Type  string `json:"type,omitempty"`
 }
 
 type BasicAuth struct {
@@ -28,35 +31,56 @@ type BasicAuth struct {
 	Password string `json:"password"`
 }
 
+type Variable struct {
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+	Enabled bool   `json:"enabled"`
+}
+
+type Environment struct {
+	ID        int        `json:"id" db:"id"`
+	ProjectID int        `json:"project_id" db:"project_id"`
+	Name      string     `json:"name" db:"name"`
+	Variables []Variable `json:"variables" db:"variables"`
+	IsActive  bool       `json:"is_active" db:"is_active"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Folder groups requests. Description holds Markdown notes, as on requests and projects.
 type Folder struct {
-	ID        int       `json:"id" db:"id"`
-	ProjectID int       `json:"project_id" db:"project_id"`
-	Name      string    `json:"name" db:"name"`
-	ParentID  *int      `json:"parent_id" db:"parent_id"`
-	Position  int       `json:"position" db:"position"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID          int       `json:"id" db:"id"`
+	ProjectID   int       `json:"project_id" db:"project_id"`
+	Name        string    `json:"name" db:"name"`
+	Description string    `json:"description" db:"description"`
+	ParentID    *int      `json:"parent_id" db:"parent_id"`
+	Position    int       `json:"position" db:"position"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
 }
 
 type Request struct {
-	ID          int               `json:"id" db:"id"`
-	ProjectID   int               `json:"project_id" db:"project_id"`
-	FolderID    *int              `json:"folder_id" db:"folder_id"`
-	Name        string            `json:"name" db:"name"`
-	Method      string            `json:"method" db:"method"`
-	URL         string            `json:"url" db:"url"`
-	Headers     map[string]string `json:"headers" db:"headers"`
-	Body        string            `json:"body" db:"body"`
-	QueryParams []QueryParam      `json:"query_params" db:"query_params"`
-	AuthType    string            `json:"auth_type" db:"auth_type"`
-	BearerToken string            `json:"bearer_token" db:"bearer_token"`
-	BasicAuth   BasicAuth         `json:"basic_auth" db:"basic_auth"`
-	BodyType    string            `json:"body_type" db:"body_type"`
-	FormData    []FormData        `json:"form_data" db:"form_data"`
-	Position    int               `json:"position" db:"position"`
-	Response    *RequestResponse  `json:"response,omitempty"`
-	CreatedAt   time.Time         `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time         `json:"updated_at" db:"updated_at"`
+	ID             int               `json:"id" db:"id"`
+	ProjectID      int               `json:"project_id" db:"project_id"`
+	FolderID       *int              `json:"folder_id" db:"folder_id"`
+	Name           string            `json:"name" db:"name"`
+	Description    string            `json:"description" db:"description"`
+	Method         string            `json:"method" db:"method"`
+	URL            string            `json:"url" db:"url"`
+	Headers        map[string]string `json:"headers" db:"headers"`
+	Body           string            `json:"body" db:"body"`
+	QueryParams    []QueryParam      `json:"query_params" db:"query_params"`
+	AuthType       string            `json:"auth_type" db:"auth_type"`
+	BearerToken    string            `json:"bearer_token" db:"bearer_token"`
+	BasicAuth      BasicAuth         `json:"basic_auth" db:"basic_auth"`
+	BodyType       string            `json:"body_type" db:"body_type"`
+	FormData       []FormData        `json:"form_data" db:"form_data"`
+	ExpectedStatus int               `json:"expected_status" db:"expected_status"`
+	Position       int               `json:"position" db:"position"`
+	Tags           []string          `json:"tags"`
+	Response       *RequestResponse  `json:"response,omitempty"`
+	CreatedAt      time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at" db:"updated_at"`
 }
 
 type RequestResponse struct {
@@ -69,13 +93,302 @@ type RequestResponse struct {
 	RawRequest string            `json:"raw_request"`
 }
 
+// RequestHistory is one execution of a request. Request is the request as it was sent, with
+// environment variables substituted; entries recorded before snapshots were kept have none.
 type RequestHistory struct {
 	ID         int             `json:"id" db:"id"`
 	RequestID  int             `json:"request_id" db:"request_id"`
+	Request    *Request        `json:"request,omitempty" db:"request"`
 	Response   RequestResponse `json:"response" db:"response"`
 	ExecutedAt time.Time       `json:"executed_at" db:"executed_at"`
 }
 
+// HistoryFilter narrows and pages a history listing. Status is a code such as "404" or a class
+// such as "4xx", durations are in milliseconds, and zero values leave a filter off.
+type HistoryFilter struct {
+	Status      string     `json:"status"`
+	From        *time.Time `json:"from"`
+	To          *time.Time `json:"to"`
+	MinDuration int64      `json:"min_duration"`
+	MaxDuration int64      `json:"max_duration"`
+	Limit       int        `json:"limit"`
+	Offset      int        `json:"offset"`
+}
+
+// HistoryPage is one page of history entries, newest first. Total counts every entry that
+// matches the filter.
+type HistoryPage struct {
+	Items  []RequestHistory `json:"items"`
+	Total  int              `json:"total"`
+	Limit  int              `json:"limit"`
+	Offset int              `json:"offset"`
+}
+
+// HistoryConfig limits how much history is kept: the newest MaxEntries executions of each
+// request, executions from the last MaxAgeDays days, and MaxSizeMB of history in total. Zero
+// keeps everything.
+type HistoryConfig struct {
+	MaxEntries int `json:"max_entries"`
+	MaxAgeDays int `json:"max_age_days"`
+	MaxSizeMB  int `json:"max_size_mb"`
+}
+
+type ResponseExample struct {
+	ID        int               `json:"id" db:"id"`
+	RequestID int               `json:"request_id" db:"request_id"`
+	Name      string            `json:"name" db:"name"`
+	Status    int               `json:"status" db:"status"`
+	Headers   map[string]string `json:"headers" db:"headers"`
+	Body      string            `json:"body" db:"body"`
+	CreatedAt time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+type MockServerConfig struct {
+	ProjectID   int     `json:"project_id"`
+	Address     string  `json:"address"`
+	DelayMs     int     `json:"delay_ms"`
+	ErrorRate   float64 `json:"error_rate"`
+	ErrorStatus int     `json:"error_status"`
+}
+
+type MockServerStatus struct {
+	Running bool             `json:"running"`
+	Config  MockServerConfig `json:"config"`
+}
+
+type ProxyConfig struct {
+	ProjectID      int    `json:"project_id"`
+	FolderID       *int   `json:"folder_id"`
+	Address        string `json:"address"`
+	InterceptHTTPS bool   `json:"intercept_https"`
+}
+
+type ProxyStatus struct {
+	Running  bool        `json:"running"`
+	Config   ProxyConfig `json:"config"`
+	Captured int         `json:"captured"`
+}
+
+// ImportResult summarizes what an importer created and what it could not convert
+type ImportResult struct {
+	ProjectID    int      `json:"project_id"`
+	Folders      int      `json:"folders"`
+	Requests     int      `json:"requests"`
+	History      int      `json:"history"`
+	Examples     int      `json:"examples"`
+	Environments int      `json:"environments"`
+	Warnings     []string `json:"warnings"`
+	SpecID       int      `json:"spec_id,omitempty"`
+}
+
+// ProjectStorage links a project to a directory where it is kept as YAML files. LastSyncedAt
+// and Error describe the last synchronization since the app started.
+type ProjectStorage struct {
+	ProjectID    int        `json:"project_id" db:"project_id"`
+	Path         string     `json:"path" db:"path"`
+	LastSyncedAt *time.Time `json:"last_synced_at" db:"-"`
+	Error        string     `json:"error,omitempty" db:"-"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+// StorageEntry maps a file or directory of a stored project to the request or folder it holds
+type StorageEntry struct {
+	ProjectID int    `json:"project_id" db:"project_id"`
+	Path      string `json:"path" db:"path"`
+	Kind      string `json:"kind" db:"kind"`
+	ItemID    int    `json:"item_id" db:"item_id"`
+}
+
+// Backup is a snapshot of the database. Reason is what created it: "startup", "scheduled",
+// "manual" or "pre-restore".
+type Backup struct {
+	Name      string    `json:"name"`
+	Reason    string    `json:"reason"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BackupConfig controls automatic backups. An IntervalHours of 0 disables them; Retention is
+// the number of backups kept.
+type BackupConfig struct {
+	IntervalHours int `json:"interval_hours"`
+	Retention     int `json:"retention"`
+}
+
+// MigrationStatus is a schema migration and when it was applied, or nil while it is pending
+type MigrationStatus struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at"`
+}
+
+// TrashItem is a deleted project, folder or request. Folders are trashed with their subfolders
+// and requests, which are restored or purged with them.
+type TrashItem struct {
+	ID        int       `json:"id" db:"id"`
+	ProjectID int       `json:"project_id" db:"project_id"`
+	Kind      string    `json:"kind" db:"kind"`
+	ItemID    int       `json:"item_id" db:"item_id"`
+	Name      string    `json:"name" db:"name"`
+	Requests  int       `json:"requests" db:"-"`
+	DeletedAt time.Time `json:"deleted_at" db:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at" db:"-"`
+}
+
+// TrashConfig sets how many days deleted items stay in the trash before they are purged
+type TrashConfig struct {
+	RetentionDays int `json:"retention_days"`
+}
+
+// Tag labels requests of a project across folders. Requests refer to tags by name, and a tag
+// used by a request for the first time is created with the default color.
+type Tag struct {
+	ID        int       `json:"id"`
+	ProjectID int       `json:"project_id"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	Requests  int       `json:"requests"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RequestFilter selects requests of a project. Requests must carry every tag in Tags. URL is
+// a pattern where * matches anything, and Status is the status of the latest run, a code such
+// as "404", a class such as "4xx" or "none" for requests never run. Empty fields match all.
+type RequestFilter struct {
+	Tags   []string `json:"tags"`
+	Method string   `json:"method"`
+	URL    string   `json:"url"`
+	Status string   `json:"status"`
+}
+
+// SavedFilter is a named request filter, kept to list or run the same requests again
+type SavedFilter struct {
+	ID        int           `json:"id"`
+	ProjectID int           `json:"project_id"`
+	Name      string        `json:"name"`
+	Filter    RequestFilter `json:"filter"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// RunResult is the outcome of running a set of requests one after another
+type RunResult struct {
+	Name      string          `json:"name"`
+	StartedAt time.Time       `json:"started_at"`
+	Duration  int64           `json:"duration"`
+	Passed    int             `json:"passed"`
+	Failed    int             `json:"failed"`
+	Results   []RunItemResult `json:"results"`
+}
+
+// RunItemResult is the outcome of one request of a run. A request passes when it gets a
+// response with its expected status, or with a status below 400 when it expects none. Folder is the path of its folder from the root, and
+// Duration the time its execution took in milliseconds, even when it got no response.
+type RunItemResult struct {
+	RequestID int              `json:"request_id"`
+	Name      string           `json:"name"`
+	Folder    string           `json:"folder,omitempty"`
+	Method    string           `json:"method"`
+	URL       string           `json:"url"`
+	Passed    bool             `json:"passed"`
+	Duration  int64            `json:"duration"`
+	Error     string           `json:"error,omitempty"`
+	Response  *RequestResponse `json:"response,omitempty"`
+}
+
+// RunOptions selects what a project run executes. A nil FolderID runs the whole project and a nil
+// EnvironmentID the active environment. Variables are added to the environment, replacing its
+// variables of the same name.
+type RunOptions struct {
+	FolderID      *int       `json:"folder_id,omitempty"`
+	EnvironmentID *int       `json:"environment_id,omitempty"`
+	Variables     []Variable `json:"variables,omitempty"`
+}
+
+// CopyTarget is where a copied or moved item goes. A nil FolderID is the root of the project, a
+// nil Position the end of the folder, and an empty Name keeps the name of the item.
+type CopyTarget struct {
+	ProjectID int    `json:"project_id"`
+	FolderID  *int   `json:"folder_id"`
+	Position  *int   `json:"position"`
+	Name      string `json:"name"`
+}
+
+// SearchQuery finds requests and folders by name, URL, headers or body, and history responses
+// by body when Responses is set. ProjectID 0 searches every project.
+type SearchQuery struct {
+	Query     string `json:"query"`
+	ProjectID int    `json:"project_id"`
+	Responses bool   `json:"responses"`
+	Limit     int    `json:"limit"`
+}
+
+// SearchResult is a request, folder or history response matching a search. ID is the ID of
+// the request, folder or history entry, and responses also carry the ID of their request.
+// FolderID is the folder holding the item, Path the names of the folders down to it from the
+// root, and Snippet the text around the first match in Field.
+type SearchResult struct {
+	Kind      string    `json:"kind"`
+	ID        int       `json:"id"`
+	ProjectID int       `json:"project_id"`
+	FolderID  *int      `json:"folder_id"`
+	RequestID int       `json:"request_id,omitempty"`
+	Name      string    `json:"name"`
+	Method    string    `json:"method,omitempty"`
+	URL       string    `json:"url,omitempty"`
+	Path      []string  `json:"path"`
+	Field     string    `json:"field"`
+	Snippet   string    `json:"snippet"`
+	Status    int       `json:"status,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+// ResponseDiffConfig sets what comparing two responses leaves out: headers by name, such as
+// Date, and JSON body paths such as $.meta.timestamp, $.items[*].id or $..updated_at
+type ResponseDiffConfig struct {
+	IgnoreHeaders []string `json:"ignore_headers"`
+	IgnorePaths   []string `json:"ignore_paths"`
+}
+
+// RequestRevision is the state of a request before an edit. Author is who made the edit, as
+// reported by the client in server mode, and is empty for edits made in the desktop app.
+type RequestRevision struct {
+	ID        int       `json:"id" db:"id"`
+	RequestID int       `json:"request_id" db:"request_id"`
+	Request   Request   `json:"request" db:"snapshot"`
+	Author    string    `json:"author" db:"author"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// OpenAPISpec is an imported OpenAPI document, kept so that a newer version of it can be
+// compared with the requests it created. FolderID, BaseURL and CreateFolders are the options
+// it was imported with and are reused for operations added later.
+type OpenAPISpec struct {
+	ID            int       `json:"id" db:"id"`
+	ProjectID     int       `json:"project_id" db:"project_id"`
+	Title         string    `json:"title" db:"title"`
+	Version       string    `json:"version" db:"version"`
+	Content       string    `json:"content,omitempty" db:"content"`
+	FolderID      *int      `json:"folder_id" db:"folder_id"`
+	BaseURL       string    `json:"base_url" db:"base_url"`
+	CreateFolders bool      `json:"create_folders" db:"create_folders"`
+	Operations    int       `json:"operations" db:"-"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// OpenAPIOperationLink ties a request to the operation it was imported from. Snapshot is the
+// request as generated by the last import or sync, which tells local edits from spec changes.
+type OpenAPIOperationLink struct {
+	RequestID   int     `json:"request_id" db:"request_id"`
+	SpecID      int     `json:"spec_id" db:"spec_id"`
+	OperationID string  `json:"operation_id" db:"operation_id"`
+	Method      string  `json:"method" db:"method"`
+	Path        string  `json:"path" db:"path"`
+	Snapshot    Request `json:"snapshot" db:"snapshot"`
+}
+
 type CopyRequestResponse struct {
 	Format  string `json:"format"`
 	Content string `json:"content"`
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"rikuest/internal/database"
+	"rikuest/internal/models"
+)
+
+const backupCheckEvery = time.Minute
+
+// BackupService snapshots the database into a backups directory next to it, when the app
+// starts and then every configured interval, and restores those snapshots on demand
+type BackupService struct {
+	db    *database.DB
+	dir   string
+	mutex sync.Mutex
+	stop  chan struct{}
+}
+
+func NewBackupService(db *database.DB) *BackupService {
+	dir := ""
+	if db.Path() != "" {
+		dir = database.BackupDir(db.Path())
+	}
+	return &BackupService{db: db, dir: dir}
+}
+
+// Start backs up the database when the app starts and then whenever the configured interval
+// has passed since the last backup, until Stop is called. Restarts within the interval take no
+// backup, so that they do not push older backups out of the retention.
+func (s *BackupService) Start() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.stop != nil || s.dir == "" {
+		return
+	}
+	stop := make(chan struct{})
+	s.stop = stop
+
+	go func() {
+		s.backupIfEnabled("startup", time.Duration(s.GetConfig().IntervalHours)*time.Hour)
+
+		ticker := time.NewTicker(backupCheckEvery)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				config := s.GetConfig()
+				s.backupIfEnabled("scheduled", time.Duration(config.IntervalHours)*time.Hour)
+			}
+		}
+	}()
+}
+
+func (s *BackupService) Stop() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+}
+
+// backupIfEnabled creates a backup unless automatic backups are disabled or the last one is
+// more recent than interval
+func (s *BackupService) backupIfEnabled(reason string, interval time.Duration) {
+	if s.GetConfig().IntervalHours == 0 {
+		return
+	}
+	if interval > 0 {
+		backups, err := s.GetBackups()
+		if err == nil && len(backups) > 0 && time.Since(backups[0].CreatedAt) < interval {
+			return
+		}
+	}
+	if _, err := s.CreateBackup(reason); err != nil {
+		log.Printf("Failed to back up database: %v", err)
+	}
+}
+
+// GetConfig returns the backup settings, falling back to daily backups keeping the last 10
+func (s *BackupService) GetConfig() models.BackupConfig {
+	config := models.BackupConfig{IntervalHours: 24, Retention: 10}
+	if value, err := s.db.GetSetting("backup_interval_hours"); err == nil && value != "" {
+		if hours, err := strconv.Atoi(value); err == nil && hours >= 0 {
+			config.IntervalHours = hours
+		}
+	}
+	if value, err := s.db.GetSetting("backup_retention"); err == nil && value != "" {
+		if retention, err := strconv.Atoi(value); err == nil && retention > 0 {
+			config.Retention = retention
+		}
+	}
+	return config
+}
+
+// SetConfig saves the backup settings. The new retention applies from the next backup.
+func (s *BackupService) SetConfig(config models.BackupConfig) error {
+	if config.IntervalHours < 0 {
+		return fmt.Errorf("backup interval cannot be negative")
+	}
+	if config.Retention < 1 {
+		return fmt.Errorf("at least one backup must be kept")
+	}
+	if err := s.db.SetSetting("backup_interval_hours", strconv.Itoa(config.IntervalHours)); err != nil {
+		return err
+	}
+	return s.db.SetSetting("backup_retention", strconv.Itoa(config.Retention))
+}
+
+// GetBackups lists the backups, newest first
+func (s *BackupService) GetBackups() ([]models.Backup, error) {
+	backups := []models.Backup{}
+	if s.dir == "" {
+		return backups, nil
+	}
+
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return backups, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		backup, ok := parseBackupName(entry.Name())
+		if !ok || entry.IsDir() {
+			continue
+		}
+		if info, err := entry.Info(); err == nil {
+			backup.Size = info.Size()
+		}
+		backups = append(backups, backup)
+	}
+
+	sort.Slice(backups, func(i, j int) bool { return backups[i].CreatedAt.After(backups[j].CreatedAt) })
+	return backups, nil
+}
+
+// CreateBackup snapshots the database and removes the oldest backups beyond the retention.
+// Reason is recorded in the file name.
+func (s *BackupService) CreateBackup(reason string) (*models.Backup, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	backup, err := s.createBackup(reason)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.prune(""); err != nil {
+		log.Printf("Failed to remove old backups: %v", err)
+	}
+	return backup, nil
+}
+
+func (s *BackupService) createBackup(reason string) (*models.Backup, error) {
+	if s.dir == "" {
+		return nil, fmt.Errorf("in-memory databases cannot be backed up")
+	}
+	name, createdAt, err := s.db.BackupInto(s.dir, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	backup := models.Backup{Name: name, Reason: reason, CreatedAt: createdAt}
+	if info, err := os.Stat(filepath.Join(s.dir, name)); err == nil {
+		backup.Size = info.Size()
+	}
+	return &backup, nil
+}
+
+// prune removes the oldest backups beyond the retention, except keep, which neither is removed
+// nor counts toward the retention
+func (s *BackupService) prune(keep string) error {
+	backups, err := s.GetBackups()
+	if err != nil {
+		return err
+	}
+	retention := s.GetConfig().Retention
+	kept := 0
+	for _, backup := range backups {
+		if backup.Name == keep {
+			continue
+		}
+		if kept++; kept <= retention {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.dir, backup.Name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RestoreBackup replaces the database with a backup. The current database is backed up first,
+// so a restore can itself be undone, and the restored backup is kept even when it is the oldest.
+func (s *BackupService) RestoreBackup(name string) (*models.Backup, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	backup, ok := parseBackupName(name)
+	if !ok || filepath.Base(name) != name || s.dir == "" {
+		return nil, fmt.Errorf("invalid backup name %q", name)
+	}
+	source := filepath.Join(s.dir, name)
+	if _, err := os.Stat(source); err != nil {
+		return nil, fmt.Errorf("backup not found: %s", name)
+	}
+
+	if _, err := s.createBackup("pre-restore"); err != nil {
+		return nil, fmt.Errorf("failed to back up the current database before restoring: %w", err)
+	}
+	if err := s.db.RestoreFrom(source); err != nil {
+		return nil, err
+	}
+	if err := s.prune(name); err != nil {
+		log.Printf("Failed to remove old backups: %v", err)
+	}
+	return &backup, nil
+}
+
+// parseBackupName reads the time and reason from a name such as
+// rikuest-20240131-093000.000-startup.db
+func parseBackupName(name string) (models.Backup, bool) {
+	createdAt, reason, ok := database.ParseBackupName(name)
+	if !ok {
+		return models.Backup{}, false
+	}
+	return models.Backup{Name: name, Reason: reason, CreatedAt: createdAt}, true
+}
@@ -0,0 +1,454 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"rikuest/internal/models"
+)
+
+// curlValueFlags are the curl options that take an argument. Options not listed here are
+// treated as switches and ignored unless handled explicitly.
+var curlValueFlags = map[string]bool{
+	"-X": true, "--request": true,
+	"-H": true, "--header": true,
+	"-d": true, "--data": true, "--data-raw": true, "--data-binary": true, "--data-ascii": true, "--data-urlencode": true, "--json": true,
+	"-F": true, "--form": true, "--form-string": true,
+	"-u": true, "--user": true,
+	"-b": true, "--cookie": true,
+	"-A": true, "--user-agent": true,
+	"-e": true, "--referer": true,
+	"--url": true,
+	"-o":    true, "--output": true,
+	"-m": true, "--max-time": true, "--connect-timeout": true,
+	"-x": true, "--proxy": true, "-U": true, "--proxy-user": true,
+	"-w": true, "--write-out": true,
+	"-c": true, "--cookie-jar": true,
+	"-E": true, "--cert": true, "--key": true, "--cacert": true, "--capath": true,
+	"-T": true, "--upload-file": true,
+	"-r": true, "--range": true,
+	"--retry": true, "--resolve": true, "--connect-to": true, "--limit-rate": true, "--max-redirs": true,
+}
+
+// ParseCurlRequest turns a curl command line into a request. It is the inverse of
+// BuildCurlRequest and also accepts commands copied from browser devtools.
+func (fs *FormatService) ParseCurlRequest(command string) (*models.Request, error) {
+	return parseCurlCommand(command)
+}
+
+func parseCurlCommand(command string) (*models.Request, error) {
+	args, err := splitShellWords(command)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 || args[0] != "curl" {
+		return nil, fmt.Errorf("not a curl command")
+	}
+
+	request := &models.Request{
+		Headers:     make(map[string]string),
+		QueryParams: []models.QueryParam{},
+		AuthType:    "none",
+		BodyType:    "none",
+		FormData:    []models.FormData{},
+	}
+
+	var (
+		method    string
+		rawURL    string
+		data      []string
+		rawData   bool
+		isJSON    bool
+		useGet    bool
+		useHead   bool
+		formParts []models.FormData
+	)
+
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			if rawURL == "" {
+				rawURL = arg
+			}
+			continue
+		}
+
+		flag, value, hasValue := arg, "", false
+		if name, inline, found := strings.Cut(arg, "="); found && strings.HasPrefix(arg, "--") && curlValueFlags[name] {
+			// --header=value is accepted as well as --header value
+			flag, value, hasValue = name, inline, true
+		} else if !strings.HasPrefix(arg, "--") && len(arg) > 2 {
+			// Short options may be combined (-sSL) or carry their value (-XPOST)
+			flag = ""
+			for j := 1; j < len(arg); j++ {
+				short := "-" + string(arg[j])
+				if curlValueFlags[short] {
+					flag = short
+					if j+1 < len(arg) {
+						value, hasValue = arg[j+1:], true
+					}
+					break
+				}
+				switch short {
+				case "-G":
+					useGet = true
+				case "-I":
+					useHead = true
+				}
+			}
+			if flag == "" {
+				continue
+			}
+		}
+
+		if curlValueFlags[flag] && !hasValue {
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("option %s requires a value", flag)
+			}
+			i++
+			value = args[i]
+		}
+
+		switch flag {
+		case "-X", "--request":
+			method = strings.ToUpper(value)
+		case "-H", "--header":
+			name, headerValue, found := strings.Cut(value, ":")
+			if !found {
+				// curl sends "Name;" as an empty header
+				name = strings.TrimSuffix(name, ";")
+			}
+			name = strings.TrimSpace(name)
+			if name != "" {
+				request.Headers[name] = strings.TrimSpace(headerValue)
+			}
+		case "-d", "--data", "--data-ascii", "--data-binary", "--data-raw":
+			if strings.HasPrefix(value, "@") && flag != "--data-raw" {
+				return nil, fmt.Errorf("reading request data from a file (%s) is not supported", value)
+			}
+			if flag != "--data-binary" && flag != "--data-raw" {
+				value = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+			} else {
+				rawData = true
+			}
+			data = append(data, value)
+		case "--json":
+			data = append(data, value)
+			isJSON = true
+		case "--data-urlencode":
+			encoded, err := curlURLEncode(value)
+			if err != nil {
+				return nil, err
+			}
+			data = append(data, encoded)
+		case "-F", "--form", "--form-string":
+			name, formValue, _ := strings.Cut(value, "=")
+			if flag != "--form-string" && strings.HasPrefix(formValue, "@") {
+				// The path is a file of the machine the command comes from, which is not read
+				// here: the field is kept empty until a file is uploaded
+				formParts = append(formParts, models.FormData{Key: name, Type: "file"})
+				continue
+			}
+			if flag != "--form-string" && strings.HasPrefix(formValue, "<") {
+				return nil, fmt.Errorf("reading form field %q from a file is not supported", name)
+			}
+			formParts = append(formParts, models.FormData{Key: name, Value: formValue})
+		case "-u", "--user":
+			username, password, _ := strings.Cut(value, ":")
+			request.AuthType = "basic"
+			request.BasicAuth = models.BasicAuth{Username: username, Password: password}
+		case "-b", "--cookie":
+			// Without "=" the value names a cookie file, which cannot be read here
+			if strings.Contains(value, "=") {
+				if existing := request.Headers["Cookie"]; existing != "" {
+					value = existing + "; " + value
+				}
+				request.Headers["Cookie"] = value
+			}
+		case "-A", "--user-agent":
+			request.Headers["User-Agent"] = value
+		case "-e", "--referer":
+			request.Headers["Referer"] = value
+		case "--url":
+			rawURL = value
+		case "-G", "--get":
+			useGet = true
+		case "-I", "--head":
+			useHead = true
+		case "--compressed":
+			// Requests are sent with Accept-Encoding: gzip and decoded already, so there is
+			// nothing to keep
+		}
+	}
+
+	if rawURL == "" {
+		return nil, fmt.Errorf("the curl command has no URL")
+	}
+
+	body := strings.Join(data, "&")
+	if useGet && len(data) > 0 {
+		// -G appends the data to the query string instead of sending it
+		separator := "?"
+		if strings.Contains(rawURL, "?") {
+			separator = "&"
+		}
+		rawURL += separator + body
+		body = ""
+	}
+
+	base, rawQuery, hasQuery := strings.Cut(rawURL, "?")
+	request.URL = base
+	if hasQuery {
+		request.QueryParams = parseRawQuery(rawQuery)
+	}
+
+	switch {
+	case method != "":
+		request.Method = method
+	case useHead:
+		request.Method = "HEAD"
+	case body != "" || len(formParts) > 0:
+		request.Method = "POST"
+	default:
+		request.Method = "GET"
+	}
+
+	applyCurlAuthorization(request)
+
+	contentType := strings.ToLower(headerValue(request.Headers, "Content-Type"))
+	switch {
+	case len(formParts) > 0:
+		request.BodyType = "multipart"
+		request.FormData = formParts
+	case body == "":
+	case isJSON:
+		request.BodyType = "json"
+		request.Body = body
+		if contentType == "" {
+			request.Headers["Content-Type"] = "application/json"
+		}
+	case strings.Contains(contentType, "json"):
+		request.BodyType = "json"
+		request.Body = body
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		request.BodyType = "form"
+		request.FormData = curlFormData(body)
+	case contentType == "" && json.Valid([]byte(body)):
+		request.BodyType = "json"
+		request.Body = body
+	case contentType == "" && !rawData && isCurlFormBody(body):
+		// curl sends -d data as application/x-www-form-urlencoded by default. Bodies given
+		// with --data-raw or --data-binary are kept as text, which is how BuildCurlRequest
+		// writes them.
+		request.BodyType = "form"
+		request.FormData = curlFormData(body)
+	default:
+		request.BodyType = "text"
+		request.Body = body
+	}
+
+	request.Name = requestNameFromURL(request)
+	return request, nil
+}
+
+// applyCurlAuthorization moves Bearer and Basic Authorization headers into the request's auth settings
+func applyCurlAuthorization(request *models.Request) {
+	for name, value := range request.Headers {
+		if strings.EqualFold(name, "Authorization") && applyAuthorizationHeader(request, value) {
+			delete(request.Headers, name)
+		}
+	}
+}
+
+// curlURLEncode applies curl's --data-urlencode rules: "content", "=content" and "name=content"
+// have their content encoded, while the "@file" forms are rejected
+func curlURLEncode(value string) (string, error) {
+	name, content, found := strings.Cut(value, "=")
+	if !found {
+		if strings.Contains(value, "@") {
+			return "", fmt.Errorf("reading request data from a file (%s) is not supported", value)
+		}
+		return url.QueryEscape(value), nil
+	}
+	if name == "" {
+		return url.QueryEscape(content), nil
+	}
+	return name + "=" + url.QueryEscape(content), nil
+}
+
+// isCurlFormBody reports whether every &-separated part of a body is a key=value pair
+func isCurlFormBody(body string) bool {
+	for _, pair := range strings.Split(body, "&") {
+		key, _, found := strings.Cut(pair, "=")
+		if !found || key == "" || strings.ContainsAny(key, " \t\r\n") {
+			return false
+		}
+	}
+	return true
+}
+
+func curlFormData(body string) []models.FormData {
+	formData := []models.FormData{}
+	for _, pair := range strings.Split(body, "&") {
+		if pair == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(pair, "=")
+		if decoded, err := url.QueryUnescape(key); err == nil {
+			key = decoded
+		}
+		if decoded, err := url.QueryUnescape(value); err == nil {
+			value = decoded
+		}
+		formData = append(formData, models.FormData{Key: key, Value: value})
+	}
+	return formData
+}
+
+// requestNameFromURL derives a name such as "GET /users" from the request URL
+func requestNameFromURL(request *models.Request) string {
+	path := request.URL
+	if parsed, err := url.Parse(request.URL); err == nil && parsed.Host != "" {
+		path = parsed.Path
+		if path == "" {
+			path = parsed.Host
+		}
+	}
+	return request.Method + " " + path
+}
+
+// splitShellWords splits a command line the way a POSIX shell would, supporting single,
+// double and $'...' quoting, backslash escapes and line continuations
+func splitShellWords(command string) ([]string, error) {
+	var (
+		words   []string
+		current strings.Builder
+		inWord  bool
+	)
+
+	runes := []rune(strings.ReplaceAll(command, "\r\n", "\n"))
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case r == '\\':
+			if i+1 < len(runes) {
+				i++
+				if runes[i] != '\n' {
+					current.WriteRune(runes[i])
+					inWord = true
+				}
+			}
+		case r == '\'':
+			end := i + 1
+			for end < len(runes) && runes[end] != '\'' {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("unterminated single quote")
+			}
+			current.WriteString(string(runes[i+1 : end]))
+			inWord = true
+			i = end
+		case r == '$' && i+1 < len(runes) && runes[i+1] == '\'':
+			value, end, err := readANSIQuoted(runes, i+2)
+			if err != nil {
+				return nil, err
+			}
+			current.WriteString(value)
+			inWord = true
+			i = end
+		case r == '"':
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("\"\\$`\n", runes[i+1]) {
+					i++
+					if runes[i] == '\n' {
+						continue
+					}
+				}
+				current.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated double quote")
+			}
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, current.String())
+	}
+
+	return words, nil
+}
+
+// readANSIQuoted decodes a $'...' string starting after the opening quote and returns the
+// value and the index of the closing quote
+func readANSIQuoted(runes []rune, start int) (string, int, error) {
+	var value strings.Builder
+	for i := start; i < len(runes); i++ {
+		r := runes[i]
+		if r == '\'' {
+			return value.String(), i, nil
+		}
+		if r != '\\' || i+1 >= len(runes) {
+			value.WriteRune(r)
+			continue
+		}
+
+		i++
+		switch runes[i] {
+		case 'n':
+			value.WriteByte('\n')
+		case 't':
+			value.WriteByte('\t')
+		case 'r':
+			value.WriteByte('\r')
+		case '0':
+			value.WriteByte(0)
+		case 'x', 'u', 'U':
+			digits := map[rune]int{'x': 2, 'u': 4, 'U': 8}[runes[i]]
+			end := i + 1
+			for end < len(runes) && end <= i+digits && isHexDigit(runes[end]) {
+				end++
+			}
+			code, err := strconv.ParseUint(string(runes[i+1:end]), 16, 32)
+			if err != nil {
+				value.WriteRune(runes[i])
+				continue
+			}
+			if runes[i] == 'x' {
+				value.WriteByte(byte(code))
+			} else if utf8.ValidRune(rune(code)) {
+				value.WriteRune(rune(code))
+			}
+			i = end - 1
+		default:
+			value.WriteRune(runes[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated $' quote")
+}
+
+func isHexDigit(r rune) bool {
+	return (r >= '0' && r <= '9') || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')
+}
+
+// shellQuote quotes a value for a POSIX shell so that splitShellWords returns it unchanged
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,634 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"rikuest/internal/database"
+	"rikuest/internal/models"
+)
+
+const (
+	// docsHistory is how many of the latest executions of a request are searched for example
+	// responses, and docsExamples how many distinct statuses are shown
+	docsHistory  = 20
+	docsExamples = 5
+	// docsBodyLimit truncates example bodies so that a large download does not bloat the site
+	docsBodyLimit = 16 * 1024
+)
+
+// DocsService renders a project as a static HTML documentation site: an index with the project
+// description and its folders, and a page per request with its method, URL, parameters, auth
+// and example responses taken from saved examples and history.
+type DocsService struct {
+	db    *database.DB
+	files FileAccess
+}
+
+func NewDocsService(db *database.DB, files FileAccess) *DocsService {
+	return &DocsService{db: db, files: files}
+}
+
+// docsFolder is a folder of the navigation tree, or the project root when ID is 0
+type docsFolder struct {
+	ID          int
+	Name        string
+	Description string
+	Requests    []*docsRequest
+	Folders     []*docsFolder
+}
+
+type docsRequest struct {
+	ID          int
+	Name        string
+	Method      string
+	URL         string
+	Description string
+	Path        []string
+	PathParams  []docsParam
+	QueryParams []docsParam
+	Headers     []docsParam
+	Auth        docsAuth
+	Body        *docsBody
+	Examples    []docsExample
+}
+
+// docsParam is a parameter, header or form field with its example value
+type docsParam struct {
+	Name  string
+	Value string
+	Note  string
+}
+
+// docsAuth is the auth a request requires. Example shows how credentials are sent, without
+// their value, and Note explains what Example cannot.
+type docsAuth struct {
+	Scheme  string
+	Example string
+	Note    string
+}
+
+type docsBody struct {
+	MediaType string
+	Content   string
+	Fields    []docsParam
+}
+
+// docsExample is a response taken from a saved example or from history
+type docsExample struct {
+	Name       string
+	Status     int
+	StatusText string
+	Duration   int64
+	ExecutedAt *time.Time
+	Headers    []docsParam
+	Body       string
+	Truncated  bool
+}
+
+// docsServer is a {{variable}} requests start with and its value in each environment
+type docsServer struct {
+	Variable string
+	Values   []docsParam
+}
+
+// docsPage is what a page template receives. Root is the relative path back to the site root.
+type docsPage struct {
+	Project *models.Project
+	Root    string
+	Tree    *docsFolder
+	Servers []docsServer
+	Request *docsRequest
+}
+
+// GenerateProject renders the documentation site of a project, returning its files by path
+func (s *DocsService) GenerateProject(projectID int) (map[string][]byte, error) {
+	project, err := s.db.GetProject(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("project not found: %w", err)
+	}
+	folders, err := s.db.GetFolders(projectID)
+	if err != nil {
+		return nil, err
+	}
+	requests, err := s.db.GetRequests(projectID)
+	if err != nil {
+		return nil, err
+	}
+	examples, err := s.db.GetProjectExamples(projectID)
+	if err != nil {
+		return nil, err
+	}
+	saved := make(map[int][]models.ResponseExample)
+	for _, example := range examples {
+		saved[example.RequestID] = append(saved[example.RequestID], example)
+	}
+
+	byID := make(map[int]*docsFolder, len(folders))
+	root := &docsFolder{}
+	byID[0] = root
+	for _, folder := range folders {
+		byID[folder.ID] = &docsFolder{ID: folder.ID, Name: folder.Name, Description: folder.Description}
+	}
+	for _, folder := range folders {
+		parent := root
+		if folder.ParentID != nil && byID[*folder.ParentID] != nil {
+			parent = byID[*folder.ParentID]
+		}
+		parent.Folders = append(parent.Folders, byID[folder.ID])
+	}
+
+	var documented []*docsRequest
+	for _, request := range treeOrder(folders, requests) {
+		request := request
+		history, err := s.db.GetLatestRequestHistory(request.ID, docsHistory)
+		if err != nil {
+			return nil, err
+		}
+		doc := describeDocsRequest(&request, saved[request.ID], history)
+
+		folder := root
+		if request.FolderID != nil && byID[*request.FolderID] != nil {
+			folder = byID[*request.FolderID]
+		}
+		doc.Path = folderNames(folders, request.FolderID)
+		folder.Requests = append(folder.Requests, doc)
+		documented = append(documented, doc)
+	}
+
+	servers, err := s.docsServers(projectID, requests)
+	if err != nil {
+		return nil, err
+	}
+
+	page := docsPage{Project: project, Tree: root, Servers: servers}
+	files := map[string][]byte{"style.css": []byte(docsStyle)}
+	if files["index.html"], err = renderDocsPage("index", page); err != nil {
+		return nil, err
+	}
+	page.Root = "../"
+	for _, doc := range documented {
+		page.Request = doc
+		if files[docsRequestPage(doc.ID)], err = renderDocsPage("request", page); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// WriteProject writes the documentation site of a project to dir and returns the path of its
+// index page. Pages are named after request IDs, so renaming a request replaces its page. The
+// directory must be within reach of the file access of the service.
+func (s *DocsService) WriteProject(projectID int, dir string) (string, error) {
+	if dir == "" {
+		return "", fmt.Errorf("a directory is required")
+	}
+	dir, err := s.files.Resolve(dir)
+	if err != nil {
+		return "", err
+	}
+	files, err := s.GenerateProject(projectID)
+	if err != nil {
+		return "", err
+	}
+	for name, content := range files {
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(target, content, 0644); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(dir, "index.html"), nil
+}
+
+// ArchiveProject returns the documentation site of a project as a ZIP file
+func (s *DocsService) ArchiveProject(projectID int) ([]byte, error) {
+	files, err := s.GenerateProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	for _, name := range names {
+		writer, err := archive.Create(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := writer.Write(files[name]); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func describeDocsRequest(request *models.Request, examples []models.ResponseExample, history []models.RequestHistory) *docsRequest {
+	doc := &docsRequest{
+		ID:          request.ID,
+		Name:        request.Name,
+		Method:      strings.ToUpper(request.Method),
+		URL:         redactURL(request.URL),
+		Description: request.Description,
+		Auth:        describeDocsAuth(request),
+		Body:        describeDocsBody(request),
+	}
+	if doc.Method == "" {
+		doc.Method = "GET"
+	}
+
+	_, path, query := splitOpenAPIURL(request.URL)
+	_, pathParams := openAPIPathTemplate(path)
+	for _, param := range pathParams {
+		value := ""
+		if param.Example != nil {
+			value = fmt.Sprint(param.Example)
+		}
+		doc.PathParams = append(doc.PathParams, docsParam{Name: param.Name, Value: value, Note: fmt.Sprint(param.Schema["type"])})
+	}
+
+	seen := make(map[string]bool)
+	for _, param := range request.QueryParams {
+		if param.Key == "" || seen[param.Key] {
+			continue
+		}
+		seen[param.Key] = true
+		note := ""
+		if !param.Enabled {
+			note = "optional"
+		}
+		doc.QueryParams = append(doc.QueryParams, docsParam{Name: param.Key, Value: redactSecret(param.Key, param.Value), Note: note})
+	}
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !seen[key] {
+			seen[key] = true
+			doc.QueryParams = append(doc.QueryParams, docsParam{Name: key, Value: redactSecret(key, query.Get(key))})
+		}
+	}
+
+	for _, name := range sortedKeys(request.Headers) {
+		doc.Headers = append(doc.Headers, docsParam{Name: name, Value: redactSecret(name, request.Headers[name])})
+	}
+
+	// Saved examples come first, then the newest response of each status not shown yet
+	statuses := make(map[int]bool)
+	for _, example := range examples {
+		doc.Examples = append(doc.Examples, docsResponse(example.Name, models.RequestResponse{
+			Status:  example.Status,
+			Headers: example.Headers,
+			Body:    example.Body,
+		}, nil))
+		statuses[example.Status] = true
+	}
+	for _, entry := range history {
+		if len(doc.Examples) >= docsExamples {
+			break
+		}
+		if entry.Response.Status == 0 || statuses[entry.Response.Status] {
+			continue
+		}
+		statuses[entry.Response.Status] = true
+		executedAt := entry.ExecutedAt
+		doc.Examples = append(doc.Examples, docsResponse("", entry.Response, &executedAt))
+	}
+	return doc
+}
+
+func describeDocsAuth(request *models.Request) docsAuth {
+	switch request.AuthType {
+	case "bearer":
+		token := "<token>"
+		if onlyVariables(request.BearerToken) {
+			token = request.BearerToken
+		}
+		return docsAuth{Scheme: "Bearer token", Example: "Authorization: Bearer " + token}
+	case "basic":
+		return docsAuth{Scheme: "HTTP Basic", Example: "Authorization: Basic <credentials>",
+			Note: "The credentials are the username and password joined by a colon, Base64 encoded."}
+	}
+	for _, name := range sortedKeys(request.Headers) {
+		if secretHeaders[strings.ToLower(name)] {
+			return docsAuth{Scheme: "Header", Note: "Credentials are sent in the " + name + " header."}
+		}
+	}
+	return docsAuth{Scheme: "None"}
+}
+
+func describeDocsBody(request *models.Request) *docsBody {
+	switch request.BodyType {
+	case "form", "multipart":
+		mediaType := "application/x-www-form-urlencoded"
+		if request.BodyType == "multipart" {
+			mediaType = "multipart/form-data"
+		}
+		body := &docsBody{MediaType: mediaType}
+		for _, item := range request.FormData {
+			if item.Key == "" {
+				continue
+			}
+			if item.Type == "file" {
+				body.Fields = append(body.Fields, docsParam{Name: item.Key, Note: "file"})
+				continue
+			}
+			body.Fields = append(body.Fields, docsParam{Name: item.Key, Value: redactSecret(item.Key, item.Value)})
+		}
+		if len(body.Fields) == 0 {
+			return nil
+		}
+		return body
+	}
+
+	mediaType, _, ok := openAPIRequestSample(request)
+	if !ok {
+		return nil
+	}
+	content, _ := bodyExcerpt(redactBody(request.Body), docsBodyLimit)
+	return &docsBody{MediaType: mediaType, Content: content}
+}
+
+func docsResponse(name string, response models.RequestResponse, executedAt *time.Time) docsExample {
+	example := docsExample{
+		Name:       name,
+		Status:     response.Status,
+		StatusText: http.StatusText(response.Status),
+		Duration:   response.Duration,
+		ExecutedAt: executedAt,
+	}
+	for _, header := range sortedKeys(response.Headers) {
+		if strings.EqualFold(header, "Set-Cookie") {
+			continue
+		}
+		example.Headers = append(example.Headers, docsParam{Name: header, Value: redactSecret(header, response.Headers[header])})
+	}
+	example.Body, example.Truncated = bodyExcerpt(redactBody(response.Body), docsBodyLimit)
+	return example
+}
+
+// bodyExcerpt indents JSON bodies and truncates ones longer than limit bytes, reporting whether
+// it did
+func bodyExcerpt(body string, limit int) (string, bool) {
+	var indented bytes.Buffer
+	if json.Indent(&indented, []byte(body), "", "  ") == nil {
+		body = indented.String()
+	}
+	if len(body) <= limit {
+		return body, false
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(body[cut]) {
+		cut--
+	}
+	return body[:cut], true
+}
+
+// docsServers lists the {{variables}} requests start with, with their value in each
+// environment, so that readers know where to send requests
+func (s *DocsService) docsServers(projectID int, requests []models.Request) ([]docsServer, error) {
+	used := make(map[string]bool)
+	var names []string
+	for _, request := range requests {
+		match := openAPIURLVariable.FindStringSubmatch(strings.TrimSpace(request.URL))
+		if match != nil && !used[match[1]] {
+			used[match[1]] = true
+			names = append(names, match[1])
+		}
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+	sort.Strings(names)
+
+	environments, err := s.db.GetEnvironments(projectID)
+	if err != nil {
+		return nil, err
+	}
+	var servers []docsServer
+	for _, name := range names {
+		server := docsServer{Variable: name}
+		for _, environment := range environments {
+			for _, variable := range environment.Variables {
+				if variable.Key == name && variable.Enabled {
+					value := redactURL(redactSecret(variable.Key, variable.Value))
+					server.Values = append(server.Values, docsParam{Name: environment.Name, Value: value})
+				}
+			}
+		}
+		servers = append(servers, server)
+	}
+	return servers, nil
+}
+
+// folderNames lists the names of the folders from the root down to folderID
+func folderNames(folders []models.Folder, folderID *int) []string {
+	byID := make(map[int]models.Folder, len(folders))
+	for _, folder := range folders {
+		byID[folder.ID] = folder
+	}
+	var path []string
+	for id := folderID; id != nil && len(path) <= len(folders); {
+		folder, ok := byID[*id]
+		if !ok {
+			break
+		}
+		path = append([]string{folder.Name}, path...)
+		id = folder.ParentID
+	}
+	return path
+}
+
+func docsRequestPage(id int) string {
+	return fmt.Sprintf("requests/%d.html", id)
+}
+
+var docsTemplates = template.Must(template.New("docs").Funcs(template.FuncMap{
+	"markdown": func(source string) template.HTML { return template.HTML(renderMarkdown(source)) },
+	"lower":    strings.ToLower,
+	"page":     docsRequestPage,
+	"branch": func(root string, folder *docsFolder) docsBranch {
+		return docsBranch{Root: root, Folder: folder}
+	},
+	"statusClass": func(status int) string { return fmt.Sprintf("s%dxx", status/100) },
+}).Parse(docsLayout))
+
+// docsBranch is a folder of the navigation tree with the path back to the site root
+type docsBranch struct {
+	Root   string
+	Folder *docsFolder
+}
+
+func renderDocsPage(name string, page docsPage) ([]byte, error) {
+	var out bytes.Buffer
+	if err := docsTemplates.ExecuteTemplate(&out, name, page); err != nil {
+		return nil, fmt.Errorf("failed to render documentation: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+const docsLayout = `
+{{define "head"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{if .Request}}{{.Request.Name}} · {{end}}{{.Project.Name}}</title>
+<link rel="stylesheet" href="{{.Root}}style.css">
+</head>
+<body>
+<nav>
+<a class="project" href="{{.Root}}index.html">{{.Project.Name}}</a>
+{{template "tree" .}}
+</nav>
+<main>
+{{end}}
+
+{{define "tree"}}{{template "branch" (branch .Root .Tree)}}{{end}}
+
+{{define "branch"}}{{$root := .Root}}<ul>
+{{range .Folder.Requests}}<li><a href="{{$root}}{{page .ID}}"><span class="method {{lower .Method}}">{{.Method}}</span> {{.Name}}</a></li>
+{{end}}{{range .Folder.Folders}}<li><a class="folder" href="{{$root}}index.html#folder-{{.ID}}">{{.Name}}</a>
+{{template "branch" (branch $root .)}}</li>
+{{end}}</ul>{{end}}
+
+{{define "foot"}}
+<footer>Generated by Rikuest</footer>
+</main>
+</body>
+</html>
+{{end}}
+
+{{define "params"}}{{if .}}<table>
+<thead><tr><th>Name</th><th>Example</th><th></th></tr></thead>
+<tbody>
+{{range .}}<tr><td><code>{{.Name}}</code></td><td>{{if .Value}}<code>{{.Value}}</code>{{end}}</td><td>{{.Note}}</td></tr>
+{{end}}</tbody>
+</table>{{end}}{{end}}
+
+{{define "index"}}{{template "head" .}}
+<h1>{{.Project.Name}}</h1>
+{{if .Project.Description}}<div class="markdown">{{markdown .Project.Description}}</div>{{end}}
+{{if .Servers}}<h2>Servers</h2>
+{{range .Servers}}<h3><code>{{"{{"}}{{.Variable}}{{"}}"}}</code></h3>
+{{if .Values}}<table>
+<thead><tr><th>Environment</th><th>Value</th></tr></thead>
+<tbody>
+{{range .Values}}<tr><td>{{.Name}}</td><td><code>{{.Value}}</code></td></tr>
+{{end}}</tbody>
+</table>{{else}}<p class="muted">No environment defines this variable.</p>{{end}}
+{{end}}{{end}}
+{{template "contents" .Tree}}
+{{template "foot" .}}{{end}}
+
+{{define "contents"}}{{if .Requests}}<ul class="requests">
+{{range .Requests}}<li><span class="method {{lower .Method}}">{{.Method}}</span> <a href="{{page .ID}}">{{.Name}}</a> <code class="url">{{.URL}}</code></li>
+{{end}}</ul>{{end}}
+{{range .Folders}}<section class="folder" id="folder-{{.ID}}">
+<h2>{{.Name}}</h2>
+{{if .Description}}<div class="markdown">{{markdown .Description}}</div>{{end}}
+{{template "contents" .}}
+</section>
+{{end}}{{end}}
+
+{{define "request"}}{{template "head" .}}{{with .Request}}
+{{if .Path}}<p class="breadcrumbs">{{range $i, $name := .Path}}{{if $i}} / {{end}}{{$name}}{{end}}</p>{{end}}
+<h1>{{.Name}}</h1>
+<p class="endpoint"><span class="method {{lower .Method}}">{{.Method}}</span> <code>{{.URL}}</code></p>
+{{if .Description}}<div class="markdown">{{markdown .Description}}</div>{{end}}
+
+<h2>Authentication</h2>
+<p><strong>{{.Auth.Scheme}}</strong>{{with .Auth.Example}} <code>{{.}}</code>{{end}}</p>
+{{with .Auth.Note}}<p>{{.}}</p>{{end}}
+
+{{if .PathParams}}<h2>Path parameters</h2>
+{{template "params" .PathParams}}{{end}}
+{{if .QueryParams}}<h2>Query parameters</h2>
+{{template "params" .QueryParams}}{{end}}
+{{if .Headers}}<h2>Headers</h2>
+{{template "params" .Headers}}{{end}}
+{{with .Body}}<h2>Body <code class="media-type">{{.MediaType}}</code></h2>
+{{if .Fields}}{{template "params" .Fields}}{{else}}<pre><code>{{.Content}}</code></pre>{{end}}{{end}}
+
+{{if .Examples}}<h2>Example responses</h2>
+{{range .Examples}}<details class="example"{{if ge .Status 400}}{{else}} open{{end}}>
+<summary><span class="status {{statusClass .Status}}">{{.Status}}</span> {{if .Name}}{{.Name}}{{else}}{{.StatusText}}{{end}}
+{{with .ExecutedAt}}<span class="muted">recorded {{.Format "2006-01-02 15:04"}}</span>{{end}}{{if .Duration}} <span class="muted">{{.Duration}} ms</span>{{end}}</summary>
+{{if .Headers}}{{template "params" .Headers}}{{end}}
+{{if .Body}}<pre><code>{{.Body}}</code></pre>{{if .Truncated}}<p class="muted">Truncated.</p>{{end}}{{end}}
+</details>
+{{end}}{{end}}
+{{end}}{{template "foot" .}}{{end}}
+`
+
+const docsStyle = `:root {
+  --text: #1f2937; --muted: #6b7280; --border: #e5e7eb; --subtle: #f9fafb; --accent: #2563eb;
+}
+* { box-sizing: border-box; }
+body { margin: 0; display: flex; min-height: 100vh; color: var(--text);
+  font: 15px/1.6 -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif; }
+nav { width: 300px; flex-shrink: 0; padding: 24px 16px; border-right: 1px solid var(--border);
+  background: var(--subtle); font-size: 14px; position: sticky; top: 0; height: 100vh; overflow-y: auto; }
+nav ul { list-style: none; margin: 0; padding-left: 12px; }
+nav > ul { padding-left: 0; }
+nav li { margin: 2px 0; }
+nav a { color: var(--text); text-decoration: none; }
+nav a:hover { color: var(--accent); }
+nav .project { display: block; font-weight: 600; font-size: 16px; margin-bottom: 16px; }
+nav .folder { font-weight: 600; }
+nav .method { display: inline-block; width: 52px; }
+main { flex: 1; min-width: 0; max-width: 960px; padding: 32px 48px; }
+h1 { margin-top: 0; }
+h2 { margin-top: 32px; padding-bottom: 4px; border-bottom: 1px solid var(--border); }
+a { color: var(--accent); }
+code { font: 13px/1.5 ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; background: var(--subtle);
+  padding: 1px 4px; border-radius: 4px; word-break: break-all; }
+pre { background: var(--subtle); border: 1px solid var(--border); border-radius: 6px; padding: 12px; overflow-x: auto; }
+pre code { background: none; padding: 0; word-break: normal; }
+table { border-collapse: collapse; width: 100%; margin: 8px 0 16px; font-size: 14px; }
+th, td { text-align: left; padding: 6px 10px; border-bottom: 1px solid var(--border); vertical-align: top; }
+th { background: var(--subtle); }
+blockquote { margin: 0; padding-left: 16px; border-left: 3px solid var(--border); color: var(--muted); }
+.muted, .breadcrumbs, footer { color: var(--muted); font-size: 13px; }
+.endpoint code { font-size: 15px; }
+.requests { list-style: none; padding: 0; }
+.requests li { padding: 4px 0; }
+.requests .url { color: var(--muted); }
+.folder { margin-top: 24px; }
+.method { font: 600 12px/1.5 ui-monospace, SFMono-Regular, Menlo, monospace; color: #6b7280; }
+.method.get { color: #16a34a; } .method.post { color: #ca8a04; } .method.put { color: #2563eb; }
+.method.patch { color: #7c3aed; } .method.delete { color: #dc2626; }
+.media-type { font-size: 12px; font-weight: normal; }
+details.example { border: 1px solid var(--border); border-radius: 6px; padding: 8px 12px; margin: 12px 0; }
+details.example summary { cursor: pointer; }
+.status { font-weight: 600; padding: 1px 6px; border-radius: 4px; color: #fff; background: #6b7280; }
+.status.s2xx { background: #16a34a; } .status.s3xx { background: #2563eb; }
+.status.s4xx { background: #ca8a04; } .status.s5xx { background: #dc2626; }
+footer { margin-top: 48px; padding-top: 16px; border-top: 1px solid var(--border); }
+@media (max-width: 800px) {
+  body { display: block; }
+  nav { width: auto; height: auto; position: static; border-right: none; border-bottom: 1px solid var(--border); }
+  main { padding: 24px 16px; }
+}
+`
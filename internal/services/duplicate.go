@@ -0,0 +1,129 @@
+package services
+
+import (
+	"fmt"
+
+	"rikuest/internal/models"
+)
+
+// A target without a project means the folder of the item, unless the target names another
+// folder of its project. A copy placed next to its original is named "<name> (Copy)" unless the
+// target names it.
+
+// copyName returns the name of a copy of an item in parentID, placed in target
+func copyName(name string, projectID int, parentID *int, target models.CopyTarget) string {
+	if target.Name != "" {
+		return target.Name
+	}
+	if target.ProjectID == projectID && sameFolder(parentID, target.FolderID) {
+		return name + " (Copy)"
+	}
+	return ""
+}
+
+// sourceTarget completes a target without a project with the project and folder of the item
+func sourceTarget(target models.CopyTarget, projectID int, folderID *int) models.CopyTarget {
+	target.ProjectID = projectID
+	if target.FolderID == nil {
+		target.FolderID = folderID
+	}
+	return target
+}
+
+// CopyRequest copies a request with its examples and returns the copy
+func (s *RequestService) CopyRequest(requestID int, target models.CopyTarget) (*models.Request, error) {
+	request, err := s.db.GetRequest(requestID)
+	if err != nil {
+		return nil, fmt.Errorf("request not found: %w", err)
+	}
+	if target.ProjectID == 0 {
+		target = sourceTarget(target, request.ProjectID, request.FolderID)
+	}
+	target.Name = copyName(request.Name, request.ProjectID, request.FolderID, target)
+
+	id, err := s.db.CopyRequest(requestID, target)
+	if err != nil {
+		return nil, err
+	}
+	return s.db.GetRequest(id)
+}
+
+// MoveRequestTo moves a request to the end of a folder or project root of any project
+func (s *RequestService) MoveRequestTo(requestID int, target models.CopyTarget) error {
+	if target.ProjectID == 0 {
+		request, err := s.db.GetRequest(requestID)
+		if err != nil {
+			return fmt.Errorf("request not found: %w", err)
+		}
+		target = sourceTarget(target, request.ProjectID, request.FolderID)
+	}
+	return s.db.MoveRequestTo(requestID, target)
+}
+
+// CopyFolder copies a folder with all its subfolders and requests and returns the copy
+func (s *FolderService) CopyFolder(folderID int, target models.CopyTarget) (*models.Folder, error) {
+	folder, err := s.db.GetFolder(folderID)
+	if err != nil {
+		return nil, fmt.Errorf("folder not found: %w", err)
+	}
+	if target.ProjectID == 0 {
+		target = sourceTarget(target, folder.ProjectID, folder.ParentID)
+	}
+	target.Name = copyName(folder.Name, folder.ProjectID, folder.ParentID, target)
+
+	id, err := s.db.CopyFolder(folderID, target)
+	if err != nil {
+		return nil, err
+	}
+	return s.db.GetFolder(id)
+}
+
+// MoveFolder moves a folder with all its subfolders and requests to the end of a folder or
+// project root of any project
+func (s *FolderService) MoveFolder(folderID int, target models.CopyTarget) error {
+	if target.ProjectID == 0 {
+		folder, err := s.db.GetFolder(folderID)
+		if err != nil {
+			return fmt.Errorf("folder not found: %w", err)
+		}
+		target = sourceTarget(target, folder.ProjectID, folder.ParentID)
+	}
+	return s.db.MoveFolder(folderID, target)
+}
+
+// CopyProject copies a project. Without a target project the copy is a new project, with the
+// environments, tags and saved filters of the original, named after the target or "<name> (Copy)". Otherwise the
+// folders and requests of the project are copied into the target folder.
+func (s *ProjectService) CopyProject(projectID int, target models.CopyTarget) (*models.Project, error) {
+	project, err := s.db.GetProject(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("project not found: %w", err)
+	}
+
+	if target.ProjectID != 0 {
+		if err := s.db.CopyProjectContents(projectID, target, false); err != nil {
+			return nil, err
+		}
+		return s.db.GetProject(target.ProjectID)
+	}
+
+	if target.FolderID != nil {
+		return nil, fmt.Errorf("a target folder needs a target project")
+	}
+	name := target.Name
+	if name == "" {
+		name = project.Name + " (Copy)"
+	}
+	if name, err = uniqueProjectName(s.db, name); err != nil {
+		return nil, err
+	}
+	copied := &models.Project{Name: name, Description: project.Description}
+	if err := s.db.CreateProject(copied); err != nil {
+		return nil, err
+	}
+	if err := s.db.CopyProjectContents(projectID, models.CopyTarget{ProjectID: copied.ID}, true); err != nil {
+		s.db.PurgeProject(copied.ID)
+		return nil, err
+	}
+	return copied, nil
+}
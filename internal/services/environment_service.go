@@ -0,0 +1,128 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"rikuest/internal/database"
+	"rikuest/internal/models"
+)
+
+// variablePattern matches {{name}} placeholders, allowing spaces inside the braces
+var variablePattern = regexp.MustCompile(`\{\{\s*([^{}\s]+)\s*\}\}`)
+
+type EnvironmentService struct {
+	db *database.DB
+}
+
+func NewEnvironmentService(db *database.DB) *EnvironmentService {
+	return &EnvironmentService{db: db}
+}
+
+func (s *EnvironmentService) GetEnvironments(projectID int) ([]models.Environment, error) {
+	return s.db.GetEnvironments(projectID)
+}
+
+func (s *EnvironmentService) GetEnvironment(id int) (*models.Environment, error) {
+	return s.db.GetEnvironment(id)
+}
+
+func (s *EnvironmentService) GetActiveEnvironment(projectID int) (*models.Environment, error) {
+	return s.db.GetActiveEnvironment(projectID)
+}
+
+func (s *EnvironmentService) CreateEnvironment(environment *models.Environment) error {
+	if strings.TrimSpace(environment.Name) == "" {
+		return fmt.Errorf("environment name is required")
+	}
+	if _, err := s.db.GetProject(environment.ProjectID); err != nil {
+		return fmt.Errorf("project not found: %w", err)
+	}
+	if environment.Variables == nil {
+		environment.Variables = []models.Variable{}
+	}
+	// Activation goes through SetActiveEnvironment so only one environment is ever active
+	active := environment.IsActive
+	environment.IsActive = false
+	if err := s.db.CreateEnvironment(environment); err != nil {
+		return err
+	}
+	if active {
+		if err := s.SetActiveEnvironment(environment.ProjectID, &environment.ID); err != nil {
+			return err
+		}
+		environment.IsActive = true
+	}
+	return nil
+}
+
+func (s *EnvironmentService) UpdateEnvironment(environment *models.Environment) error {
+	if strings.TrimSpace(environment.Name) == "" {
+		return fmt.Errorf("environment name is required")
+	}
+	return s.db.UpdateEnvironment(environment)
+}
+
+func (s *EnvironmentService) DeleteEnvironment(id int) error {
+	return s.db.DeleteEnvironment(id)
+}
+
+// SetActiveEnvironment selects the environment used when executing the project's requests.
+// A nil environmentID runs requests without variable substitution.
+func (s *EnvironmentService) SetActiveEnvironment(projectID int, environmentID *int) error {
+	return s.db.SetActiveEnvironment(projectID, environmentID)
+}
+
+// applyEnvironment returns a copy of the request with {{variables}} replaced by the enabled
+// variables. Unknown placeholders are left untouched.
+func applyEnvironment(request *models.Request, variables []models.Variable) *models.Request {
+	values := make(map[string]string)
+	for _, variable := range variables {
+		if variable.Enabled && variable.Key != "" {
+			values[variable.Key] = variable.Value
+		}
+	}
+	if len(values) == 0 {
+		return request
+	}
+
+	replace := func(text string) string {
+		if !strings.Contains(text, "{{") {
+			return text
+		}
+		return variablePattern.ReplaceAllStringFunc(text, func(match string) string {
+			name := variablePattern.FindStringSubmatch(match)[1]
+			if value, ok := values[name]; ok {
+				return value
+			}
+			return match
+		})
+	}
+
+	resolved := *request
+	resolved.URL = replace(request.URL)
+	resolved.Body = replace(request.Body)
+	resolved.BearerToken = replace(request.BearerToken)
+	resolved.BasicAuth = models.BasicAuth{
+		Username: replace(request.BasicAuth.Username),
+		Password: replace(request.BasicAuth.Password),
+	}
+
+	resolved.Headers = make(map[string]string, len(request.Headers))
+	for key, value := range request.Headers {
+		resolved.Headers[replace(key)] = replace(value)
+	}
+
+	resolved.QueryParams = make([]models.QueryParam, len(request.QueryParams))
+	for i, param := range request.QueryParams {
+		resolved.QueryParams[i] = models.QueryParam{Key: replace(param.Key), Value: replace(param.Value), Enabled: param.Enabled}
+	}
+
+	resolved.FormData = make([]models.FormData, len(request.FormData))
+	for i, item := range request.FormData {
+		resolved.FormData[i] = models.FormData{Key: replace(item.Key), Value: replace(item.Value), Type: item.Type}
+	}
+
+	return &resolved
+}
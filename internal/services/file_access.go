@@ -0,0 +1,128 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileAccess limits the local files services read and write on behalf of their clients, such as
+// the files uploaded by multipart form fields. The desktop app acts for the user of the machine
+// and allows any path. The server is reached over the network without authentication, so it
+// only allows paths inside Root.
+type FileAccess struct {
+	// Unrestricted allows paths outside Root
+	Unrestricted bool
+	// Root holds uploaded files, and relative paths are resolved against it. Without a Root,
+	// restricted access refuses every path.
+	Root string
+}
+
+// uploadsDir is the directory of Root that SaveUpload writes to
+const uploadsDir = "uploads"
+
+// Resolve returns the absolute path a client-given path refers to, or an error when the path is
+// out of reach
+func (a FileAccess) Resolve(path string) (string, error) {
+	if strings.TrimSpace(path) == "" {
+		return "", errors.New("no file selected")
+	}
+	if a.Unrestricted && filepath.IsAbs(path) {
+		return filepath.Clean(path), nil
+	}
+	if a.Root == "" {
+		return "", fmt.Errorf("%s: local files are not available", path)
+	}
+
+	root, err := filepath.Abs(a.Root)
+	if err != nil {
+		return "", err
+	}
+	target := path
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(root, target)
+	}
+	target = filepath.Clean(target)
+	if a.Unrestricted {
+		return target, nil
+	}
+
+	if !insideDir(root, target) {
+		return "", fmt.Errorf("%s is outside of %s", path, root)
+	}
+	// A symbolic link inside the root may still lead out of it
+	if resolvedRoot, err := filepath.EvalSymlinks(root); err == nil {
+		if resolved, err := evalExistingSymlinks(target); err == nil && !insideDir(resolvedRoot, resolved) {
+			return "", fmt.Errorf("%s is outside of %s", path, root)
+		}
+	}
+	return target, nil
+}
+
+// SaveUpload stores an uploaded file in its own directory under Root and returns the path to
+// refer to it by, relative to Root
+func (a FileAccess) SaveUpload(name string, content io.Reader) (string, error) {
+	if a.Root == "" {
+		return "", errors.New("uploads are not available")
+	}
+	name = filepath.Base(filepath.Clean("/" + strings.ReplaceAll(name, `\`, "/")))
+	if name == "/" || name == "." {
+		name = "upload"
+	}
+
+	random := make([]byte, 8)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	relative := filepath.Join(uploadsDir, hex.EncodeToString(random), name)
+	path := filepath.Join(a.Root, relative)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", fmt.Errorf("failed to save upload: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return "", fmt.Errorf("failed to save upload: %w", err)
+	}
+	if _, err := io.Copy(file, content); err != nil {
+		file.Close()
+		os.Remove(path)
+		return "", fmt.Errorf("failed to save upload: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to save upload: %w", err)
+	}
+	return filepath.ToSlash(relative), nil
+}
+
+// insideDir reports whether path is dir or below it. Both must be clean absolute paths.
+func insideDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// evalExistingSymlinks resolves the symbolic links of the part of path that exists, so that
+// paths of files yet to be created are checked too
+func evalExistingSymlinks(path string) (string, error) {
+	missing := ""
+	for {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err == nil {
+			return filepath.Join(resolved, missing), nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return "", err
+		}
+		missing = filepath.Join(filepath.Base(path), missing)
+		path = parent
+	}
+}
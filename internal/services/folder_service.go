@@ -1,6 +1,8 @@
 package services
 
 import (
+	"fmt"
+
 	"rikuest/internal/database"
 	"rikuest/internal/models"
 )
@@ -27,4 +29,36 @@ func (s *FolderService) UpdateFolder(folder *models.Folder) error {
 
 func (s *FolderService) DeleteFolder(id int) error {
 	return s.db.DeleteFolder(id)
-}
\ No newline at end of file
+}
+
+// DeleteFolderKeepingContents moves a folder to the trash after moving its subfolders and
+// requests up to its parent
+func (s *FolderService) DeleteFolderKeepingContents(id int) error {
+	return s.db.DeleteFolderKeepingContents(id)
+}
+
+// ReorderFolders sets the order of the folders directly in parentID, or at the project root
+func (s *FolderService) ReorderFolders(projectID int, parentID *int, folderIDs []int) error {
+	if _, err := s.db.GetProject(projectID); err != nil {
+		return fmt.Errorf("project not found: %w", err)
+	}
+	return s.db.ReorderFolders(projectID, parentID, folderIDs)
+}
+
+// folderSubtree returns the IDs of rootID and every folder nested below it
+func folderSubtree(folders []models.Folder, rootID int) map[int]bool {
+	subtree := map[int]bool{rootID: true}
+
+	// Repeat until no new descendants are found, so the input order does not matter
+	for changed := true; changed; {
+		changed = false
+		for _, folder := range folders {
+			if folder.ParentID != nil && subtree[*folder.ParentID] && !subtree[folder.ID] {
+				subtree[folder.ID] = true
+				changed = true
+			}
+		}
+	}
+
+	return subtree
+}
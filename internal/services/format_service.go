@@ -4,6 +4,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"net/url"
+	"path/filepath"
 	"strings"
 
 	"rikuest/internal/models"
@@ -19,19 +20,23 @@ func NewFormatService() *FormatService {
 
 // RequestFormats contains all available formats for a request
 type RequestFormats struct {
-	Raw    string `json:"raw"`
-	Curl   string `json:"curl"`
-	Fetch  string `json:"fetch"`
-	Python string `json:"python"`
+	Raw      string `json:"raw"`
+	Curl     string `json:"curl"`
+	Fetch    string `json:"fetch"`
+	Python   string `json:"python"`
+	HTTPFile string `json:"http-file"`
+	OpenAPI  string `json:"openapi"`
 }
 
 // GetAllFormats generates all available formats for a request
 func (fs *FormatService) GetAllFormats(request *models.Request) *RequestFormats {
 	return &RequestFormats{
-		Raw:    fs.BuildRawRequest(request),
-		Curl:   fs.BuildCurlRequest(request),
-		Fetch:  fs.BuildFetchRequest(request),
-		Python: fs.BuildPythonRequest(request),
+		Raw:      fs.BuildRawRequest(request),
+		Curl:     fs.BuildCurlRequest(request),
+		Fetch:    fs.BuildFetchRequest(request),
+		Python:   fs.BuildPythonRequest(request),
+		HTTPFile: fs.BuildHTTPFileRequest(request),
+		OpenAPI:  fs.BuildOpenAPIRequest(request),
 	}
 }
 
@@ -46,8 +51,12 @@ func (fs *FormatService) GetFormat(request *models.Request, format string) (stri
 		return fs.BuildFetchRequest(request), nil
 	case "python":
 		return fs.BuildPythonRequest(request), nil
+	case "http-file":
+		return fs.BuildHTTPFileRequest(request), nil
+	case "openapi":
+		return fs.BuildOpenAPIRequest(request), nil
 	default:
-		return "", fmt.Errorf("unsupported format. Supported formats: raw, curl, fetch, python")
+		return "", fmt.Errorf("unsupported format. Supported formats: raw, curl, fetch, python, http-file, openapi")
 	}
 }
 
@@ -112,7 +121,14 @@ func (fs *FormatService) BuildRawRequest(request *models.Request) string {
 
 	// Determine the actual body content
 	var actualBody string
-	if request.BodyType == "form" && len(request.FormData) > 0 {
+	if request.BodyType == "multipart" && len(request.FormData) > 0 {
+		// Build multipart body, showing file fields as placeholders
+		multipartBody, contentType, _ := buildMultipartBody(request.FormData, multipartPreviewBoundary, nil)
+		actualBody = string(multipartBody)
+		if headerValue(request.Headers, "Content-Type") == "" {
+			rawRequest.WriteString("Content-Type: " + contentType + "\r\n")
+		}
+	} else if request.BodyType == "form" && len(request.FormData) > 0 {
 		// Build form data body
 		formValues := url.Values{}
 		for _, item := range request.FormData {
@@ -181,24 +197,36 @@ func (fs *FormatService) BuildCurlRequest(request *models.Request) string {
 	curlCmd.WriteString("curl -X " + request.Method)
 
 	// Add headers
-	for key, value := range request.Headers {
-		curlCmd.WriteString(fmt.Sprintf(" -H \"%s: %s\"", key, value))
+	for _, key := range sortedKeys(request.Headers) {
+		curlCmd.WriteString(" -H " + shellQuote(key+": "+request.Headers[key]))
 	}
 
 	// Add authorization headers based on auth type
 	switch request.AuthType {
 	case "bearer":
 		if request.BearerToken != "" {
-			curlCmd.WriteString(fmt.Sprintf(" -H \"Authorization: Bearer %s\"", request.BearerToken))
+			curlCmd.WriteString(" -H " + shellQuote("Authorization: Bearer "+request.BearerToken))
 		}
 	case "basic":
 		if request.BasicAuth.Username != "" || request.BasicAuth.Password != "" {
-			curlCmd.WriteString(fmt.Sprintf(" -u \"%s:%s\"", request.BasicAuth.Username, request.BasicAuth.Password))
+			curlCmd.WriteString(" -u " + shellQuote(request.BasicAuth.Username+":"+request.BasicAuth.Password))
 		}
 	}
 
 	// Add body data
-	if request.BodyType == "form" && len(request.FormData) > 0 {
+	if request.BodyType == "multipart" && len(request.FormData) > 0 {
+		// curl reads file fields from the path after @
+		for _, item := range request.FormData {
+			if item.Key == "" {
+				continue
+			}
+			if item.Type == "file" {
+				curlCmd.WriteString(" -F " + shellQuote(item.Key+"=@"+item.Value))
+			} else {
+				curlCmd.WriteString(" --form-string " + shellQuote(item.Key+"="+item.Value))
+			}
+		}
+	} else if request.BodyType == "form" && len(request.FormData) > 0 {
 		// Build form data
 		formValues := url.Values{}
 		for _, item := range request.FormData {
@@ -206,13 +234,13 @@ func (fs *FormatService) BuildCurlRequest(request *models.Request) string {
 				formValues.Add(item.Key, item.Value)
 			}
 		}
-		curlCmd.WriteString(fmt.Sprintf(" -d \"%s\"", formValues.Encode()))
+		curlCmd.WriteString(" -d " + shellQuote(formValues.Encode()))
 	} else if request.Body != "" {
-		curlCmd.WriteString(fmt.Sprintf(" -d '%s'", request.Body))
+		curlCmd.WriteString(" --data-raw " + shellQuote(request.Body))
 	}
 
 	// Add the URL
-	curlCmd.WriteString(fmt.Sprintf(" \"%s\"", finalURL))
+	curlCmd.WriteString(" " + shellQuote(finalURL))
 
 	return curlCmd.String()
 }
@@ -264,7 +292,22 @@ func (fs *FormatService) BuildFetchRequest(request *models.Request) string {
 
 	// Build body
 	var bodyContent string
-	if request.BodyType == "form" && len(request.FormData) > 0 {
+	if request.BodyType == "multipart" && len(request.FormData) > 0 {
+		// Build a FormData object; the browser sets the multipart boundary
+		fetchCmd.WriteString("const formData = new FormData();\n")
+		for _, item := range request.FormData {
+			if item.Key == "" {
+				continue
+			}
+			if item.Type == "file" {
+				fetchCmd.WriteString(fmt.Sprintf("formData.append('%s', fileInput.files[0]); // %s\n", strings.ReplaceAll(item.Key, "'", "\\'"), item.Value))
+			} else {
+				fetchCmd.WriteString(fmt.Sprintf("formData.append('%s', '%s');\n", strings.ReplaceAll(item.Key, "'", "\\'"), strings.ReplaceAll(item.Value, "'", "\\'")))
+			}
+		}
+		fetchCmd.WriteString("\n")
+		bodyContent = "formData"
+	} else if request.BodyType == "form" && len(request.FormData) > 0 {
 		// Build form data
 		formValues := url.Values{}
 		for _, item := range request.FormData {
@@ -350,7 +393,21 @@ func (fs *FormatService) BuildPythonRequest(request *models.Request) string {
 
 	// Build body
 	var bodyContent string
-	if request.BodyType == "form" && len(request.FormData) > 0 {
+	if request.BodyType == "multipart" && len(request.FormData) > 0 {
+		// Text fields go in data and file fields in files
+		var fields, files []string
+		for _, item := range request.FormData {
+			if item.Key == "" {
+				continue
+			}
+			if item.Type == "file" {
+				files = append(files, fmt.Sprintf("%q: open(%q, 'rb')", item.Key, item.Value))
+			} else {
+				fields = append(fields, fmt.Sprintf("%q: %q", item.Key, item.Value))
+			}
+		}
+		bodyContent = "data={" + strings.Join(fields, ", ") + "},\n    files={" + strings.Join(files, ", ") + "}"
+	} else if request.BodyType == "form" && len(request.FormData) > 0 {
 		// Build form data
 		formData := make(map[string]string)
 		for _, item := range request.FormData {
@@ -383,3 +440,116 @@ func (fs *FormatService) BuildPythonRequest(request *models.Request) string {
 
 	return pythonCmd.String()
 }
+
+// BuildHTTPFileRequest constructs a request block for .http files used by the VS Code REST Client
+// and the JetBrains HTTP Client. {{variables}} are written unencoded so both tools resolve them.
+func (fs *FormatService) BuildHTTPFileRequest(request *models.Request) string {
+	var httpFile strings.Builder
+
+	httpFile.WriteString("### " + request.Name + "\n")
+
+	// Construct the request line, keeping any query string already in the URL
+	finalURL := request.URL
+	var queryParts []string
+	for _, param := range request.QueryParams {
+		if param.Enabled && param.Key != "" {
+			queryParts = append(queryParts, httpFileEscape(param.Key)+"="+httpFileEscape(param.Value))
+		}
+	}
+	if len(queryParts) > 0 {
+		separator := "?"
+		if strings.Contains(finalURL, "?") {
+			separator = "&"
+		}
+		finalURL += separator + strings.Join(queryParts, "&")
+	}
+	httpFile.WriteString(request.Method + " " + finalURL + " HTTP/1.1\n")
+
+	// Add headers
+	for _, key := range sortedKeys(request.Headers) {
+		httpFile.WriteString(key + ": " + request.Headers[key] + "\n")
+	}
+
+	// Add authorization headers based on auth type
+	switch request.AuthType {
+	case "bearer":
+		if request.BearerToken != "" {
+			httpFile.WriteString("Authorization: Bearer " + request.BearerToken + "\n")
+		}
+	case "basic":
+		if request.BasicAuth.Username != "" || request.BasicAuth.Password != "" {
+			credentials := request.BasicAuth.Username + ":" + request.BasicAuth.Password
+			// Encoding would hide variables from the client, which accepts plain credentials
+			if !strings.Contains(credentials, "{{") {
+				credentials = base64.StdEncoding.EncodeToString([]byte(credentials))
+			}
+			httpFile.WriteString("Authorization: Basic " + credentials + "\n")
+		}
+	}
+
+	// Add body data
+	var body string
+	if request.BodyType == "multipart" && len(request.FormData) > 0 {
+		body = buildHTTPFileMultipart(request.FormData)
+		if headerValue(request.Headers, "Content-Type") == "" {
+			httpFile.WriteString("Content-Type: multipart/form-data; boundary=" + multipartPreviewBoundary + "\n")
+		}
+	} else if request.BodyType == "form" && len(request.FormData) > 0 {
+		var pairs []string
+		for _, item := range request.FormData {
+			if item.Key != "" {
+				pairs = append(pairs, httpFileEscape(item.Key)+"="+httpFileEscape(item.Value))
+			}
+		}
+		body = strings.Join(pairs, "\n&")
+		if headerValue(request.Headers, "Content-Type") == "" {
+			httpFile.WriteString("Content-Type: application/x-www-form-urlencoded\n")
+		}
+	} else if request.Body != "" {
+		body = request.Body
+	}
+
+	if body != "" {
+		httpFile.WriteString("\n" + strings.TrimRight(body, "\n") + "\n")
+	}
+
+	return httpFile.String()
+}
+
+// buildHTTPFileMultipart writes a multipart body by hand so file fields can use the
+// "< path" syntax that makes the client read the file
+func buildHTTPFileMultipart(formData []models.FormData) string {
+	var body strings.Builder
+	for _, item := range formData {
+		if item.Key == "" {
+			continue
+		}
+		body.WriteString("--" + multipartPreviewBoundary + "\n")
+		if item.Type == "file" {
+			body.WriteString(fmt.Sprintf("Content-Disposition: form-data; name=%q; filename=%q\n\n", item.Key, filepath.Base(item.Value)))
+			body.WriteString("< " + item.Value + "\n")
+		} else {
+			body.WriteString(fmt.Sprintf("Content-Disposition: form-data; name=%q\n\n", item.Key))
+			body.WriteString(item.Value + "\n")
+		}
+	}
+	body.WriteString("--" + multipartPreviewBoundary + "--")
+	return body.String()
+}
+
+// httpFileEscape URL-encodes a query or form value, leaving {{variables}} intact
+func httpFileEscape(value string) string {
+	var escaped strings.Builder
+	for value != "" {
+		start := strings.Index(value, "{{")
+		end := strings.Index(value, "}}")
+		if start < 0 || end < start {
+			escaped.WriteString(url.QueryEscape(value))
+			break
+		}
+		escaped.WriteString(url.QueryEscape(value[:start]))
+		escaped.WriteString(value[start : end+2])
+		value = value[end+2:]
+	}
+	return escaped.String()
+}
@@ -0,0 +1,471 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"rikuest/internal/config"
+	"rikuest/internal/database"
+	"rikuest/internal/models"
+)
+
+// HAR 1.2 document types (http://www.softwareishard.com/blog/har-12-spec/)
+type HARFile struct {
+	Log HARLog `json:"log"`
+}
+
+type HARLog struct {
+	Version string     `json:"version"`
+	Creator HARCreator `json:"creator"`
+	Entries []HAREntry `json:"entries"`
+}
+
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type HAREntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HARTimings  `json:"timings"`
+	Comment         string      `json:"comment,omitempty"`
+}
+
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	PostData    *HARPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type HARResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	Content     HARContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type HARNameValue struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	FileName string `json:"fileName,omitempty"`
+}
+
+type HARPostData struct {
+	MimeType string         `json:"mimeType"`
+	Text     string         `json:"text,omitempty"`
+	Params   []HARNameValue `json:"params,omitempty"`
+}
+
+type HARContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type HARTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// HARService converts between HAR archives and projects
+type HARService struct {
+	db     *database.DB
+	format *FormatService
+}
+
+func NewHARService(db *database.DB, format *FormatService) *HARService {
+	return &HARService{db: db, format: format}
+}
+
+// ImportHAR turns every entry of a HAR archive into a request filed by host and path under
+// folderID, keeping the recorded response as history
+func (s *HARService) ImportHAR(projectID int, folderID *int, data []byte) (*models.ImportResult, error) {
+	var file HARFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid HAR file: %w", err)
+	}
+
+	if _, err := s.db.GetProject(projectID); err != nil {
+		return nil, fmt.Errorf("project not found: %w", err)
+	}
+
+	tree, err := newCaptureTree(s.db, projectID, folderID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.ImportResult{ProjectID: projectID, Warnings: []string{}}
+
+	for i, entry := range file.Log.Entries {
+		request, err := requestFromHAR(entry.Request)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("entry %d (%s %s): %v", i+1, entry.Request.Method, entry.Request.URL, err))
+			continue
+		}
+
+		history := &models.RequestHistory{
+			Request:  request,
+			Response: responseFromHAR(entry),
+		}
+		history.Response.RawRequest = s.format.BuildRawRequest(request)
+		if startedAt, err := time.Parse(time.RFC3339Nano, entry.StartedDateTime); err == nil {
+			history.ExecutedAt = startedAt
+		}
+
+		if err := tree.store(request, history); err != nil {
+			return nil, fmt.Errorf("failed to import entry %d: %w", i+1, err)
+		}
+	}
+
+	result.Folders = tree.createdFolders
+	result.Requests = tree.createdRequests
+	result.History = tree.createdHistory
+	return result, nil
+}
+
+// ExportProject exports every request of a project with its latest response
+func (s *HARService) ExportProject(projectID int) (*HARFile, error) {
+	requests, err := s.db.GetRequests(projectID)
+	if err != nil {
+		return nil, err
+	}
+	return s.exportRequests(requests)
+}
+
+// ExportFolder exports the requests of a folder and all of its subfolders with their latest response
+func (s *HARService) ExportFolder(folderID int) (*HARFile, error) {
+	folder, err := s.db.GetFolder(folderID)
+	if err != nil {
+		return nil, fmt.Errorf("folder not found: %w", err)
+	}
+
+	folders, err := s.db.GetFolders(folder.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	requests, err := s.db.GetRequests(folder.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	subtree := folderSubtree(folders, folderID)
+	var selected []models.Request
+	for _, request := range requests {
+		if request.FolderID != nil && subtree[*request.FolderID] {
+			selected = append(selected, request)
+		}
+	}
+
+	return s.exportRequests(selected)
+}
+
+// ExportHistory exports the given history entries, in the order requested
+func (s *HARService) ExportHistory(historyIDs []int) (*HARFile, error) {
+	file := newHARFile()
+	requests := make(map[int]*models.Request)
+
+	for _, historyID := range historyIDs {
+		history, err := s.db.GetHistoryEntry(historyID)
+		if err != nil {
+			return nil, fmt.Errorf("history item %d not found: %w", historyID, err)
+		}
+
+		request, ok := requests[history.RequestID]
+		if !ok {
+			request, err = s.db.GetRequest(history.RequestID)
+			if err != nil {
+				return nil, err
+			}
+			requests[history.RequestID] = request
+		}
+
+		file.Log.Entries = append(file.Log.Entries, harEntry(request, history))
+	}
+
+	return file, nil
+}
+
+func (s *HARService) exportRequests(requests []models.Request) (*HARFile, error) {
+	file := newHARFile()
+
+	for i := range requests {
+		request := &requests[i]
+		history, err := s.db.GetLatestRequestHistory(request.ID, 1)
+		if err != nil {
+			return nil, err
+		}
+
+		var latest *models.RequestHistory
+		if len(history) > 0 {
+			latest = &history[0]
+		}
+		file.Log.Entries = append(file.Log.Entries, harEntry(request, latest))
+	}
+
+	return file, nil
+}
+
+func newHARFile() *HARFile {
+	return &HARFile{
+		Log: HARLog{
+			Version: "1.2",
+			Creator: HARCreator{Name: "Rikuest", Version: config.Version()},
+			Entries: []HAREntry{},
+		},
+	}
+}
+
+// harEntry builds a HAR entry for a request and, when available, one of its executions
+func harEntry(request *models.Request, history *models.RequestHistory) HAREntry {
+	entry := HAREntry{
+		Request: harRequest(request),
+		Response: HARResponse{
+			HTTPVersion: "HTTP/1.1",
+			Cookies:     []HARNameValue{},
+			Headers:     []HARNameValue{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+	}
+
+	if history == nil {
+		entry.StartedDateTime = request.UpdatedAt.Format(time.RFC3339Nano)
+		entry.Comment = "Request has not been executed"
+		entry.Timings = HARTimings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1}
+		return entry
+	}
+
+	response := history.Response
+	duration := float64(response.Duration)
+
+	entry.StartedDateTime = history.ExecutedAt.Format(time.RFC3339Nano)
+	entry.Time = duration
+	// Rikuest measures the whole exchange, which is reported as time spent waiting
+	entry.Timings = HARTimings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1, Wait: duration}
+
+	entry.Response.Status = response.Status
+	entry.Response.StatusText = strings.TrimSpace(strings.TrimPrefix(response.StatusText, strconv.Itoa(response.Status)))
+	entry.Response.Headers = harNameValues(response.Headers)
+	entry.Response.BodySize = int(response.Size)
+	entry.Response.Content = HARContent{
+		Size:     int64(len(response.Body)),
+		MimeType: headerValue(response.Headers, "Content-Type"),
+		Text:     response.Body,
+	}
+	if location := headerValue(response.Headers, "Location"); location != "" {
+		entry.Response.RedirectURL = location
+	}
+	if response.Status == 0 {
+		entry.Comment = response.StatusText + ": " + response.Body
+	}
+
+	return entry
+}
+
+func harRequest(request *models.Request) HARRequest {
+	harReq := HARRequest{
+		Method:      request.Method,
+		URL:         request.URL,
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     []HARNameValue{},
+		Headers:     harNameValues(request.Headers),
+		QueryString: []HARNameValue{},
+		HeadersSize: -1,
+		BodySize:    0,
+	}
+
+	if parsedURL, err := url.Parse(request.URL); err == nil {
+		query := parsedURL.Query()
+		for _, param := range request.QueryParams {
+			if param.Enabled && param.Key != "" {
+				query.Add(param.Key, param.Value)
+			}
+		}
+		parsedURL.RawQuery = query.Encode()
+		harReq.URL = parsedURL.String()
+
+		keys := make([]string, 0, len(query))
+		for key := range query {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			for _, value := range query[key] {
+				harReq.QueryString = append(harReq.QueryString, HARNameValue{Name: key, Value: value})
+			}
+		}
+	}
+
+	switch request.AuthType {
+	case "bearer":
+		if request.BearerToken != "" {
+			harReq.Headers = append(harReq.Headers, HARNameValue{Name: "Authorization", Value: "Bearer " + request.BearerToken})
+		}
+	case "basic":
+		if request.BasicAuth.Username != "" || request.BasicAuth.Password != "" {
+			auth := base64.StdEncoding.EncodeToString([]byte(request.BasicAuth.Username + ":" + request.BasicAuth.Password))
+			harReq.Headers = append(harReq.Headers, HARNameValue{Name: "Authorization", Value: "Basic " + auth})
+		}
+	}
+
+	if request.BodyType == "multipart" && len(request.FormData) > 0 {
+		body, contentType, _ := buildMultipartBody(request.FormData, multipartPreviewBoundary, nil)
+		postData := &HARPostData{MimeType: contentType, Text: string(body)}
+		for _, item := range request.FormData {
+			if item.Key == "" {
+				continue
+			}
+			if item.Type == "file" {
+				postData.Params = append(postData.Params, HARNameValue{Name: item.Key, FileName: item.Value})
+			} else {
+				postData.Params = append(postData.Params, HARNameValue{Name: item.Key, Value: item.Value})
+			}
+		}
+		harReq.PostData = postData
+		harReq.BodySize = len(postData.Text)
+	} else if request.BodyType == "form" && len(request.FormData) > 0 {
+		formValues := url.Values{}
+		postData := &HARPostData{MimeType: "application/x-www-form-urlencoded"}
+		for _, item := range request.FormData {
+			if item.Key != "" {
+				formValues.Add(item.Key, item.Value)
+				postData.Params = append(postData.Params, HARNameValue{Name: item.Key, Value: item.Value})
+			}
+		}
+		postData.Text = formValues.Encode()
+		harReq.PostData = postData
+		harReq.BodySize = len(postData.Text)
+	} else if request.Body != "" {
+		mimeType := headerValue(request.Headers, "Content-Type")
+		if mimeType == "" && request.BodyType == "json" {
+			mimeType = "application/json"
+		} else if mimeType == "" {
+			mimeType = "text/plain"
+		}
+		harReq.PostData = &HARPostData{MimeType: mimeType, Text: request.Body}
+		harReq.BodySize = len(request.Body)
+	}
+
+	return harReq
+}
+
+// requestFromHAR converts a HAR request into a Rikuest request
+func requestFromHAR(harReq HARRequest) (*models.Request, error) {
+	var body string
+	if harReq.PostData != nil {
+		body = harReq.PostData.Text
+		if body == "" && len(harReq.PostData.Params) > 0 {
+			values := url.Values{}
+			for _, param := range harReq.PostData.Params {
+				values.Add(param.Name, param.Value)
+			}
+			body = values.Encode()
+		}
+	}
+
+	r, err := http.NewRequest(harReq.Method, harReq.URL, strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for _, header := range harReq.Headers {
+		// HTTP/2 captures include pseudo-headers such as :authority
+		if strings.HasPrefix(header.Name, ":") {
+			continue
+		}
+		r.Header.Add(header.Name, header.Value)
+	}
+	if harReq.PostData != nil && r.Header.Get("Content-Type") == "" {
+		r.Header.Set("Content-Type", harReq.PostData.MimeType)
+	}
+
+	return capturedRequest(r, []byte(body)), nil
+}
+
+func responseFromHAR(entry HAREntry) models.RequestResponse {
+	harResp := entry.Response
+
+	headers := make(map[string]string)
+	for _, header := range harResp.Headers {
+		if strings.HasPrefix(header.Name, ":") {
+			continue
+		}
+		key := http.CanonicalHeaderKey(header.Name)
+		if existing, ok := headers[key]; ok {
+			headers[key] = existing + ", " + header.Value
+		} else {
+			headers[key] = header.Value
+		}
+	}
+
+	body := harResp.Content.Text
+	if harResp.Content.Encoding == "base64" {
+		if decoded, err := base64.StdEncoding.DecodeString(body); err == nil {
+			body = string(decoded)
+		}
+	}
+
+	statusText := harResp.StatusText
+	if statusText == "" {
+		statusText = http.StatusText(harResp.Status)
+	}
+
+	size := harResp.Content.Size
+	if size <= 0 {
+		size = int64(len(body))
+	}
+
+	return models.RequestResponse{
+		Status:     harResp.Status,
+		StatusText: strings.TrimSpace(fmt.Sprintf("%d %s", harResp.Status, statusText)),
+		Headers:    headers,
+		Body:       body,
+		Duration:   int64(entry.Time),
+		Size:       size,
+	}
+}
+
+func harNameValues(values map[string]string) []HARNameValue {
+	pairs := make([]HARNameValue, 0, len(values))
+	for _, key := range sortedKeys(values) {
+		pairs = append(pairs, HARNameValue{Name: key, Value: values[key]})
+	}
+	return pairs
+}
+
+// headerValue looks a header up case-insensitively
+func headerValue(headers map[string]string, name string) string {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,245 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"rikuest/internal/database"
+	"rikuest/internal/models"
+)
+
+const (
+	historyPruneEvery      = time.Hour
+	historyDefaultPageSize = 50
+	historyMaxPageSize     = 500
+)
+
+// HistoryService lists past executions of requests, one request or a whole project at a time,
+// replays and compares them, and prunes them in the background according to the configured
+// retention
+type HistoryService struct {
+	db       *database.DB
+	requests *RequestService
+	mutex    sync.Mutex
+	stop     chan struct{}
+}
+
+func NewHistoryService(db *database.DB, files FileAccess) *HistoryService {
+	return &HistoryService{db: db, requests: NewRequestService(db, files)}
+}
+
+// Start prunes history now and then every hour, until Stop is called
+func (s *HistoryService) Start() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.stop != nil {
+		return
+	}
+	stop := make(chan struct{})
+	s.stop = stop
+
+	go func() {
+		ticker := time.NewTicker(historyPruneEvery)
+		defer ticker.Stop()
+
+		s.prune()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				s.prune()
+			}
+		}
+	}()
+}
+
+func (s *HistoryService) Stop() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+}
+
+func (s *HistoryService) prune() {
+	config := s.GetConfig()
+	if config.MaxEntries == 0 && config.MaxAgeDays == 0 && config.MaxSizeMB == 0 {
+		return
+	}
+	maxAge := time.Duration(config.MaxAgeDays) * 24 * time.Hour
+	maxSize := int64(config.MaxSizeMB) * 1024 * 1024
+	if _, err := s.db.PruneHistory(config.MaxEntries, maxAge, maxSize); err != nil {
+		log.Printf("Failed to prune request history: %v", err)
+	}
+}
+
+// GetConfig returns the history retention, which keeps everything unless set
+func (s *HistoryService) GetConfig() models.HistoryConfig {
+	var config models.HistoryConfig
+	settings := map[string]*int{
+		"history_max_entries":  &config.MaxEntries,
+		"history_max_age_days": &config.MaxAgeDays,
+		"history_max_size_mb":  &config.MaxSizeMB,
+	}
+	for key, target := range settings {
+		if value, err := s.db.GetSetting(key); err == nil && value != "" {
+			if number, err := strconv.Atoi(value); err == nil && number > 0 {
+				*target = number
+			}
+		}
+	}
+	return config
+}
+
+// SetConfig saves the history retention and prunes history to it right away
+func (s *HistoryService) SetConfig(config models.HistoryConfig) error {
+	if config.MaxEntries < 0 || config.MaxAgeDays < 0 || config.MaxSizeMB < 0 {
+		return fmt.Errorf("history limits cannot be negative")
+	}
+	settings := map[string]int{
+		"history_max_entries":  config.MaxEntries,
+		"history_max_age_days": config.MaxAgeDays,
+		"history_max_size_mb":  config.MaxSizeMB,
+	}
+	for key, value := range settings {
+		if err := s.db.SetSetting(key, strconv.Itoa(value)); err != nil {
+			return err
+		}
+	}
+	s.prune()
+	return nil
+}
+
+// GetRequestHistory returns a page of the executions of a request, newest first
+func (s *HistoryService) GetRequestHistory(requestID int, filter models.HistoryFilter) (*models.HistoryPage, error) {
+	if _, err := s.db.GetRequest(requestID); err != nil {
+		return nil, fmt.Errorf("request not found: %w", err)
+	}
+	return s.db.FindRequestHistory(requestID, pagedHistoryFilter(filter))
+}
+
+// GetProjectHistory returns a page of the executions of every request in a project, newest
+// first
+func (s *HistoryService) GetProjectHistory(projectID int, filter models.HistoryFilter) (*models.HistoryPage, error) {
+	if _, err := s.db.GetProject(projectID); err != nil {
+		return nil, fmt.Errorf("project not found: %w", err)
+	}
+	return s.db.FindProjectHistory(projectID, pagedHistoryFilter(filter))
+}
+
+// pagedHistoryFilter defaults the page size to 50 and caps it at 500
+func pagedHistoryFilter(filter models.HistoryFilter) models.HistoryFilter {
+	if filter.Limit <= 0 {
+		filter.Limit = historyDefaultPageSize
+	}
+	if filter.Limit > historyMaxPageSize {
+		filter.Limit = historyMaxPageSize
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	return filter
+}
+
+// Replay sends the request of a history entry again exactly as it was sent, without applying
+// later edits or the current environment, and records the response as a new history entry
+func (s *HistoryService) Replay(historyID int) (*models.RequestHistory, error) {
+	entry, err := s.db.GetHistoryEntry(historyID)
+	if err != nil {
+		return nil, fmt.Errorf("history entry not found: %w", err)
+	}
+	if entry.Request == nil {
+		return nil, fmt.Errorf("history entry %d was recorded without the request that was sent and cannot be replayed", historyID)
+	}
+
+	response, err := s.requests.executeHTTPRequest(entry.Request)
+	if err != nil {
+		return nil, err
+	}
+	replay := &models.RequestHistory{RequestID: entry.RequestID, Request: entry.Request, Response: *response}
+	if err := s.db.SaveRequestHistory(replay); err != nil {
+		return nil, err
+	}
+	return replay, nil
+}
+
+// Diff compares the responses of two history entries. ignorePaths are left out on top of the
+// configured ones.
+func (s *HistoryService) Diff(historyID, againstID int, ignorePaths []string) (*ResponseDiff, error) {
+	before, err := s.db.GetHistoryEntry(historyID)
+	if err != nil {
+		return nil, fmt.Errorf("history entry %d not found: %w", historyID, err)
+	}
+	after, err := s.db.GetHistoryEntry(againstID)
+	if err != nil {
+		return nil, fmt.Errorf("history entry %d not found: %w", againstID, err)
+	}
+	return s.diff(before, after, ignorePaths)
+}
+
+// DiffLive replays a history entry and compares its response with the live one
+func (s *HistoryService) DiffLive(historyID int, ignorePaths []string) (*ResponseDiff, error) {
+	before, err := s.db.GetHistoryEntry(historyID)
+	if err != nil {
+		return nil, fmt.Errorf("history entry %d not found: %w", historyID, err)
+	}
+	after, err := s.Replay(historyID)
+	if err != nil {
+		return nil, err
+	}
+	return s.diff(before, after, ignorePaths)
+}
+
+func (s *HistoryService) diff(before, after *models.RequestHistory, ignorePaths []string) (*ResponseDiff, error) {
+	config := s.GetDiffConfig()
+	config.IgnorePaths = append(config.IgnorePaths, ignorePaths...)
+	diff, err := diffResponses(before.Response, after.Response, config)
+	if err != nil {
+		return nil, err
+	}
+	diff.From = before.ID
+	diff.To = after.ID
+	return diff, nil
+}
+
+// GetDiffConfig returns what response diffs leave out, falling back to the Date header
+func (s *HistoryService) GetDiffConfig() models.ResponseDiffConfig {
+	config := models.ResponseDiffConfig{IgnoreHeaders: []string{"Date"}, IgnorePaths: []string{}}
+	if value, err := s.db.GetSetting("diff_ignore_headers"); err == nil && value != "" {
+		json.Unmarshal([]byte(value), &config.IgnoreHeaders)
+	}
+	if value, err := s.db.GetSetting("diff_ignore_paths"); err == nil && value != "" {
+		json.Unmarshal([]byte(value), &config.IgnorePaths)
+	}
+	return config
+}
+
+// SetDiffConfig saves what response diffs leave out, after checking the ignore paths
+func (s *HistoryService) SetDiffConfig(config models.ResponseDiffConfig) error {
+	if config.IgnoreHeaders == nil {
+		config.IgnoreHeaders = []string{}
+	}
+	if config.IgnorePaths == nil {
+		config.IgnorePaths = []string{}
+	}
+	for _, path := range config.IgnorePaths {
+		if _, err := parseJSONPath(path); err != nil {
+			return err
+		}
+	}
+
+	headers, _ := json.Marshal(config.IgnoreHeaders)
+	paths, _ := json.Marshal(config.IgnorePaths)
+	if err := s.db.SetSetting("diff_ignore_headers", string(headers)); err != nil {
+		return err
+	}
+	return s.db.SetSetting("diff_ignore_paths", string(paths))
+}
@@ -0,0 +1,465 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"regexp"
+	"sort"
+	"strings"
+
+	"rikuest/internal/database"
+	"rikuest/internal/models"
+)
+
+var (
+	httpFileRequestLine = regexp.MustCompile(`^(GET|POST|PUT|PATCH|DELETE|HEAD|OPTIONS|TRACE|CONNECT)\s+(\S.*?)(?:\s+HTTP/[\d.]+)?$`)
+	httpFileVariable    = regexp.MustCompile(`^@([A-Za-z0-9_.\-]+)\s*=\s*(.*)$`)
+	httpFileMetadata    = regexp.MustCompile(`^(?:#|//)\s*@name\s+(.+)$`)
+	httpFileDynamic     = regexp.MustCompile(`\{\{\s*\$[A-Za-z]+[^}]*\}\}`)
+	httpFileRequestVar  = regexp.MustCompile(`\{\{\s*[A-Za-z0-9_\-]+\.(?:request|response)\.[^}]*\}\}`)
+)
+
+// HTTPFileService converts between projects and .http/.rest request files
+type HTTPFileService struct {
+	db     *database.DB
+	format *FormatService
+}
+
+func NewHTTPFileService(db *database.DB, format *FormatService) *HTTPFileService {
+	return &HTTPFileService{db: db, format: format}
+}
+
+// httpFileRequest is a request block read from a file before it is converted
+type httpFileRequest struct {
+	name    string
+	method  string
+	url     string
+	headers [][2]string
+	body    []string
+}
+
+// Import reads the requests of a .http file into a project, or into folderID when set.
+// File variables become an environment with the given name.
+func (s *HTTPFileService) Import(projectID int, folderID *int, environmentName string, data []byte) (*models.ImportResult, error) {
+	if _, err := s.db.GetProject(projectID); err != nil {
+		return nil, fmt.Errorf("project not found: %w", err)
+	}
+	if folderID != nil {
+		folder, err := s.db.GetFolder(*folderID)
+		if err != nil || folder.ProjectID != projectID {
+			return nil, fmt.Errorf("folder not found in project")
+		}
+	}
+
+	result := &models.ImportResult{ProjectID: projectID, Warnings: []string{}}
+	blocks, variables := parseHTTPFile(string(data), result)
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("the file does not contain any request")
+	}
+
+	for _, block := range blocks {
+		request := convertHTTPFileRequest(block, result)
+		request.ProjectID = projectID
+		request.FolderID = folderID
+		if err := s.db.CreateRequest(request); err != nil {
+			return nil, fmt.Errorf("failed to create request %q: %w", request.Name, err)
+		}
+		result.Requests++
+	}
+
+	if len(variables) > 0 {
+		if environmentName == "" {
+			environmentName = "HTTP file"
+		}
+		environment := &models.Environment{
+			ProjectID: projectID,
+			Name:      environmentName,
+			Variables: variables,
+		}
+
+		active, err := s.db.GetActiveEnvironment(projectID)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.db.CreateEnvironment(environment); err != nil {
+			return nil, fmt.Errorf("failed to create environment: %w", err)
+		}
+		if active == nil {
+			if err := s.db.SetActiveEnvironment(projectID, &environment.ID); err != nil {
+				return nil, err
+			}
+		}
+		result.Environments++
+	}
+
+	return result, nil
+}
+
+// parseHTTPFile splits a file into request blocks separated by ### lines and collects
+// the @name = value variable definitions
+func parseHTTPFile(content string, result *models.ImportResult) ([]httpFileRequest, []models.Variable) {
+	var (
+		blocks    []httpFileRequest
+		variables []models.Variable
+		seen      = make(map[string]int)
+		current   httpFileRequest
+		inHeaders bool
+		inScript  bool
+	)
+
+	finish := func() {
+		if current.method != "" {
+			// Trailing blank lines separate requests and are not part of the body
+			for len(current.body) > 0 && strings.TrimSpace(current.body[len(current.body)-1]) == "" {
+				current.body = current.body[:len(current.body)-1]
+			}
+			blocks = append(blocks, current)
+		}
+		current = httpFileRequest{}
+		inHeaders = false
+	}
+
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "###") {
+			finish()
+			current.name = strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+			continue
+		}
+
+		// JetBrains response handler scripts run after the request and have no equivalent
+		if inScript {
+			if strings.Contains(trimmed, "%}") {
+				inScript = false
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "> ") || strings.HasPrefix(trimmed, ">> ") || strings.HasPrefix(trimmed, "<> ") {
+			warnOnce(result, "response handlers and response references are not supported and were skipped")
+			if strings.Contains(trimmed, "{%") && !strings.Contains(trimmed, "%}") {
+				inScript = true
+			}
+			continue
+		}
+
+		// Before the request line: comments, metadata and variables
+		if current.method == "" {
+			if match := httpFileMetadata.FindStringSubmatch(trimmed); match != nil {
+				current.name = strings.TrimSpace(match[1])
+				continue
+			}
+			if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "//") {
+				continue
+			}
+			if match := httpFileVariable.FindStringSubmatch(trimmed); match != nil {
+				variable := models.Variable{Key: match[1], Value: strings.TrimSpace(match[2]), Enabled: true}
+				if index, ok := seen[variable.Key]; ok {
+					variables[index] = variable
+				} else {
+					seen[variable.Key] = len(variables)
+					variables = append(variables, variable)
+				}
+				continue
+			}
+
+			if match := httpFileRequestLine.FindStringSubmatch(trimmed); match != nil {
+				current.method, current.url = match[1], match[2]
+			} else if isHTTPFileURL(trimmed) {
+				// A line with only a URL is a GET request
+				current.method, current.url = "GET", strings.Fields(trimmed)[0]
+			} else {
+				warnOnce(result, fmt.Sprintf("unrecognized line skipped: %s", trimmed))
+				continue
+			}
+			inHeaders = true
+			continue
+		}
+
+		if inHeaders {
+			switch {
+			case trimmed == "":
+				inHeaders = false
+			case strings.HasPrefix(trimmed, "?") || strings.HasPrefix(trimmed, "&"):
+				// Query parameters may continue on the following lines
+				current.url += trimmed
+			case strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "//"):
+			default:
+				name, value, found := strings.Cut(trimmed, ":")
+				if found {
+					current.headers = append(current.headers, [2]string{strings.TrimSpace(name), strings.TrimSpace(value)})
+				}
+			}
+			continue
+		}
+
+		current.body = append(current.body, line)
+	}
+	finish()
+
+	return blocks, variables
+}
+
+// convertHTTPFileRequest maps a parsed block to a request, recording anything it cannot keep
+func convertHTTPFileRequest(block httpFileRequest, result *models.ImportResult) *models.Request {
+	request := &models.Request{
+		Method:      block.method,
+		Headers:     make(map[string]string),
+		QueryParams: []models.QueryParam{},
+		AuthType:    "none",
+		BodyType:    "none",
+		FormData:    []models.FormData{},
+	}
+
+	base, rawQuery, hasQuery := strings.Cut(block.url, "?")
+	request.URL = base
+	if hasQuery {
+		request.QueryParams = parseRawQuery(rawQuery)
+	}
+
+	for _, header := range block.headers {
+		name, value := header[0], header[1]
+		if strings.EqualFold(name, "Authorization") && applyHTTPFileAuthorization(request, value) {
+			continue
+		}
+		request.Headers[name] = value
+	}
+
+	if block.name != "" {
+		request.Name = block.name
+	} else {
+		request.Name = requestNameFromURL(request)
+	}
+
+	body := strings.Join(block.body, "\n")
+	contentType := strings.ToLower(headerValue(request.Headers, "Content-Type"))
+	switch {
+	case strings.TrimSpace(body) == "":
+	case strings.HasPrefix(strings.TrimSpace(body), "< "):
+		warnOnce(result, fmt.Sprintf("%s: bodies read from files are not supported and were skipped", request.Name))
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		// Form bodies may be split over several lines starting with &
+		var joined strings.Builder
+		for _, line := range block.body {
+			joined.WriteString(strings.TrimSpace(line))
+		}
+		request.BodyType = "form"
+		request.FormData = curlFormData(joined.String())
+	case strings.HasPrefix(contentType, "multipart/form-data"):
+		formData, ok := parseHTTPFileMultipart(headerValue(request.Headers, "Content-Type"), body)
+		if !ok {
+			warnOnce(result, fmt.Sprintf("%s: the multipart body could not be parsed and was imported as text", request.Name))
+			request.BodyType = "text"
+			request.Body = body
+			break
+		}
+		for i, item := range formData {
+			if item.Type == "file" && item.Value != "" {
+				warnOnce(result, fmt.Sprintf("%s: file field %q refers to a local file, upload it again", request.Name, item.Key))
+				formData[i].Value = ""
+			}
+		}
+		// Execution writes its own boundary
+		for name := range request.Headers {
+			if strings.EqualFold(name, "Content-Type") {
+				delete(request.Headers, name)
+			}
+		}
+		request.BodyType = "multipart"
+		request.FormData = formData
+	case strings.Contains(contentType, "json") || (contentType == "" && json.Valid([]byte(body))):
+		request.BodyType = "json"
+		request.Body = body
+	default:
+		request.BodyType = "text"
+		request.Body = body
+	}
+
+	for _, text := range append([]string{block.url, body}, headerValues(request.Headers)...) {
+		if httpFileDynamic.MatchString(text) {
+			warnOnce(result, fmt.Sprintf("%s: system variables such as {{$guid}} are not supported", request.Name))
+		}
+		if httpFileRequestVar.MatchString(text) {
+			warnOnce(result, fmt.Sprintf("%s: request variables referencing other responses are not supported", request.Name))
+		}
+	}
+
+	return request
+}
+
+// parseHTTPFileMultipart reads the parts of a multipart body written by hand, where a part
+// whose content is "< path" sends a local file
+func parseHTTPFileMultipart(contentType string, body string) ([]models.FormData, bool) {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil || params["boundary"] == "" {
+		return nil, false
+	}
+	delimiter := "--" + params["boundary"]
+
+	formData := []models.FormData{}
+	for _, part := range strings.Split(body, delimiter)[1:] {
+		if strings.HasPrefix(part, "--") {
+			break
+		}
+
+		headers, content, found := strings.Cut(strings.TrimPrefix(part, "\n"), "\n\n")
+		if !found {
+			return nil, false
+		}
+
+		var name string
+		for _, line := range strings.Split(headers, "\n") {
+			key, value, _ := strings.Cut(line, ":")
+			if !strings.EqualFold(strings.TrimSpace(key), "Content-Disposition") {
+				continue
+			}
+			if _, disposition, err := mime.ParseMediaType(strings.TrimSpace(value)); err == nil {
+				name = disposition["name"]
+			}
+		}
+		if name == "" {
+			return nil, false
+		}
+
+		content = strings.TrimSuffix(content, "\n")
+		if path, isFile := strings.CutPrefix(strings.TrimSpace(content), "< "); isFile {
+			formData = append(formData, models.FormData{Key: name, Value: strings.TrimSpace(path), Type: "file"})
+		} else {
+			formData = append(formData, models.FormData{Key: name, Value: content})
+		}
+	}
+	return formData, len(formData) > 0
+}
+
+// applyHTTPFileAuthorization extends applyAuthorizationHeader with the plain "Basic user:pass"
+// and "Basic user pass" forms accepted by the REST Client
+func applyHTTPFileAuthorization(request *models.Request, value string) bool {
+	if applyAuthorizationHeader(request, value) {
+		return true
+	}
+
+	scheme, credentials, _ := strings.Cut(strings.TrimSpace(value), " ")
+	if !strings.EqualFold(scheme, "basic") {
+		return false
+	}
+	credentials = strings.TrimSpace(credentials)
+	username, password, found := strings.Cut(credentials, ":")
+	if !found {
+		username, password, found = strings.Cut(credentials, " ")
+	}
+	if !found {
+		return false
+	}
+	request.AuthType = "basic"
+	request.BasicAuth = models.BasicAuth{Username: username, Password: strings.TrimSpace(password)}
+	return true
+}
+
+// ExportProject writes every request of a project, preceded by the variables of its active environment
+func (s *HTTPFileService) ExportProject(projectID int) (string, error) {
+	project, err := s.db.GetProject(projectID)
+	if err != nil {
+		return "", fmt.Errorf("project not found: %w", err)
+	}
+	return s.export(project.ID, nil, "# "+project.Name)
+}
+
+// ExportFolder writes the requests of a folder and its subfolders
+func (s *HTTPFileService) ExportFolder(folderID int) (string, error) {
+	folder, err := s.db.GetFolder(folderID)
+	if err != nil {
+		return "", fmt.Errorf("folder not found: %w", err)
+	}
+	return s.export(folder.ProjectID, &folder.ID, "# "+folder.Name)
+}
+
+func (s *HTTPFileService) export(projectID int, rootID *int, title string) (string, error) {
+	folders, err := s.db.GetFolders(projectID)
+	if err != nil {
+		return "", err
+	}
+	requests, err := s.db.GetRequests(projectID)
+	if err != nil {
+		return "", err
+	}
+	environment, err := s.db.GetActiveEnvironment(projectID)
+	if err != nil {
+		return "", err
+	}
+
+	var file strings.Builder
+	file.WriteString(title + "\n")
+
+	if environment != nil {
+		file.WriteString("\n")
+		for _, variable := range environment.Variables {
+			if variable.Enabled && variable.Key != "" {
+				file.WriteString("@" + variable.Key + " = " + variable.Value + "\n")
+			}
+		}
+	}
+
+	s.writeLevel(&file, rootID, "", folders, requests)
+	return file.String(), nil
+}
+
+// writeLevel writes one level of the folder tree in position order, naming each folder in a comment
+func (s *HTTPFileService) writeLevel(file *strings.Builder, parentID *int, path string, folders []models.Folder, requests []models.Request) {
+	type positioned struct {
+		position int
+		folder   *models.Folder
+		request  *models.Request
+	}
+	var entries []positioned
+
+	for i := range folders {
+		if sameFolder(folders[i].ParentID, parentID) {
+			entries = append(entries, positioned{position: folders[i].Position, folder: &folders[i]})
+		}
+	}
+	for i := range requests {
+		if sameFolder(requests[i].FolderID, parentID) {
+			entries = append(entries, positioned{position: requests[i].Position, request: &requests[i]})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].position < entries[j].position })
+
+	for _, entry := range entries {
+		if entry.request != nil {
+			file.WriteString("\n" + s.format.BuildHTTPFileRequest(entry.request))
+			continue
+		}
+
+		folderPath := entry.folder.Name
+		if path != "" {
+			folderPath = path + " / " + entry.folder.Name
+		}
+		// A separator without a request names the folder and is ignored by clients
+		file.WriteString("\n### " + folderPath + "\n")
+		s.writeLevel(file, &entry.folder.ID, folderPath, folders, requests)
+	}
+}
+
+func isHTTPFileURL(line string) bool {
+	return strings.HasPrefix(line, "http://") || strings.HasPrefix(line, "https://") ||
+		strings.HasPrefix(line, "{{") || strings.HasPrefix(line, "/")
+}
+
+func headerValues(headers map[string]string) []string {
+	values := make([]string, 0, len(headers))
+	for _, value := range headers {
+		values = append(values, value)
+	}
+	return values
+}
+
+func warnOnce(result *models.ImportResult, warning string) {
+	for _, existing := range result.Warnings {
+		if existing == warning {
+			return
+		}
+	}
+	result.Warnings = append(result.Warnings, warning)
+}
@@ -0,0 +1,466 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"rikuest/internal/database"
+	"rikuest/internal/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+// insomniaVariablePattern matches Insomnia's {{ _.name }} and legacy {{ name }} variables
+var insomniaVariablePattern = regexp.MustCompile(`\{\{\s*(?:_\.)?([A-Za-z0-9_.\-]+)\s*\}\}`)
+
+// Insomnia v4 export document types
+type InsomniaExport struct {
+	Type      string             `json:"_type"`
+	Format    int                `json:"__export_format"`
+	Resources []InsomniaResource `json:"resources"`
+}
+
+type InsomniaResource struct {
+	ID             string                 `json:"_id"`
+	Type           string                 `json:"_type"`
+	ParentID       string                 `json:"parentId"`
+	Name           string                 `json:"name"`
+	Description    string                 `json:"description"`
+	MetaSortKey    float64                `json:"metaSortKey"`
+	Method         string                 `json:"method"`
+	URL            string                 `json:"url"`
+	Body           InsomniaBody           `json:"body"`
+	Parameters     []InsomniaPair         `json:"parameters"`
+	Headers        []InsomniaPair         `json:"headers"`
+	Authentication map[string]interface{} `json:"authentication"`
+	Data           map[string]interface{} `json:"data"`
+	Environment    map[string]interface{} `json:"environment"`
+}
+
+type InsomniaBody struct {
+	MimeType string         `json:"mimeType"`
+	Text     string         `json:"text"`
+	Params   []InsomniaPair `json:"params"`
+}
+
+type InsomniaPair struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Disabled bool   `json:"disabled"`
+	Type     string `json:"type"`
+	FileName string `json:"fileName"`
+}
+
+// InsomniaService imports Insomnia v4 exports
+type InsomniaService struct {
+	db *database.DB
+}
+
+func NewInsomniaService(db *database.DB) *InsomniaService {
+	return &InsomniaService{db: db}
+}
+
+// insomniaImporter carries the state of a single workspace import
+type insomniaImporter struct {
+	db       *database.DB
+	children map[string][]InsomniaResource
+	result   *models.ImportResult
+}
+
+// Import creates one project per workspace of an Insomnia v4 export given as JSON or YAML
+func (s *InsomniaService) Import(data []byte) ([]*models.ImportResult, error) {
+	export, err := parseInsomniaExport(data)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[string][]InsomniaResource)
+	var workspaces []InsomniaResource
+	skipped := make(map[string]int)
+	for _, resource := range export.Resources {
+		switch resource.Type {
+		case "workspace":
+			workspaces = append(workspaces, resource)
+		case "request", "request_group", "environment":
+			children[resource.ParentID] = append(children[resource.ParentID], resource)
+		case "cookie_jar":
+		default:
+			skipped[resource.Type]++
+		}
+	}
+	for parentID := range children {
+		siblings := children[parentID]
+		sort.SliceStable(siblings, func(i, j int) bool { return siblings[i].MetaSortKey < siblings[j].MetaSortKey })
+	}
+
+	if len(workspaces) == 0 {
+		return nil, fmt.Errorf("the export does not contain any workspace")
+	}
+
+	// Every workspace is imported or none is
+	var results []*models.ImportResult
+	err = s.db.InTransaction(func(tx *database.DB) error {
+		var err error
+		results, err = (&InsomniaService{db: tx}).importWorkspaces(workspaces, children)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Resource types without a Rikuest equivalent are reported on the first project
+	types := make([]string, 0, len(skipped))
+	for resourceType := range skipped {
+		types = append(types, resourceType)
+	}
+	sort.Strings(types)
+	for _, resourceType := range types {
+		results[0].Warnings = append(results[0].Warnings,
+			fmt.Sprintf("%d %s resource(s) are not supported and were skipped", skipped[resourceType], resourceType))
+	}
+
+	return results, nil
+}
+
+// importWorkspaces creates a project for each workspace
+func (s *InsomniaService) importWorkspaces(workspaces []InsomniaResource, children map[string][]InsomniaResource) ([]*models.ImportResult, error) {
+	var results []*models.ImportResult
+	for _, workspace := range workspaces {
+		name := workspace.Name
+		if name == "" {
+			name = "Insomnia Workspace"
+		}
+		name, err := uniqueProjectName(s.db, name)
+		if err != nil {
+			return nil, err
+		}
+		project := &models.Project{Name: name, Description: workspace.Description}
+		if err := s.db.CreateProject(project); err != nil {
+			return nil, fmt.Errorf("failed to create project %q: %w", name, err)
+		}
+
+		importer := &insomniaImporter{
+			db:       s.db,
+			children: children,
+			result:   &models.ImportResult{ProjectID: project.ID, Warnings: []string{}},
+		}
+		if err := importer.importChildren(project.ID, workspace.ID, nil, ""); err != nil {
+			return nil, err
+		}
+		if err := importer.importEnvironments(project.ID, workspace.ID); err != nil {
+			return nil, err
+		}
+		results = append(results, importer.result)
+	}
+	return results, nil
+}
+
+// parseInsomniaExport reads an export in JSON, falling back to YAML
+func parseInsomniaExport(data []byte) (*InsomniaExport, error) {
+	var export InsomniaExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		var document interface{}
+		if yamlErr := yaml.Unmarshal(data, &document); yamlErr != nil {
+			return nil, fmt.Errorf("invalid Insomnia export: %w", err)
+		}
+		// Round-trip through JSON so both formats share the same struct tags
+		encoded, err := json.Marshal(document)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Insomnia export: %w", err)
+		}
+		if err := json.Unmarshal(encoded, &export); err != nil {
+			return nil, fmt.Errorf("invalid Insomnia export: %w", err)
+		}
+	}
+
+	if export.Type != "export" || export.Format != 4 {
+		return nil, fmt.Errorf("unsupported Insomnia export, export the data as Insomnia v4 (JSON or YAML)")
+	}
+	return &export, nil
+}
+
+func (imp *insomniaImporter) importChildren(projectID int, parentID string, folderID *int, path string) error {
+	for _, resource := range imp.children[parentID] {
+		itemPath := resource.Name
+		if path != "" {
+			itemPath = path + "/" + resource.Name
+		}
+
+		switch resource.Type {
+		case "request_group":
+			folder := &models.Folder{
+				ProjectID:   projectID,
+				Name:        resource.Name,
+				Description: resource.Description,
+				ParentID:    folderID,
+			}
+			if err := imp.db.CreateFolder(folder); err != nil {
+				return fmt.Errorf("failed to create folder %q: %w", itemPath, err)
+			}
+			imp.result.Folders++
+
+			if len(resource.Environment) > 0 {
+				imp.warn("%s: folder environment variables are not supported and were skipped", itemPath)
+			}
+
+			if err := imp.importChildren(projectID, resource.ID, &folder.ID, itemPath); err != nil {
+				return err
+			}
+		case "request":
+			request := imp.convertRequest(resource, itemPath)
+			request.ProjectID = projectID
+			request.FolderID = folderID
+			if err := imp.db.CreateRequest(request); err != nil {
+				return fmt.Errorf("failed to create request %q: %w", itemPath, err)
+			}
+			imp.result.Requests++
+		}
+	}
+
+	return nil
+}
+
+// convertRequest maps an Insomnia request to a Rikuest request, recording warnings for anything lost
+func (imp *insomniaImporter) convertRequest(resource InsomniaResource, path string) *models.Request {
+	request := &models.Request{
+		Name:        resource.Name,
+		Description: resource.Description,
+		Method:      strings.ToUpper(resource.Method),
+		Headers:     make(map[string]string),
+		QueryParams: []models.QueryParam{},
+		AuthType:    "none",
+		BodyType:    "none",
+		FormData:    []models.FormData{},
+	}
+	if request.Method == "" {
+		request.Method = "GET"
+	}
+
+	base, rawQuery, hasQuery := strings.Cut(imp.convertText(resource.URL, path), "?")
+	request.URL = base
+	if hasQuery {
+		request.QueryParams = parseRawQuery(rawQuery)
+	}
+	for _, param := range resource.Parameters {
+		request.QueryParams = append(request.QueryParams, models.QueryParam{
+			Key:     imp.convertText(param.Name, path),
+			Value:   imp.convertText(param.Value, path),
+			Enabled: !param.Disabled,
+		})
+	}
+
+	for _, header := range resource.Headers {
+		if header.Disabled {
+			imp.warn("%s: disabled header %q was skipped", path, header.Name)
+			continue
+		}
+		if header.Name == "" {
+			continue
+		}
+		request.Headers[imp.convertText(header.Name, path)] = imp.convertText(header.Value, path)
+	}
+
+	imp.applyAuth(request, resource.Authentication, path)
+	imp.applyBody(request, resource.Body, path)
+
+	return request
+}
+
+func (imp *insomniaImporter) applyAuth(request *models.Request, auth map[string]interface{}, path string) {
+	if len(auth) == 0 {
+		return
+	}
+
+	value := func(key string) string {
+		return imp.convertText(postmanValue(auth[key]), path)
+	}
+
+	if disabled, _ := auth["disabled"].(bool); disabled {
+		return
+	}
+
+	authType := postmanValue(auth["type"])
+	switch authType {
+	case "", "none":
+	case "bearer":
+		request.AuthType = "bearer"
+		request.BearerToken = value("token")
+		if prefix := value("prefix"); prefix != "" && !strings.EqualFold(prefix, "Bearer") {
+			imp.warn("%s: custom bearer prefix %q was replaced with Bearer", path, prefix)
+		}
+	case "basic":
+		request.AuthType = "basic"
+		request.BasicAuth = models.BasicAuth{Username: value("username"), Password: value("password")}
+	case "apikey":
+		if value("addTo") == "queryParams" {
+			request.QueryParams = append(request.QueryParams, models.QueryParam{Key: value("key"), Value: value("value"), Enabled: true})
+		} else {
+			request.Headers[value("key")] = value("value")
+		}
+	default:
+		imp.warn("%s: %s authentication is not supported and was skipped", path, authType)
+	}
+}
+
+func (imp *insomniaImporter) applyBody(request *models.Request, body InsomniaBody, path string) {
+	mimeType := strings.ToLower(body.MimeType)
+
+	switch {
+	case mimeType == "" && body.Text == "":
+		return
+	case mimeType == "application/x-www-form-urlencoded" || mimeType == "multipart/form-data":
+		request.BodyType = "form"
+		if mimeType == "multipart/form-data" {
+			request.BodyType = "multipart"
+		}
+		for _, param := range body.Params {
+			if param.Disabled {
+				imp.warn("%s: disabled form field %q was skipped", path, param.Name)
+				continue
+			}
+			item := models.FormData{
+				Key:   imp.convertText(param.Name, path),
+				Value: imp.convertText(param.Value, path),
+			}
+			if param.Type == "file" {
+				if request.BodyType != "multipart" {
+					imp.warn("%s: file field %q was skipped", path, param.Name)
+					continue
+				}
+				// Paths are files of the machine the export comes from, so they are not kept
+				if param.FileName != "" {
+					imp.warn("%s: file field %q refers to a local file, upload it again", path, param.Name)
+				}
+				item.Value, item.Type = "", "file"
+			}
+			request.FormData = append(request.FormData, item)
+		}
+		// Execution sets the form Content-Type itself
+		return
+	case mimeType == "application/octet-stream":
+		imp.warn("%s: binary file bodies are not supported and were skipped", path)
+		return
+	case strings.Contains(mimeType, "json") || mimeType == "application/graphql":
+		// GraphQL bodies are stored by Insomnia as a JSON {"query": ...} document
+		request.BodyType = "json"
+		request.Body = imp.convertText(body.Text, path)
+		mimeType = "application/json"
+	default:
+		request.BodyType = "text"
+		request.Body = imp.convertText(body.Text, path)
+	}
+
+	if mimeType != "" && headerValue(request.Headers, "Content-Type") == "" {
+		request.Headers["Content-Type"] = mimeType
+	}
+}
+
+// importEnvironments creates one environment per Insomnia sub environment, merged over the
+// base environment, or the base environment alone when there are none
+func (imp *insomniaImporter) importEnvironments(projectID int, workspaceID string) error {
+	for _, base := range imp.children[workspaceID] {
+		if base.Type != "environment" {
+			continue
+		}
+
+		baseVariables := flattenInsomniaData("", base.Data)
+		subEnvironments := imp.children[base.ID]
+		if len(subEnvironments) == 0 {
+			if err := imp.createEnvironment(projectID, base.Name, baseVariables); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, sub := range subEnvironments {
+			merged := make(map[string]string, len(baseVariables))
+			for key, value := range baseVariables {
+				merged[key] = value
+			}
+			for key, value := range flattenInsomniaData("", sub.Data) {
+				merged[key] = value
+			}
+			if err := imp.createEnvironment(projectID, sub.Name, merged); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (imp *insomniaImporter) createEnvironment(projectID int, name string, values map[string]string) error {
+	environment := &models.Environment{
+		ProjectID: projectID,
+		Name:      name,
+		Variables: []models.Variable{},
+	}
+	if environment.Name == "" {
+		environment.Name = "Insomnia environment"
+	}
+	for _, key := range sortedKeys(values) {
+		environment.Variables = append(environment.Variables, models.Variable{
+			Key:     key,
+			Value:   imp.convertText(values[key], "environment "+environment.Name),
+			Enabled: true,
+		})
+	}
+
+	active, err := imp.db.GetActiveEnvironment(projectID)
+	if err != nil {
+		return err
+	}
+	if err := imp.db.CreateEnvironment(environment); err != nil {
+		return fmt.Errorf("failed to create environment %q: %w", environment.Name, err)
+	}
+	if active == nil {
+		if err := imp.db.SetActiveEnvironment(projectID, &environment.ID); err != nil {
+			return err
+		}
+	}
+	imp.result.Environments++
+	return nil
+}
+
+// convertText rewrites Insomnia variables to Rikuest's {{name}} syntax. Template tags such as
+// {% response %} have no equivalent and are reported.
+func (imp *insomniaImporter) convertText(text string, path string) string {
+	if strings.Contains(text, "{%") {
+		imp.warn("%s: template tags are not supported and were kept as text", path)
+	}
+	return insomniaVariablePattern.ReplaceAllString(text, "{{$1}}")
+}
+
+func (imp *insomniaImporter) warn(format string, args ...interface{}) {
+	warning := fmt.Sprintf(format, args...)
+	for _, existing := range imp.result.Warnings {
+		if existing == warning {
+			return
+		}
+	}
+	imp.result.Warnings = append(imp.result.Warnings, warning)
+}
+
+// flattenInsomniaData turns nested environment data into dotted keys, matching how Insomnia
+// references them as {{ _.parent.child }}
+func flattenInsomniaData(prefix string, data map[string]interface{}) map[string]string {
+	values := make(map[string]string)
+	for key, value := range data {
+		name := key
+		if prefix != "" {
+			name = prefix + "." + key
+		}
+
+		if nested, ok := value.(map[string]interface{}); ok {
+			for nestedKey, nestedValue := range flattenInsomniaData(name, nested) {
+				values[nestedKey] = nestedValue
+			}
+			continue
+		}
+		values[name] = postmanValue(value)
+	}
+	return values
+}
@@ -0,0 +1,383 @@
+package services
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// renderMarkdown converts the Markdown of descriptions to HTML. It covers what notes about an
+// API usually hold: headings, paragraphs, lists, quotes, fenced code, tables, rules, emphasis,
+// code spans and links. Raw HTML is escaped rather than passed through, so that a description
+// cannot inject markup into a published page.
+func renderMarkdown(source string) string {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	var out strings.Builder
+	renderMarkdownBlocks(&out, strings.Split(source, "\n"))
+	return out.String()
+}
+
+var (
+	markdownHeading   = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	markdownFence     = regexp.MustCompile("^ {0,3}(```+|~~~+)\\s*([\\w+-]*)")
+	markdownListItem  = regexp.MustCompile(`^( {0,3})([-*+]|\d{1,9}[.)])\s+(.*)$`)
+	markdownTableRule = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+)
+
+func renderMarkdownBlocks(out *strings.Builder, lines []string) {
+	var paragraph []string
+	flush := func() {
+		if len(paragraph) > 0 {
+			fmt.Fprintf(out, "<p>%s</p>\n", renderMarkdownInline(strings.Join(paragraph, "\n")))
+			paragraph = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			flush()
+
+		case markdownFence.MatchString(line):
+			flush()
+			match := markdownFence.FindStringSubmatch(line)
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), match[1]); i++ {
+				code = append(code, lines[i])
+			}
+			if match[2] != "" {
+				fmt.Fprintf(out, "<pre><code class=\"language-%s\">", html.EscapeString(match[2]))
+			} else {
+				out.WriteString("<pre><code>")
+			}
+			if len(code) > 0 {
+				out.WriteString(html.EscapeString(strings.Join(code, "\n")) + "\n")
+			}
+			out.WriteString("</code></pre>\n")
+
+		case markdownHeading.MatchString(trimmed):
+			flush()
+			match := markdownHeading.FindStringSubmatch(trimmed)
+			level := len(match[1])
+			fmt.Fprintf(out, "<h%d>%s</h%d>\n", level, renderMarkdownInline(match[2]), level)
+
+		case isMarkdownRule(trimmed):
+			flush()
+			out.WriteString("<hr>\n")
+
+		case strings.HasPrefix(trimmed, ">"):
+			flush()
+			var quoted []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				text := strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")
+				quoted = append(quoted, strings.TrimPrefix(text, " "))
+			}
+			i--
+			out.WriteString("<blockquote>\n")
+			renderMarkdownBlocks(out, quoted)
+			out.WriteString("</blockquote>\n")
+
+		case markdownListItem.MatchString(line) && (len(paragraph) == 0 || !startsOrderedAfterOne(line)):
+			flush()
+			i = renderMarkdownList(out, lines, i) - 1
+
+		case strings.Contains(line, "|") && i+1 < len(lines) && markdownTableRule.MatchString(lines[i+1]) &&
+			strings.Contains(lines[i+1], "-"):
+			flush()
+			i = renderMarkdownTable(out, lines, i) - 1
+
+		default:
+			paragraph = append(paragraph, line)
+		}
+	}
+	flush()
+}
+
+// isMarkdownRule reports whether a line is three or more -, * or _, optionally spaced out
+func isMarkdownRule(trimmed string) bool {
+	compact := strings.ReplaceAll(trimmed, " ", "")
+	return len(compact) >= 3 && strings.Trim(compact, compact[:1]) == "" && strings.Contains("-*_", compact[:1])
+}
+
+// startsOrderedAfterOne reports whether a line is an ordered item other than "1.". Those do not
+// interrupt a paragraph, so that a wrapped line starting with "2021." stays text.
+func startsOrderedAfterOne(line string) bool {
+	match := markdownListItem.FindStringSubmatch(line)
+	marker := strings.TrimRight(match[2], ".)")
+	return marker != match[2] && marker != "1"
+}
+
+// renderMarkdownList renders the list starting at lines[start] and returns the index of the
+// first line after it. Lines indented below an item belong to it, so lists can be nested.
+func renderMarkdownList(out *strings.Builder, lines []string, start int) int {
+	first := markdownListItem.FindStringSubmatch(lines[start])
+	indent := len(first[1])
+	ordered := strings.TrimRight(first[2], ".)") != first[2]
+	tag := "ul"
+	if ordered {
+		tag = "ol"
+	}
+	sameKind := func(marker string) bool {
+		return (strings.TrimRight(marker, ".)") != marker) == ordered
+	}
+
+	var items [][]string
+	loose := false
+	i := start
+	for i < len(lines) {
+		line := lines[i]
+		if match := markdownListItem.FindStringSubmatch(line); match != nil && len(match[1]) == indent {
+			if !sameKind(match[2]) {
+				break
+			}
+			items = append(items, []string{match[3]})
+			i++
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			// A blank line ends the list unless the next line is indented or another item of it
+			next := i + 1
+			for next < len(lines) && strings.TrimSpace(lines[next]) == "" {
+				next++
+			}
+			var item []string
+			if next < len(lines) {
+				item = markdownListItem.FindStringSubmatch(lines[next])
+			}
+			if next < len(lines) && (leadingSpaces(lines[next]) > indent ||
+				item != nil && len(item[1]) == indent && sameKind(item[2])) {
+				loose = true
+				items[len(items)-1] = append(items[len(items)-1], "")
+				i++
+				continue
+			}
+			break
+		}
+		if leadingSpaces(line) <= indent && (markdownListItem.MatchString(line) || markdownFence.MatchString(line) ||
+			strings.HasPrefix(strings.TrimSpace(line), "#") || strings.HasPrefix(strings.TrimSpace(line), ">")) {
+			break
+		}
+		// Continuation lines keep their indentation relative to the item
+		items[len(items)-1] = append(items[len(items)-1], dedent(line, indent+2))
+		i++
+	}
+
+	if ordered {
+		if number := strings.TrimRight(first[2], ".)"); number != "1" {
+			fmt.Fprintf(out, "<ol start=\"%s\">\n", strings.TrimLeft(number, "0"))
+		} else {
+			out.WriteString("<ol>\n")
+		}
+	} else {
+		out.WriteString("<ul>\n")
+	}
+	for _, item := range items {
+		var body strings.Builder
+		renderMarkdownBlocks(&body, item)
+		content := body.String()
+		// Tight lists keep their text out of paragraphs
+		if !loose && strings.HasPrefix(content, "<p>") {
+			end := strings.Index(content, "</p>\n")
+			content = content[len("<p>"):end] + "\n" + content[end+len("</p>\n"):]
+		}
+		fmt.Fprintf(out, "<li>%s</li>\n", strings.TrimSuffix(content, "\n"))
+	}
+	fmt.Fprintf(out, "</%s>\n", tag)
+	return i
+}
+
+// renderMarkdownTable renders the table whose header is lines[start] and returns the index of
+// the first line after it
+func renderMarkdownTable(out *strings.Builder, lines []string, start int) int {
+	header := markdownTableCells(lines[start])
+	var aligns []string
+	for _, cell := range markdownTableCells(lines[start+1]) {
+		switch {
+		case strings.HasPrefix(cell, ":") && strings.HasSuffix(cell, ":"):
+			aligns = append(aligns, "center")
+		case strings.HasSuffix(cell, ":"):
+			aligns = append(aligns, "right")
+		case strings.HasPrefix(cell, ":"):
+			aligns = append(aligns, "left")
+		default:
+			aligns = append(aligns, "")
+		}
+	}
+	row := func(cells []string, tag string) {
+		out.WriteString("<tr>")
+		for i := range header {
+			var cell string
+			if i < len(cells) {
+				cell = cells[i]
+			}
+			if i < len(aligns) && aligns[i] != "" {
+				fmt.Fprintf(out, "<%s style=\"text-align: %s\">%s</%s>", tag, aligns[i], renderMarkdownInline(cell), tag)
+			} else {
+				fmt.Fprintf(out, "<%s>%s</%s>", tag, renderMarkdownInline(cell), tag)
+			}
+		}
+		out.WriteString("</tr>\n")
+	}
+
+	out.WriteString("<table>\n<thead>\n")
+	row(header, "th")
+	out.WriteString("</thead>\n<tbody>\n")
+	i := start + 2
+	for ; i < len(lines) && strings.TrimSpace(lines[i]) != "" && strings.Contains(lines[i], "|"); i++ {
+		row(markdownTableCells(lines[i]), "td")
+	}
+	out.WriteString("</tbody>\n</table>\n")
+	return i
+}
+
+// markdownTableCells splits a table row on the pipes that are not escaped or in code spans
+func markdownTableCells(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = strings.TrimSuffix(line, "|")
+	}
+
+	var cells []string
+	var cell strings.Builder
+	inCode := false
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '`':
+			inCode = !inCode
+			cell.WriteByte('`')
+		case line[i] == '|' && !inCode:
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+var (
+	markdownCodeSpan = regexp.MustCompile("(`+)(.+?)(`+)")
+	markdownLink     = regexp.MustCompile(`(!?)\[([^\]]*)\]\(\s*((?:[^()\s]|\([^()\s]*\))+)(?:\s+&#34;([^&]*)&#34;)?\s*\)`)
+	markdownAutoLink = regexp.MustCompile(`&lt;((?:https?|mailto):[^\s&]+)&gt;`)
+	markdownStrong   = regexp.MustCompile(`\*\*(\S(?:.*?\S)?)\*\*|__(\S(?:.*?\S)?)__`)
+	markdownEm       = regexp.MustCompile(`\*(\S(?:[^*]*?\S)?)\*`)
+	markdownUnderEm  = regexp.MustCompile(`(^|[^\w])_(\S(?:[^_]*?\S)?)_($|[^\w])`)
+	markdownStrike   = regexp.MustCompile(`~~(\S(?:.*?\S)?)~~`)
+	markdownBreak    = regexp.MustCompile(`( {2,}|\\)\n`)
+	markdownEscape   = regexp.MustCompile("\\\\([\\\\`*_{}\\[\\]()#+\\-.!|~>])")
+	markdownToken    = regexp.MustCompile("\x00(\\d+)\x00")
+)
+
+// renderMarkdownInline renders the text of a block. Code spans and links are set aside as
+// tokens first, so that emphasis never applies inside them or inside their URLs.
+func renderMarkdownInline(text string) string {
+	var tokens []string
+	token := func(rendered string) string {
+		tokens = append(tokens, rendered)
+		return fmt.Sprintf("\x00%d\x00", len(tokens)-1)
+	}
+
+	// Code spans close with a run of as many backticks as they open with
+	var rest strings.Builder
+	text = strings.ReplaceAll(text, "\x00", "")
+	for text != "" {
+		location := markdownCodeSpan.FindStringSubmatchIndex(text)
+		if location == nil {
+			rest.WriteString(text)
+			break
+		}
+		open := text[location[2]:location[3]]
+		end := strings.Index(text[location[3]:], open)
+		if end < 0 {
+			rest.WriteString(text[:location[3]])
+			text = text[location[3]:]
+			continue
+		}
+		code := text[location[3] : location[3]+end]
+		rest.WriteString(text[:location[0]])
+		rest.WriteString(token("<code>" + html.EscapeString(strings.TrimSpace(code)) + "</code>"))
+		text = text[location[3]+end+len(open):]
+	}
+
+	// Tokens are NUL bytes around digits, which escaping leaves alone
+	escaped := html.EscapeString(rest.String())
+	escaped = markdownEscape.ReplaceAllStringFunc(escaped, func(s string) string {
+		return token(s[1:])
+	})
+
+	escaped = markdownLink.ReplaceAllStringFunc(escaped, func(s string) string {
+		match := markdownLink.FindStringSubmatch(s)
+		image, label, href, title := match[1] == "!", match[2], match[3], match[4]
+		if !safeMarkdownURL(html.UnescapeString(href)) {
+			return label
+		}
+		attributes := fmt.Sprintf(`href="%s"`, href)
+		if title != "" {
+			attributes += fmt.Sprintf(` title="%s"`, title)
+		}
+		if image {
+			return token(fmt.Sprintf(`<img src="%s" alt="%s">`, href, markdownToken.ReplaceAllString(label, "")))
+		}
+		return token(fmt.Sprintf(`<a %s>`, attributes)) + label + token("</a>")
+	})
+	escaped = markdownAutoLink.ReplaceAllStringFunc(escaped, func(s string) string {
+		address := markdownAutoLink.FindStringSubmatch(s)[1]
+		return token(fmt.Sprintf(`<a href="%s">%s</a>`, address, strings.TrimPrefix(address, "mailto:")))
+	})
+
+	escaped = markdownStrong.ReplaceAllString(escaped, "<strong>$1$2</strong>")
+	escaped = markdownEm.ReplaceAllString(escaped, "<em>$1</em>")
+	escaped = markdownUnderEm.ReplaceAllString(escaped, "$1<em>$2</em>$3")
+	escaped = markdownStrike.ReplaceAllString(escaped, "<del>$1</del>")
+	escaped = markdownBreak.ReplaceAllString(escaped, "<br>\n")
+
+	// Tokens may hold other tokens, such as a code span inside a link label
+	for markdownToken.MatchString(escaped) {
+		escaped = markdownToken.ReplaceAllStringFunc(escaped, func(s string) string {
+			var index int
+			fmt.Sscanf(strings.Trim(s, "\x00"), "%d", &index)
+			return tokens[index]
+		})
+	}
+	return escaped
+}
+
+// safeMarkdownURL allows links to web pages, mail addresses, anchors and relative paths, but
+// not javascript: or data: URLs
+func safeMarkdownURL(address string) bool {
+	scheme := address
+	if end := strings.IndexAny(address, "/?#"); end >= 0 {
+		scheme = address[:end]
+	}
+	colon := strings.Index(scheme, ":")
+	if colon < 0 {
+		return true
+	}
+	switch strings.ToLower(scheme[:colon]) {
+	case "http", "https", "mailto":
+		return true
+	}
+	return false
+}
+
+func leadingSpaces(line string) int {
+	expanded := strings.ReplaceAll(line, "\t", "    ")
+	return len(expanded) - len(strings.TrimLeft(expanded, " "))
+}
+
+// dedent removes up to n leading spaces from a line
+func dedent(line string, n int) string {
+	line = strings.ReplaceAll(line, "\t", "    ")
+	for i := 0; i < n && strings.HasPrefix(line, " "); i++ {
+		line = line[1:]
+	}
+	return line
+}
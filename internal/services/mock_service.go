@@ -0,0 +1,345 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"rikuest/internal/database"
+	"rikuest/internal/models"
+)
+
+const defaultMockAddress = "127.0.0.1:8090"
+
+// MockService serves the saved response examples of a project as a stand-in backend
+type MockService struct {
+	db     *database.DB
+	mutex  sync.Mutex
+	server *http.Server
+	config models.MockServerConfig
+
+	// AllowRemote lets the mock server listen on addresses other machines can reach. It is off
+	// by default because the examples it serves may hold captured responses.
+	AllowRemote bool
+}
+
+func NewMockService(db *database.DB) *MockService {
+	return &MockService{db: db}
+}
+
+// Start launches the mock server in the background. Only one mock server runs at a time.
+func (s *MockService) Start(config models.MockServerConfig) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.server != nil {
+		return fmt.Errorf("mock server is already running on %s", s.config.Address)
+	}
+
+	if _, err := s.db.GetProject(config.ProjectID); err != nil {
+		return fmt.Errorf("project not found: %w", err)
+	}
+
+	config, err := normalizeMockConfig(config)
+	if err != nil {
+		return err
+	}
+	if !s.AllowRemote && !isLoopbackAddress(config.Address) {
+		return fmt.Errorf("the mock server only listens on loopback addresses such as %s, not on %s", defaultMockAddress, config.Address)
+	}
+
+	// Listen synchronously so errors like "address already in use" reach the caller
+	listener, err := net.Listen("tcp", config.Address)
+	if err != nil {
+		return fmt.Errorf("failed to start mock server: %w", err)
+	}
+
+	server := &http.Server{Handler: s.Handler(config)}
+	s.server = server
+	s.config = config
+
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Printf("Mock server stopped: %v", err)
+		}
+	}()
+
+	log.Printf("Mock server for project %d listening on %s", config.ProjectID, config.Address)
+	return nil
+}
+
+// Stop shuts down the running mock server, if any
+func (s *MockService) Stop() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.server == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := s.server.Shutdown(ctx)
+	s.server = nil
+	return err
+}
+
+// Status reports whether the mock server is running and with which configuration
+func (s *MockService) Status() models.MockServerStatus {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return models.MockServerStatus{
+		Running: s.server != nil,
+		Config:  s.config,
+	}
+}
+
+// Handler returns an http.Handler that serves the examples of the configured project.
+// Requests and examples are loaded on every call so edits are picked up immediately.
+func (s *MockService) Handler(config models.MockServerConfig) http.Handler {
+	config, err := normalizeMockConfig(config)
+	if err != nil {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			writeMockError(w, http.StatusInternalServerError, err.Error())
+		})
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Allow browser frontends on any origin to use the mock server
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Headers", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, HEAD, OPTIONS")
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		request, err := s.matchRequest(config.ProjectID, r.Method, r.URL.Path)
+		if err != nil {
+			writeMockError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if request == nil {
+			writeMockError(w, http.StatusNotFound, fmt.Sprintf("No mock route matches %s %s", r.Method, r.URL.Path))
+			return
+		}
+
+		examples, err := s.db.GetRequestExamples(request.ID)
+		if err != nil {
+			writeMockError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		example := selectMockExample(examples, r)
+		if example == nil {
+			writeMockError(w, http.StatusNotImplemented, fmt.Sprintf("Request %q has no saved examples", request.Name))
+			return
+		}
+		if !isHTTPStatus(example.Status) {
+			writeMockError(w, http.StatusInternalServerError, fmt.Sprintf("Example %q has an invalid status %d", example.Name, example.Status))
+			return
+		}
+
+		// Delay from the server config plus an optional per-call delay
+		delay := time.Duration(config.DelayMs) * time.Millisecond
+		if value := r.Header.Get("X-Mock-Delay"); value != "" {
+			if ms, err := strconv.Atoi(value); err == nil && ms > 0 {
+				delay += time.Duration(ms) * time.Millisecond
+			}
+		}
+		if delay > 0 {
+			select {
+			case <-time.After(delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		if config.ErrorRate > 0 && rand.Float64() < config.ErrorRate {
+			writeMockError(w, config.ErrorStatus, "Injected mock error")
+			return
+		}
+
+		for key, value := range example.Headers {
+			if isMockSkippedHeader(key) {
+				continue
+			}
+			w.Header().Set(key, value)
+		}
+		w.Header().Set("X-Mock-Example", example.Name)
+		w.WriteHeader(example.Status)
+		if r.Method != http.MethodHead {
+			w.Write([]byte(example.Body))
+		}
+	})
+}
+
+// matchRequest finds the request of the project whose method and URL path match.
+// Path segments such as :id, {id} or {{id}} act as wildcards; the most specific match wins.
+func (s *MockService) matchRequest(projectID int, method string, path string) (*models.Request, error) {
+	requests, err := s.db.GetRequests(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	incoming := splitMockPath(path)
+
+	var best *models.Request
+	bestScore := -1
+	for i := range requests {
+		request := &requests[i]
+		if !strings.EqualFold(request.Method, method) {
+			continue
+		}
+
+		score := matchMockPath(splitMockPath(mockPathFromURL(request.URL)), incoming)
+		if score > bestScore {
+			best = request
+			bestScore = score
+		}
+	}
+
+	return best, nil
+}
+
+// selectMockExample picks the example to serve. Callers can choose one by name with the
+// __example query parameter or X-Mock-Example header, or by status with X-Mock-Status.
+// Otherwise the first successful example is served.
+func selectMockExample(examples []models.ResponseExample, r *http.Request) *models.ResponseExample {
+	if len(examples) == 0 {
+		return nil
+	}
+
+	name := r.URL.Query().Get("__example")
+	if name == "" {
+		name = r.Header.Get("X-Mock-Example")
+	}
+	if name != "" {
+		for i := range examples {
+			if strings.EqualFold(examples[i].Name, name) {
+				return &examples[i]
+			}
+		}
+	}
+
+	if value := r.Header.Get("X-Mock-Status"); value != "" {
+		if status, err := strconv.Atoi(value); err == nil {
+			for i := range examples {
+				if examples[i].Status == status {
+					return &examples[i]
+				}
+			}
+		}
+	}
+
+	for i := range examples {
+		if examples[i].Status >= 200 && examples[i].Status < 300 {
+			return &examples[i]
+		}
+	}
+
+	return &examples[0]
+}
+
+// mockPathFromURL extracts the path of a stored request URL, which may start with a
+// {{variable}} instead of a scheme and host
+func mockPathFromURL(rawURL string) string {
+	path := strings.TrimSpace(rawURL)
+
+	if strings.HasPrefix(path, "{{") {
+		if end := strings.Index(path, "}}"); end >= 0 {
+			path = path[end+2:]
+		}
+	}
+
+	if index := strings.Index(path, "://"); index >= 0 {
+		path = path[index+3:]
+		if slash := strings.Index(path, "/"); slash >= 0 {
+			path = path[slash:]
+		} else {
+			path = "/"
+		}
+	}
+
+	if index := strings.IndexAny(path, "?#"); index >= 0 {
+		path = path[:index]
+	}
+
+	return path
+}
+
+func splitMockPath(path string) []string {
+	var segments []string
+	for _, segment := range strings.Split(path, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
+
+// matchMockPath returns the number of literal segments matched, or -1 when the paths differ
+func matchMockPath(pattern []string, path []string) int {
+	if len(pattern) != len(path) {
+		return -1
+	}
+
+	score := 0
+	for i, segment := range pattern {
+		if isMockWildcard(segment) {
+			continue
+		}
+		if segment != path[i] {
+			return -1
+		}
+		score++
+	}
+
+	return score
+}
+
+func isMockWildcard(segment string) bool {
+	return segment == "*" ||
+		strings.HasPrefix(segment, ":") ||
+		(strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}"))
+}
+
+func isMockSkippedHeader(key string) bool {
+	switch strings.ToLower(key) {
+	// Examples hold decoded bodies, so their Content-Encoding no longer applies
+	case "content-length", "content-encoding", "transfer-encoding", "connection", "date":
+		return true
+	}
+	return false
+}
+
+func normalizeMockConfig(config models.MockServerConfig) (models.MockServerConfig, error) {
+	if config.Address == "" {
+		config.Address = defaultMockAddress
+	}
+	if config.ErrorStatus == 0 {
+		config.ErrorStatus = http.StatusInternalServerError
+	}
+	if !isHTTPStatus(config.ErrorStatus) {
+		return config, fmt.Errorf("invalid error status %d, expected a status between 100 and 599", config.ErrorStatus)
+	}
+	if config.DelayMs < 0 {
+		config.DelayMs = 0
+	}
+	return config, nil
+}
+
+func writeMockError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+
+	"rikuest/internal/models"
+)
+
+// multipartPreviewBoundary is used when a multipart body is displayed rather than sent, so
+// raw requests and generated snippets stay stable between calls
+const multipartPreviewBoundary = "RikuestFormBoundary"
+
+// buildMultipartBody encodes form fields as multipart/form-data and returns the body with its
+// Content-Type. File fields hold a local path; the file is read when files allows it and is
+// replaced by a placeholder when files is nil. An empty boundary lets the writer pick a random one.
+func buildMultipartBody(formData []models.FormData, boundary string, files *FileAccess) ([]byte, string, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if boundary != "" {
+		if err := writer.SetBoundary(boundary); err != nil {
+			return nil, "", err
+		}
+	}
+
+	for _, item := range formData {
+		if item.Key == "" {
+			continue
+		}
+
+		if item.Type != "file" {
+			if err := writer.WriteField(item.Key, item.Value); err != nil {
+				return nil, "", err
+			}
+			continue
+		}
+
+		part, err := writer.CreateFormFile(item.Key, filepath.Base(item.Value))
+		if err != nil {
+			return nil, "", err
+		}
+		if files == nil {
+			fmt.Fprintf(part, "<contents of %s>", item.Value)
+			continue
+		}
+
+		path, err := files.Resolve(item.Value)
+		if err != nil {
+			return nil, "", fmt.Errorf("cannot read file for form field %q: %w", item.Key, err)
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read file for form field %q: %w", item.Key, err)
+		}
+		_, err = io.Copy(part, file)
+		file.Close()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read file for form field %q: %w", item.Key, err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return body.Bytes(), writer.FormDataContentType(), nil
+}
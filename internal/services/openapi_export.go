@@ -0,0 +1,886 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"rikuest/internal/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+// openAPIExportHistory is how many of the latest executions of each request are used to infer
+// response schemas
+const openAPIExportHistory = 10
+
+var (
+	openAPIVariable    = regexp.MustCompile(`\{\{\s*([^{}\s]+)\s*\}\}`)
+	openAPIURLVariable = regexp.MustCompile(`^\{\{\s*([^{}\s]+)\s*\}\}`)
+	openAPIIDSegment   = regexp.MustCompile(`^(\d+|[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12})$`)
+	openAPIDateTime    = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}`)
+	openAPIDate        = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	openAPIUUID        = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	openAPIEmail       = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+)
+
+// openAPIIgnoredHeaders are described by other parts of an operation and must not be parameters
+var openAPIIgnoredHeaders = map[string]bool{"accept": true, "content-type": true, "authorization": true}
+
+type OpenAPIExportDocument struct {
+	OpenAPI    string                                        `json:"openapi" yaml:"openapi"`
+	Info       OpenAPIExportInfo                             `json:"info" yaml:"info"`
+	Servers    []OpenAPIExportServer                         `json:"servers,omitempty" yaml:"servers,omitempty"`
+	Tags       []OpenAPIExportTag                            `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Paths      map[string]map[string]*OpenAPIExportOperation `json:"paths" yaml:"paths"`
+	Components *OpenAPIExportComponents                      `json:"components,omitempty" yaml:"components,omitempty"`
+}
+
+type OpenAPIExportInfo struct {
+	Title       string `json:"title" yaml:"title"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Version     string `json:"version" yaml:"version"`
+}
+
+type OpenAPIExportServer struct {
+	URL       string                                 `json:"url" yaml:"url"`
+	Variables map[string]OpenAPIExportServerVariable `json:"variables,omitempty" yaml:"variables,omitempty"`
+}
+
+type OpenAPIExportServerVariable struct {
+	Default string `json:"default" yaml:"default"`
+}
+
+type OpenAPIExportTag struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+type OpenAPIExportOperation struct {
+	Tags        []string                          `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Summary     string                            `json:"summary" yaml:"summary"`
+	Description string                            `json:"description,omitempty" yaml:"description,omitempty"`
+	OperationID string                            `json:"operationId" yaml:"operationId"`
+	Servers     []OpenAPIExportServer             `json:"servers,omitempty" yaml:"servers,omitempty"`
+	Parameters  []OpenAPIExportParameter          `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	RequestBody *OpenAPIExportRequestBody         `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIExportResponse `json:"responses,omitempty" yaml:"responses,omitempty"`
+	Security    []map[string][]string             `json:"security,omitempty" yaml:"security,omitempty"`
+}
+
+type OpenAPIExportParameter struct {
+	Name     string                 `json:"name" yaml:"name"`
+	In       string                 `json:"in" yaml:"in"`
+	Required bool                   `json:"required,omitempty" yaml:"required,omitempty"`
+	Schema   map[string]interface{} `json:"schema" yaml:"schema"`
+	Example  interface{}            `json:"example,omitempty" yaml:"example,omitempty"`
+}
+
+type OpenAPIExportRequestBody struct {
+	Content map[string]*OpenAPIExportMediaType `json:"content" yaml:"content"`
+}
+
+type OpenAPIExportResponse struct {
+	Description string                             `json:"description" yaml:"description"`
+	Content     map[string]*OpenAPIExportMediaType `json:"content,omitempty" yaml:"content,omitempty"`
+}
+
+type OpenAPIExportMediaType struct {
+	Schema  map[string]interface{} `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Example interface{}            `json:"example,omitempty" yaml:"example,omitempty"`
+}
+
+type OpenAPIExportComponents struct {
+	SecuritySchemes map[string]map[string]string `json:"securitySchemes,omitempty" yaml:"securitySchemes,omitempty"`
+}
+
+// openAPIExporter builds a document from requests. Requests with the same method and path are
+// merged into one operation whose schemas cover the bodies of all of them.
+type openAPIExporter struct {
+	document    *OpenAPIExportDocument
+	variables   map[string]string
+	tagPaths    map[int]string
+	tagNotes    map[string]string
+	operations  map[*OpenAPIExportOperation]*openAPIExportSamples
+	operationID map[string]bool
+}
+
+// openAPIExportSamples collects the bodies an operation's schemas are inferred from
+type openAPIExportSamples struct {
+	requests  map[string][]interface{}
+	responses map[string]map[string][]interface{}
+}
+
+// newOpenAPIExporter starts a document. Server variables such as {{baseUrl}} default to the
+// values in variables, and folders are named by their path so nested folders get distinct tags.
+func newOpenAPIExporter(info OpenAPIExportInfo, folders []models.Folder, variables map[string]string) *openAPIExporter {
+	if info.Version == "" {
+		info.Version = "1.0.0"
+	}
+	exporter := &openAPIExporter{
+		document: &OpenAPIExportDocument{
+			OpenAPI: "3.1.0",
+			Info:    info,
+			Paths:   make(map[string]map[string]*OpenAPIExportOperation),
+		},
+		variables:   variables,
+		tagPaths:    make(map[int]string),
+		tagNotes:    make(map[string]string),
+		operations:  make(map[*OpenAPIExportOperation]*openAPIExportSamples),
+		operationID: make(map[string]bool),
+	}
+
+	byID := make(map[int]*models.Folder, len(folders))
+	for i := range folders {
+		byID[folders[i].ID] = &folders[i]
+	}
+	for _, folder := range folders {
+		names := []string{folder.Name}
+		for parent := folder.ParentID; parent != nil && byID[*parent] != nil && len(names) <= len(folders); parent = byID[*parent].ParentID {
+			names = append([]string{byID[*parent].Name}, names...)
+		}
+		exporter.tagPaths[folder.ID] = strings.Join(names, " / ")
+		exporter.tagNotes[exporter.tagPaths[folder.ID]] = folder.Description
+	}
+	return exporter
+}
+
+// add describes a request and the responses recorded for it
+func (e *openAPIExporter) add(request *models.Request, responses []models.RequestResponse) {
+	server, path, query := splitOpenAPIURL(request.URL)
+	path, pathParams := openAPIPathTemplate(path)
+
+	methods, ok := e.document.Paths[path]
+	if !ok {
+		methods = make(map[string]*OpenAPIExportOperation)
+		e.document.Paths[path] = methods
+	}
+	method := strings.ToLower(request.Method)
+	if method == "" {
+		method = "get"
+	}
+
+	operation, exists := methods[method]
+	if !exists {
+		operation = &OpenAPIExportOperation{
+			Summary:     request.Name,
+			Description: request.Description,
+			OperationID: e.uniqueOperationID(request.Name, method, path),
+			Parameters:  pathParams,
+		}
+		methods[method] = operation
+		e.operations[operation] = &openAPIExportSamples{
+			requests:  make(map[string][]interface{}),
+			responses: make(map[string]map[string][]interface{}),
+		}
+
+		if request.FolderID != nil && e.tagPaths[*request.FolderID] != "" {
+			operation.Tags = []string{e.tagPaths[*request.FolderID]}
+			e.addTag(operation.Tags[0])
+		}
+		if server != nil {
+			e.setServer(operation, *server)
+		}
+		e.applyParameters(operation, request, query)
+		e.applySecurity(operation, request)
+	}
+
+	samples := e.operations[operation]
+	if mediaType, sample, ok := openAPIRequestSample(request); ok {
+		if operation.RequestBody == nil {
+			operation.RequestBody = &OpenAPIExportRequestBody{Content: make(map[string]*OpenAPIExportMediaType)}
+		}
+		if operation.RequestBody.Content[mediaType] == nil {
+			operation.RequestBody.Content[mediaType] = &OpenAPIExportMediaType{Example: sample}
+		}
+		samples.requests[mediaType] = append(samples.requests[mediaType], sample)
+	}
+
+	for _, response := range responses {
+		if response.Status == 0 {
+			continue
+		}
+		if operation.Responses == nil {
+			operation.Responses = make(map[string]*OpenAPIExportResponse)
+		}
+		status := strconv.Itoa(response.Status)
+		if operation.Responses[status] == nil {
+			description := http.StatusText(response.Status)
+			if description == "" {
+				description = "Status " + status
+			}
+			operation.Responses[status] = &OpenAPIExportResponse{Description: description}
+			samples.responses[status] = make(map[string][]interface{})
+		}
+
+		mediaType, sample, ok := openAPIResponseSample(response)
+		if !ok {
+			continue
+		}
+		described := operation.Responses[status]
+		if described.Content == nil {
+			described.Content = make(map[string]*OpenAPIExportMediaType)
+		}
+		if described.Content[mediaType] == nil {
+			described.Content[mediaType] = &OpenAPIExportMediaType{Example: sample}
+		}
+		samples.responses[status][mediaType] = append(samples.responses[status][mediaType], sample)
+	}
+}
+
+// build infers the schemas of every operation from the samples collected so far
+func (e *openAPIExporter) build() *OpenAPIExportDocument {
+	for operation, samples := range e.operations {
+		if operation.RequestBody != nil {
+			for mediaType, content := range operation.RequestBody.Content {
+				content.Schema = openAPISchemaFromSamples(mediaType, samples.requests[mediaType])
+			}
+		}
+		for status, response := range operation.Responses {
+			for mediaType, content := range response.Content {
+				content.Schema = openAPISchemaFromSamples(mediaType, samples.responses[status][mediaType])
+			}
+		}
+	}
+	return e.document
+}
+
+func (e *openAPIExporter) addTag(name string) {
+	for _, tag := range e.document.Tags {
+		if tag.Name == name {
+			return
+		}
+	}
+	e.document.Tags = append(e.document.Tags, OpenAPIExportTag{Name: name, Description: e.tagNotes[name]})
+}
+
+// setServer sets the server of the document from the first request, turning {{variables}}
+// into server variables. Operations on another server list it as their own.
+func (e *openAPIExporter) setServer(operation *OpenAPIExportOperation, serverURL string) {
+	server := OpenAPIExportServer{}
+	server.URL = openAPIVariable.ReplaceAllStringFunc(serverURL, func(match string) string {
+		name := openAPIVariable.FindStringSubmatch(match)[1]
+		if server.Variables == nil {
+			server.Variables = make(map[string]OpenAPIExportServerVariable)
+		}
+		server.Variables[name] = OpenAPIExportServerVariable{Default: e.variables[name]}
+		return "{" + name + "}"
+	})
+
+	if len(e.document.Servers) == 0 {
+		e.document.Servers = []OpenAPIExportServer{server}
+		return
+	}
+	if e.document.Servers[0].URL != server.URL {
+		operation.Servers = []OpenAPIExportServer{server}
+	}
+}
+
+func (e *openAPIExporter) uniqueOperationID(name, method, path string) string {
+	id := openAPIOperationID(name)
+	if id == "" {
+		id = openAPIOperationID(method + " " + path)
+	}
+	unique := id
+	for i := 2; e.operationID[unique]; i++ {
+		unique = fmt.Sprintf("%s%d", id, i)
+	}
+	e.operationID[unique] = true
+	return unique
+}
+
+// applyParameters adds the query parameters of the request and the URL, and its headers
+func (e *openAPIExporter) applyParameters(operation *OpenAPIExportOperation, request *models.Request, query url.Values) {
+	seen := make(map[string]bool)
+	addQuery := func(name, value string) {
+		if name == "" || seen[name] {
+			return
+		}
+		seen[name] = true
+		operation.Parameters = append(operation.Parameters, openAPIParameter(name, "query", openAPIExample(name, value), false))
+	}
+
+	for _, param := range request.QueryParams {
+		addQuery(param.Key, param.Value)
+	}
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		addQuery(key, query.Get(key))
+	}
+
+	for _, name := range sortedKeys(request.Headers) {
+		if openAPIIgnoredHeaders[strings.ToLower(name)] {
+			continue
+		}
+		operation.Parameters = append(operation.Parameters, openAPIParameter(name, "header", openAPIExample(name, request.Headers[name]), false))
+	}
+}
+
+// openAPIExample is the value a parameter is exemplified with. Credentials have none, since
+// the document shows {{variables}} in schemas only.
+func openAPIExample(name, value string) string {
+	if isSecretName(name) {
+		return ""
+	}
+	return value
+}
+
+func (e *openAPIExporter) applySecurity(operation *OpenAPIExportOperation, request *models.Request) {
+	var name string
+	var scheme map[string]string
+	switch request.AuthType {
+	case "bearer":
+		name, scheme = "bearerAuth", map[string]string{"type": "http", "scheme": "bearer"}
+	case "basic":
+		name, scheme = "basicAuth", map[string]string{"type": "http", "scheme": "basic"}
+	default:
+		return
+	}
+
+	if e.document.Components == nil {
+		e.document.Components = &OpenAPIExportComponents{SecuritySchemes: make(map[string]map[string]string)}
+	}
+	e.document.Components.SecuritySchemes[name] = scheme
+	operation.Security = []map[string][]string{{name: {}}}
+}
+
+// splitOpenAPIURL separates the server, path and query of a request URL. A leading
+// {{variable}} is the server.
+func splitOpenAPIURL(rawURL string) (*string, string, url.Values) {
+	rawURL = strings.TrimSpace(rawURL)
+	var query url.Values
+	if i := strings.Index(rawURL, "?"); i >= 0 {
+		query, _ = url.ParseQuery(rawURL[i+1:])
+		rawURL = rawURL[:i]
+	}
+	if i := strings.Index(rawURL, "#"); i >= 0 {
+		rawURL = rawURL[:i]
+	}
+
+	if match := openAPIURLVariable.FindString(rawURL); match != "" {
+		return &match, openAPIPath(rawURL[len(match):]), query
+	}
+
+	if !strings.Contains(rawURL, "://") {
+		return nil, openAPIPath(rawURL), query
+	}
+	scheme, rest, _ := strings.Cut(rawURL, "://")
+	host, path, _ := strings.Cut(rest, "/")
+	server := scheme + "://" + host
+	return &server, openAPIPath("/" + path), query
+}
+
+func openAPIPath(path string) string {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return path
+}
+
+// openAPIPathTemplate turns {{variables}} and segments that look like IDs into path parameters.
+// An ID segment is named after the segment before it, so /users/42 becomes /users/{userId}.
+func openAPIPathTemplate(path string) (string, []OpenAPIExportParameter) {
+	var params []OpenAPIExportParameter
+	used := make(map[string]bool)
+	unique := func(name string) string {
+		candidate := name
+		for i := 2; used[candidate]; i++ {
+			candidate = fmt.Sprintf("%s%d", name, i)
+		}
+		used[candidate] = true
+		return candidate
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if segment == "" {
+			continue
+		}
+		if openAPIVariable.MatchString(segment) {
+			segments[i] = openAPIVariable.ReplaceAllStringFunc(segment, func(match string) string {
+				name := unique(openAPIVariable.FindStringSubmatch(match)[1])
+				params = append(params, openAPIParameter(name, "path", "", true))
+				return "{" + name + "}"
+			})
+			continue
+		}
+		if openAPIIDSegment.MatchString(segment) {
+			name := "id"
+			if i > 0 && segments[i-1] != "" && !strings.HasPrefix(segments[i-1], "{") {
+				name = openAPIOperationID(strings.TrimSuffix(segments[i-1], "s") + " id")
+			}
+			name = unique(name)
+			segments[i] = "{" + name + "}"
+			params = append(params, openAPIParameter(name, "path", segment, true))
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+func openAPIParameter(name, in, value string, required bool) OpenAPIExportParameter {
+	param := OpenAPIExportParameter{Name: name, In: in, Required: required, Schema: map[string]interface{}{"type": "string"}}
+	if value == "" || openAPIVariable.MatchString(value) {
+		return param
+	}
+	if number, err := strconv.ParseInt(value, 10, 64); err == nil {
+		param.Schema["type"] = "integer"
+		param.Example = number
+	} else if value == "true" || value == "false" {
+		param.Schema["type"] = "boolean"
+		param.Example = value == "true"
+	} else {
+		param.Example = value
+	}
+	return param
+}
+
+// openAPIRequestSample returns the media type and decoded body of a request
+func openAPIRequestSample(request *models.Request) (string, interface{}, bool) {
+	switch request.BodyType {
+	case "json":
+		if strings.TrimSpace(request.Body) == "" {
+			return "", nil, false
+		}
+		return "application/json", redactSecrets(openAPIDecodeBody(request.Body)), true
+	case "text":
+		if request.Body == "" {
+			return "", nil, false
+		}
+		mediaType := headerValue(request.Headers, "Content-Type")
+		if mediaType == "" {
+			mediaType = "text/plain"
+		}
+		mediaType, _, _ = strings.Cut(mediaType, ";")
+		return strings.TrimSpace(mediaType), request.Body, true
+	case "form", "multipart":
+		if len(request.FormData) == 0 {
+			return "", nil, false
+		}
+		sample := make(map[string]interface{})
+		for _, item := range request.FormData {
+			if item.Key == "" {
+				continue
+			}
+			if item.Type == "file" {
+				sample[item.Key] = openAPIFileSample{}
+				continue
+			}
+			sample[item.Key] = redactSecret(item.Key, item.Value)
+		}
+		if request.BodyType == "multipart" {
+			return "multipart/form-data", sample, true
+		}
+		return "application/x-www-form-urlencoded", sample, true
+	}
+	return "", nil, false
+}
+
+// openAPIFileSample marks a file field of a form so its schema describes binary content
+type openAPIFileSample struct{}
+
+func (openAPIFileSample) MarshalJSON() ([]byte, error) { return []byte(`"(binary)"`), nil }
+
+func (openAPIFileSample) MarshalYAML() (interface{}, error) { return "(binary)", nil }
+
+// openAPIResponseSample returns the media type and decoded body of a recorded response
+func openAPIResponseSample(response models.RequestResponse) (string, interface{}, bool) {
+	if response.Body == "" {
+		return "", nil, false
+	}
+	mediaType, _, _ := strings.Cut(headerValue(response.Headers, "Content-Type"), ";")
+	mediaType = strings.TrimSpace(strings.ToLower(mediaType))
+	if mediaType == "" {
+		if json.Valid([]byte(response.Body)) {
+			mediaType = "application/json"
+		} else {
+			mediaType = "text/plain"
+		}
+	}
+	if isJSONMediaType(mediaType) {
+		return mediaType, redactSecrets(openAPIDecodeBody(response.Body)), true
+	}
+	return mediaType, response.Body, true
+}
+
+// openAPIDecodeBody decodes a JSON body, keeping bodies that are not valid JSON (for example
+// because of unquoted {{variables}}) as text
+func openAPIDecodeBody(body string) interface{} {
+	var value interface{}
+	if err := json.Unmarshal([]byte(body), &value); err != nil {
+		return body
+	}
+	return value
+}
+
+func openAPISchemaFromSamples(mediaType string, samples []interface{}) map[string]interface{} {
+	var schema map[string]interface{}
+	for _, sample := range samples {
+		if text, ok := sample.(string); ok && isJSONMediaType(mediaType) && !json.Valid([]byte(text)) {
+			// Templated JSON says nothing reliable about its structure
+			continue
+		}
+		schema = mergeOpenAPISchemas(schema, inferOpenAPISchema(sample))
+	}
+	if schema == nil && isJSONMediaType(mediaType) {
+		return map[string]interface{}{}
+	}
+	return schema
+}
+
+// inferOpenAPISchema describes a decoded JSON value as a JSON Schema, as used by OpenAPI 3.1
+func inferOpenAPISchema(value interface{}) map[string]interface{} {
+	switch v := value.(type) {
+	case nil:
+		return map[string]interface{}{"type": "null"}
+	case bool:
+		return map[string]interface{}{"type": "boolean"}
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return map[string]interface{}{"type": "integer"}
+		}
+		return map[string]interface{}{"type": "number"}
+	case string:
+		schema := map[string]interface{}{"type": "string"}
+		switch {
+		case openAPIDateTime.MatchString(v):
+			schema["format"] = "date-time"
+		case openAPIDate.MatchString(v):
+			schema["format"] = "date"
+		case openAPIUUID.MatchString(v):
+			schema["format"] = "uuid"
+		case openAPIEmail.MatchString(v):
+			schema["format"] = "email"
+		case strings.HasPrefix(v, "http://") || strings.HasPrefix(v, "https://"):
+			schema["format"] = "uri"
+		}
+		return schema
+	case openAPIFileSample:
+		return map[string]interface{}{"type": "string", "contentMediaType": "application/octet-stream"}
+	case []interface{}:
+		schema := map[string]interface{}{"type": "array"}
+		var items map[string]interface{}
+		for _, item := range v {
+			items = mergeOpenAPISchemas(items, inferOpenAPISchema(item))
+		}
+		if items != nil {
+			schema["items"] = items
+		}
+		return schema
+	case map[string]interface{}:
+		properties := make(map[string]interface{}, len(v))
+		required := make([]interface{}, 0, len(v))
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			properties[key] = inferOpenAPISchema(v[key])
+			required = append(required, key)
+		}
+		schema := map[string]interface{}{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema
+	}
+	return map[string]interface{}{}
+}
+
+// mergeOpenAPISchemas combines the schemas of two samples of the same value. Properties missing
+// from either sample are no longer required, null makes a type nullable and other mismatches
+// become anyOf.
+func mergeOpenAPISchemas(a, b map[string]interface{}) map[string]interface{} {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if variants, ok := a["anyOf"].([]interface{}); ok {
+		return mergeOpenAPIVariant(variants, b)
+	}
+	if variants, ok := b["anyOf"].([]interface{}); ok {
+		return mergeOpenAPIVariant(variants, a)
+	}
+
+	aType, aNull := openAPISchemaType(a)
+	bType, bNull := openAPISchemaType(b)
+
+	var merged map[string]interface{}
+	baseType := aType
+	switch {
+	case aType == "":
+		merged, baseType = copyOpenAPISchema(b), bType
+	case bType == "":
+		merged = copyOpenAPISchema(a)
+	case aType == bType:
+		merged = mergeOpenAPISameType(a, b, aType)
+	case (aType == "integer" && bType == "number") || (aType == "number" && bType == "integer"):
+		merged, baseType = map[string]interface{}{}, "number"
+	default:
+		return map[string]interface{}{"anyOf": []interface{}{a, b}}
+	}
+
+	switch {
+	case baseType == "":
+		merged["type"] = "null"
+	case aNull || bNull:
+		merged["type"] = []interface{}{baseType, "null"}
+	default:
+		merged["type"] = baseType
+	}
+	return merged
+}
+
+func mergeOpenAPIVariant(variants []interface{}, schema map[string]interface{}) map[string]interface{} {
+	schemaType, _ := openAPISchemaType(schema)
+	merged := make([]interface{}, len(variants))
+	copy(merged, variants)
+	for i, variant := range merged {
+		existing, _ := variant.(map[string]interface{})
+		if existingType, _ := openAPISchemaType(existing); existingType == schemaType {
+			merged[i] = mergeOpenAPISchemas(existing, schema)
+			return map[string]interface{}{"anyOf": merged}
+		}
+	}
+	return map[string]interface{}{"anyOf": append(merged, schema)}
+}
+
+func mergeOpenAPISameType(a, b map[string]interface{}, schemaType string) map[string]interface{} {
+	merged := map[string]interface{}{}
+	switch schemaType {
+	case "object":
+		aProperties, _ := a["properties"].(map[string]interface{})
+		bProperties, _ := b["properties"].(map[string]interface{})
+		properties := make(map[string]interface{})
+		for key, value := range aProperties {
+			properties[key] = value
+		}
+		for key, value := range bProperties {
+			existing, _ := properties[key].(map[string]interface{})
+			schema, _ := value.(map[string]interface{})
+			properties[key] = mergeOpenAPISchemas(existing, schema)
+		}
+		merged["properties"] = properties
+
+		bRequired := make(map[string]bool)
+		for _, key := range listValue(b["required"]) {
+			bRequired[stringValue(key)] = true
+		}
+		var required []interface{}
+		for _, key := range listValue(a["required"]) {
+			if bRequired[stringValue(key)] {
+				required = append(required, key)
+			}
+		}
+		if len(required) > 0 {
+			merged["required"] = required
+		}
+	case "array":
+		aItems, _ := a["items"].(map[string]interface{})
+		bItems, _ := b["items"].(map[string]interface{})
+		if items := mergeOpenAPISchemas(aItems, bItems); items != nil {
+			merged["items"] = items
+		}
+	default:
+		for _, key := range []string{"format", "contentMediaType"} {
+			if a[key] != nil && a[key] == b[key] {
+				merged[key] = a[key]
+			}
+		}
+	}
+	return merged
+}
+
+// openAPISchemaType returns the non-null type of a schema and whether it allows null
+func openAPISchemaType(schema map[string]interface{}) (string, bool) {
+	var types []interface{}
+	switch value := schema["type"].(type) {
+	case string:
+		types = []interface{}{value}
+	case []interface{}:
+		types = value
+	}
+
+	schemaType, nullable := "", false
+	for _, item := range types {
+		if item == "null" {
+			nullable = true
+		} else if name, ok := item.(string); ok {
+			schemaType = name
+		}
+	}
+	return schemaType, nullable
+}
+
+func copyOpenAPISchema(schema map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(schema))
+	for key, value := range schema {
+		copied[key] = value
+	}
+	return copied
+}
+
+// openAPIOperationID converts a request name to lower camel case, such as "List users" to listUsers
+func openAPIOperationID(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var id strings.Builder
+	for i, word := range words {
+		runes := []rune(strings.ToLower(word))
+		if i > 0 {
+			runes[0] = unicode.ToUpper(runes[0])
+		}
+		id.WriteString(string(runes))
+	}
+	return id.String()
+}
+
+// marshalOpenAPIExport encodes a document as YAML, or JSON when format is "json"
+func marshalOpenAPIExport(document *OpenAPIExportDocument, format string) (string, error) {
+	switch format {
+	case "", "yaml", "yml":
+		var content bytes.Buffer
+		encoder := yaml.NewEncoder(&content)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(document); err != nil {
+			return "", err
+		}
+		encoder.Close()
+		return content.String(), nil
+	case "json":
+		content, err := json.MarshalIndent(document, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(content), nil
+	default:
+		return "", fmt.Errorf("unsupported OpenAPI format %q, expected yaml or json", format)
+	}
+}
+
+// ExportProject describes a project as an OpenAPI 3.1 document, in YAML or JSON
+func (s *OpenAPIService) ExportProject(projectID int, format string) (string, error) {
+	return s.export(projectID, nil, format)
+}
+
+// ExportFolder describes the requests of a folder and its subfolders as an OpenAPI 3.1 document
+func (s *OpenAPIService) ExportFolder(folderID int, format string) (string, error) {
+	folder, err := s.db.GetFolder(folderID)
+	if err != nil {
+		return "", fmt.Errorf("folder not found: %w", err)
+	}
+	return s.export(folder.ProjectID, &folder.ID, format)
+}
+
+// export infers schemas from request bodies, recorded history and saved response examples
+func (s *OpenAPIService) export(projectID int, rootID *int, format string) (string, error) {
+	project, err := s.db.GetProject(projectID)
+	if err != nil {
+		return "", fmt.Errorf("project not found: %w", err)
+	}
+	folders, err := s.db.GetFolders(projectID)
+	if err != nil {
+		return "", err
+	}
+	requests, err := s.db.GetRequests(projectID)
+	if err != nil {
+		return "", err
+	}
+	examples, err := s.db.GetProjectExamples(projectID)
+	if err != nil {
+		return "", err
+	}
+	variables, err := s.exportVariables(projectID)
+	if err != nil {
+		return "", err
+	}
+
+	var subtree map[int]bool
+	if rootID != nil {
+		subtree = folderSubtree(folders, *rootID)
+	}
+	saved := make(map[int][]models.RequestResponse)
+	for _, example := range examples {
+		saved[example.RequestID] = append(saved[example.RequestID], models.RequestResponse{
+			Status:  example.Status,
+			Headers: example.Headers,
+			Body:    example.Body,
+		})
+	}
+
+	info := OpenAPIExportInfo{Title: project.Name, Description: project.Description}
+	exporter := newOpenAPIExporter(info, folders, variables)
+	for i := range requests {
+		request := &requests[i]
+		if subtree != nil && (request.FolderID == nil || !subtree[*request.FolderID]) {
+			continue
+		}
+
+		history, err := s.db.GetLatestRequestHistory(request.ID, openAPIExportHistory)
+		if err != nil {
+			return "", err
+		}
+		responses := saved[request.ID]
+		for _, entry := range history {
+			responses = append(responses, entry.Response)
+		}
+		exporter.add(request, responses)
+	}
+
+	return marshalOpenAPIExport(exporter.build(), format)
+}
+
+// exportVariables returns the values used as server variable defaults, taken from the active
+// environment first and then from the other environments of the project
+func (s *OpenAPIService) exportVariables(projectID int) (map[string]string, error) {
+	environments, err := s.db.GetEnvironments(projectID)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(environments, func(i, j int) bool { return environments[i].IsActive && !environments[j].IsActive })
+
+	variables := make(map[string]string)
+	for _, environment := range environments {
+		for _, variable := range environment.Variables {
+			if _, ok := variables[variable.Key]; !ok && variable.Enabled && variable.Key != "" {
+				variables[variable.Key] = variable.Value
+			}
+		}
+	}
+	return variables, nil
+}
+
+// BuildOpenAPIRequest describes a single request as an OpenAPI 3.1 document in YAML. The
+// response schema comes from request.Response when it is set.
+func (fs *FormatService) BuildOpenAPIRequest(request *models.Request) string {
+	var responses []models.RequestResponse
+	if request.Response != nil {
+		responses = append(responses, *request.Response)
+	}
+
+	exporter := newOpenAPIExporter(OpenAPIExportInfo{Title: request.Name}, nil, nil)
+	exporter.add(request, responses)
+	content, err := marshalOpenAPIExport(exporter.build(), "yaml")
+	if err != nil {
+		return ""
+	}
+	return content
+}
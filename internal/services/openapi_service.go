@@ -0,0 +1,1147 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"rikuest/internal/database"
+	"rikuest/internal/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+// openAPIMethods lists the operations of a path item in the order they are imported
+var openAPIMethods = []string{"get", "post", "put", "patch", "delete", "head", "options", "trace"}
+
+var openAPIPathParam = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// OpenAPIOperation is an operation found in a document, listed so callers can preview and
+// select what is imported. ID is the operationId, or "METHOD path" when there is none.
+type OpenAPIOperation struct {
+	ID         string `json:"id"`
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+	Name       string `json:"name"`
+	Tag        string `json:"tag"`
+	Deprecated bool   `json:"deprecated"`
+}
+
+type OpenAPIPreview struct {
+	Title       string             `json:"title"`
+	Description string             `json:"description"`
+	Version     string             `json:"version"`
+	SpecVersion string             `json:"spec_version"`
+	Servers     []string           `json:"servers"`
+	Operations  []OpenAPIOperation `json:"operations"`
+}
+
+// OpenAPIImportOptions selects how a document is imported. An empty Operations list imports
+// every operation, and BaseURL replaces the servers declared by the document.
+type OpenAPIImportOptions struct {
+	FolderID      *int     `json:"folder_id"`
+	BaseURL       string   `json:"base_url"`
+	Operations    []string `json:"operations"`
+	CreateFolders bool     `json:"create_folders"`
+}
+
+// OpenAPIService imports OpenAPI 3 and Swagger 2 documents
+type OpenAPIService struct {
+	db *database.DB
+}
+
+func NewOpenAPIService(db *database.DB) *OpenAPIService {
+	return &OpenAPIService{db: db}
+}
+
+// openAPIDocument is a parsed document with the helpers shared by both specification versions
+type openAPIDocument struct {
+	root      map[string]interface{}
+	pathOrder []string
+	swagger   bool
+	warnings  []string
+}
+
+// openAPIServer is a base URL with the name of the environment created for it
+type openAPIServer struct {
+	name string
+	url  string
+}
+
+// Preview lists the operations of a document without importing anything
+func (s *OpenAPIService) Preview(data []byte) (*OpenAPIPreview, error) {
+	doc, err := parseOpenAPIDocument(data)
+	if err != nil {
+		return nil, err
+	}
+
+	info := mapValue(doc.root["info"])
+	preview := &OpenAPIPreview{
+		Title:       stringValue(info["title"]),
+		Description: stringValue(info["description"]),
+		Version:     stringValue(info["version"]),
+		SpecVersion: doc.specVersion(),
+		Servers:     []string{},
+		Operations:  []OpenAPIOperation{},
+	}
+	for _, server := range doc.servers() {
+		preview.Servers = append(preview.Servers, server.url)
+	}
+	doc.eachOperation(func(path, method string, pathItem, operation map[string]interface{}) {
+		preview.Operations = append(preview.Operations, doc.describeOperation(path, method, operation))
+	})
+
+	return preview, nil
+}
+
+// Import creates a request for each selected operation, with folders for tags, an environment
+// per server holding the baseUrl variable and response examples for the mock server
+func (s *OpenAPIService) Import(projectID int, data []byte, options OpenAPIImportOptions) (*models.ImportResult, error) {
+	doc, err := parseOpenAPIDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.db.GetProject(projectID); err != nil {
+		return nil, fmt.Errorf("project not found: %w", err)
+	}
+	if options.FolderID != nil {
+		folder, err := s.db.GetFolder(*options.FolderID)
+		if err != nil || folder.ProjectID != projectID {
+			return nil, fmt.Errorf("folder not found in project")
+		}
+	}
+
+	selected := make(map[string]bool, len(options.Operations))
+	for _, id := range options.Operations {
+		selected[id] = true
+	}
+
+	servers := doc.servers()
+	if options.BaseURL != "" {
+		servers = []openAPIServer{{name: "Base URL", url: options.BaseURL}}
+	}
+
+	result := &models.ImportResult{ProjectID: projectID, Warnings: []string{}}
+	target := newOpenAPITarget(projectID, options.FolderID, options.CreateFolders, len(servers) > 0)
+
+	var links []models.OpenAPIOperationLink
+	var importErr error
+	doc.eachOperation(func(path, method string, pathItem, operation map[string]interface{}) {
+		if importErr != nil {
+			return
+		}
+		described := doc.describeOperation(path, method, operation)
+		if len(selected) > 0 && !selected[described.ID] {
+			return
+		}
+
+		link, err := s.createOperation(doc, target, path, method, pathItem, operation, result)
+		if err != nil {
+			importErr = err
+			return
+		}
+		links = append(links, *link)
+	})
+	if importErr != nil {
+		return nil, importErr
+	}
+	if result.Requests == 0 {
+		return nil, fmt.Errorf("no operations were selected for import")
+	}
+
+	// Keep the document so that a later version can be compared with what was imported
+	info := mapValue(doc.root["info"])
+	spec := &models.OpenAPISpec{
+		ProjectID:     projectID,
+		Title:         stringValue(info["title"]),
+		Version:       stringValue(info["version"]),
+		Content:       string(data),
+		FolderID:      options.FolderID,
+		BaseURL:       options.BaseURL,
+		CreateFolders: options.CreateFolders,
+	}
+	if spec.Title == "" {
+		spec.Title = "OpenAPI"
+	}
+	if err := s.db.CreateOpenAPISpec(spec); err != nil {
+		return nil, fmt.Errorf("failed to save OpenAPI document: %w", err)
+	}
+	for i := range links {
+		links[i].SpecID = spec.ID
+		if err := s.db.SaveOpenAPIOperationLink(&links[i]); err != nil {
+			return nil, fmt.Errorf("failed to link request to operation %q: %w", links[i].OperationID, err)
+		}
+	}
+	result.SpecID = spec.ID
+
+	if err := s.createEnvironments(projectID, servers, target.authVariables, result); err != nil {
+		return nil, err
+	}
+
+	result.Warnings = append(result.Warnings, doc.warnings...)
+	return result, nil
+}
+
+// openAPITarget is where the requests of a document are created
+type openAPITarget struct {
+	projectID     int
+	folderID      *int
+	createFolders bool
+	baseURL       string
+	folders       map[string]*int
+	authVariables map[string]bool
+}
+
+// newOpenAPITarget prefixes request URLs with {{baseUrl}} when the document has servers
+func newOpenAPITarget(projectID int, folderID *int, createFolders, hasServers bool) *openAPITarget {
+	target := &openAPITarget{
+		projectID:     projectID,
+		folderID:      folderID,
+		createFolders: createFolders,
+		folders:       make(map[string]*int),
+		authVariables: make(map[string]bool),
+	}
+	if hasServers {
+		target.baseURL = "{{baseUrl}}"
+	}
+	return target
+}
+
+// tagDescription returns the description the document gives a tag in its tags list
+func (doc *openAPIDocument) tagDescription(name string) string {
+	for _, tag := range listValue(doc.root["tags"]) {
+		if entry := mapValue(tag); stringValue(entry["name"]) == name {
+			return stringValue(entry["description"])
+		}
+	}
+	return ""
+}
+
+// generateRequest maps an operation to a named request without saving it
+func (doc *openAPIDocument) generateRequest(target *openAPITarget, path, method string, pathItem, operation map[string]interface{}) *models.Request {
+	request := doc.buildRequest(path, method, pathItem, operation, target.baseURL, target.authVariables)
+	request.Name = doc.describeOperation(path, method, operation).Name
+	request.Description = stringValue(operation["description"])
+	request.ProjectID = target.projectID
+	request.FolderID = target.folderID
+	return request
+}
+
+// createOperation creates the request and response examples of an operation and returns the
+// link to save once the document is stored
+func (s *OpenAPIService) createOperation(doc *openAPIDocument, target *openAPITarget, path, method string, pathItem, operation map[string]interface{}, result *models.ImportResult) (*models.OpenAPIOperationLink, error) {
+	described := doc.describeOperation(path, method, operation)
+	request := doc.generateRequest(target, path, method, pathItem, operation)
+	snapshot := *request
+
+	if target.createFolders && described.Tag != "" {
+		folderID, err := s.ensureTagFolder(target.projectID, target.folderID, described.Tag, doc.tagDescription(described.Tag), target.folders, result)
+		if err != nil {
+			return nil, err
+		}
+		request.FolderID = folderID
+	}
+
+	if err := s.db.CreateRequest(request); err != nil {
+		return nil, fmt.Errorf("failed to create request %q: %w", request.Name, err)
+	}
+	result.Requests++
+
+	for _, example := range doc.responseExamples(operation) {
+		example.RequestID = request.ID
+		if err := s.db.CreateRequestExample(&example); err != nil {
+			return nil, fmt.Errorf("failed to create example for %q: %w", request.Name, err)
+		}
+		result.Examples++
+	}
+
+	return &models.OpenAPIOperationLink{
+		RequestID:   request.ID,
+		OperationID: described.ID,
+		Method:      described.Method,
+		Path:        path,
+		Snapshot:    snapshot,
+	}, nil
+}
+
+// ensureTagFolder returns the folder for a tag, reusing a folder of the same name so that
+// importing a document again does not duplicate its folders
+func (s *OpenAPIService) ensureTagFolder(projectID int, parentID *int, tag, description string, folders map[string]*int, result *models.ImportResult) (*int, error) {
+	if folderID, ok := folders[tag]; ok {
+		return folderID, nil
+	}
+
+	existing, err := s.db.GetFolders(projectID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range existing {
+		if existing[i].Name == tag && sameFolder(existing[i].ParentID, parentID) {
+			folders[tag] = &existing[i].ID
+			return folders[tag], nil
+		}
+	}
+
+	folder := &models.Folder{ProjectID: projectID, Name: tag, Description: description, ParentID: parentID}
+	if err := s.db.CreateFolder(folder); err != nil {
+		return nil, fmt.Errorf("failed to create folder %q: %w", tag, err)
+	}
+	result.Folders++
+	folders[tag] = &folder.ID
+	return folders[tag], nil
+}
+
+// createEnvironments adds an environment per server with the baseUrl variable and empty
+// variables for the credentials referenced by the imported auth settings
+func (s *OpenAPIService) createEnvironments(projectID int, servers []openAPIServer, authVariables map[string]bool, result *models.ImportResult) error {
+	if len(servers) == 0 && len(authVariables) > 0 {
+		servers = []openAPIServer{{name: "OpenAPI"}}
+	}
+
+	active, err := s.db.GetActiveEnvironment(projectID)
+	if err != nil {
+		return err
+	}
+
+	names := make(map[string]int)
+	for _, server := range servers {
+		name := server.name
+		if names[name]++; names[name] > 1 {
+			name = fmt.Sprintf("%s (%d)", name, names[name])
+		}
+
+		environment := &models.Environment{ProjectID: projectID, Name: name, Variables: []models.Variable{}}
+		if server.url != "" {
+			environment.Variables = append(environment.Variables, models.Variable{Key: "baseUrl", Value: server.url, Enabled: true})
+		}
+		for _, key := range sortedBoolKeys(authVariables) {
+			environment.Variables = append(environment.Variables, models.Variable{Key: key, Enabled: true})
+		}
+
+		if err := s.db.CreateEnvironment(environment); err != nil {
+			return fmt.Errorf("failed to create environment %q: %w", name, err)
+		}
+		if active == nil {
+			if err := s.db.SetActiveEnvironment(projectID, &environment.ID); err != nil {
+				return err
+			}
+			active = environment
+		}
+		result.Environments++
+	}
+	return nil
+}
+
+// parseOpenAPIDocument reads a JSON or YAML document and checks its specification version
+func parseOpenAPIDocument(data []byte) (*openAPIDocument, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document, expected JSON or YAML: %w", err)
+	}
+
+	var decoded interface{}
+	if err := node.Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+	root, ok := normalizeYAML(decoded).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid OpenAPI document: expected an object")
+	}
+
+	doc := &openAPIDocument{root: root}
+	switch {
+	case strings.HasPrefix(stringValue(root["swagger"]), "2."):
+		doc.swagger = true
+	case strings.HasPrefix(stringValue(root["openapi"]), "3."):
+	default:
+		return nil, fmt.Errorf("unsupported document: expected OpenAPI 3 or Swagger 2")
+	}
+
+	// Go maps lose the order of paths, so read it from the YAML node
+	if len(node.Content) > 0 {
+		doc.pathOrder = mappingKeys(mappingChild(node.Content[0], "paths"))
+	}
+	return doc, nil
+}
+
+func (doc *openAPIDocument) specVersion() string {
+	if doc.swagger {
+		return stringValue(doc.root["swagger"])
+	}
+	return stringValue(doc.root["openapi"])
+}
+
+func (doc *openAPIDocument) warn(format string, args ...interface{}) {
+	warning := fmt.Sprintf(format, args...)
+	for _, existing := range doc.warnings {
+		if existing == warning {
+			return
+		}
+	}
+	doc.warnings = append(doc.warnings, warning)
+}
+
+// servers returns the base URLs of the document, with server variables set to their defaults
+func (doc *openAPIDocument) servers() []openAPIServer {
+	if doc.swagger {
+		host := stringValue(doc.root["host"])
+		basePath := strings.TrimSuffix(stringValue(doc.root["basePath"]), "/")
+		if host == "" && basePath == "" {
+			return nil
+		}
+		if host == "" {
+			return []openAPIServer{{name: basePath, url: basePath}}
+		}
+
+		scheme := "https"
+		if schemes := listValue(doc.root["schemes"]); len(schemes) > 0 {
+			scheme = stringValue(schemes[0])
+		}
+		url := scheme + "://" + host + basePath
+		return []openAPIServer{{name: url, url: url}}
+	}
+
+	var servers []openAPIServer
+	for _, item := range listValue(doc.root["servers"]) {
+		server := mapValue(item)
+		url := stringValue(server["url"])
+		if url == "" {
+			continue
+		}
+		variables := mapValue(server["variables"])
+		url = openAPIPathParam.ReplaceAllStringFunc(url, func(match string) string {
+			variable := mapValue(variables[match[1:len(match)-1]])
+			if value, ok := variable["default"]; ok {
+				return stringValue(value)
+			}
+			return match
+		})
+		url = strings.TrimSuffix(url, "/")
+
+		name := stringValue(server["description"])
+		if name == "" {
+			name = url
+		}
+		servers = append(servers, openAPIServer{name: name, url: url})
+	}
+	return servers
+}
+
+// eachOperation calls fn for every operation in document order
+func (doc *openAPIDocument) eachOperation(fn func(path, method string, pathItem, operation map[string]interface{})) {
+	paths := mapValue(doc.root["paths"])
+
+	order := doc.pathOrder
+	if len(order) != len(paths) {
+		order = make([]string, 0, len(paths))
+		for path := range paths {
+			order = append(order, path)
+		}
+		sort.Strings(order)
+	}
+
+	for _, path := range order {
+		pathItem := doc.resolve(paths[path])
+		if pathItem == nil {
+			continue
+		}
+		if _, ok := pathItem["servers"]; ok {
+			doc.warn("%s: path-level servers are not supported, requests use the document servers", path)
+		}
+		for _, method := range openAPIMethods {
+			if operation := doc.resolve(pathItem[method]); operation != nil {
+				fn(path, method, pathItem, operation)
+			}
+		}
+	}
+}
+
+func (doc *openAPIDocument) describeOperation(path, method string, operation map[string]interface{}) OpenAPIOperation {
+	described := OpenAPIOperation{
+		ID:     stringValue(operation["operationId"]),
+		Method: strings.ToUpper(method),
+		Path:   path,
+		Name:   stringValue(operation["summary"]),
+	}
+	if described.ID == "" {
+		described.ID = described.Method + " " + path
+	}
+	if described.Name == "" {
+		described.Name = stringValue(operation["operationId"])
+	}
+	if described.Name == "" {
+		described.Name = described.Method + " " + path
+	}
+	if tags := listValue(operation["tags"]); len(tags) > 0 {
+		described.Tag = stringValue(tags[0])
+	}
+	described.Deprecated, _ = operation["deprecated"].(bool)
+	return described
+}
+
+// buildRequest maps an operation to a request. Path parameters without an example become
+// {{variables}} and the names of credentials variables are collected in authVariables.
+func (doc *openAPIDocument) buildRequest(path, method string, pathItem, operation map[string]interface{}, baseURL string, authVariables map[string]bool) *models.Request {
+	request := &models.Request{
+		Method:      strings.ToUpper(method),
+		Headers:     make(map[string]string),
+		QueryParams: []models.QueryParam{},
+		AuthType:    "none",
+		BodyType:    "none",
+		FormData:    []models.FormData{},
+	}
+
+	parameters := doc.parameters(pathItem, operation)
+	var cookies []string
+	var bodyParameter map[string]interface{}
+	var formParameters []map[string]interface{}
+
+	for _, param := range parameters {
+		name := stringValue(param["name"])
+		value, hasValue := doc.parameterExample(param)
+
+		switch stringValue(param["in"]) {
+		case "path":
+			if hasValue {
+				path = strings.ReplaceAll(path, "{"+name+"}", value)
+			}
+		case "query":
+			required, _ := param["required"].(bool)
+			request.QueryParams = append(request.QueryParams, models.QueryParam{Key: name, Value: value, Enabled: required || hasValue})
+		case "header":
+			// Content-Type, Accept and Authorization are described elsewhere in the document
+			if !strings.EqualFold(name, "Authorization") {
+				request.Headers[name] = value
+			}
+		case "cookie":
+			cookies = append(cookies, name+"="+value)
+		case "body":
+			bodyParameter = param
+		case "formData":
+			formParameters = append(formParameters, param)
+		}
+	}
+	if len(cookies) > 0 {
+		request.Headers["Cookie"] = strings.Join(cookies, "; ")
+	}
+
+	// Path parameters without an example become variables
+	request.URL = baseURL + openAPIPathParam.ReplaceAllString(path, "{{$1}}")
+
+	if doc.swagger {
+		doc.applySwaggerBody(request, operation, bodyParameter, formParameters)
+	} else {
+		doc.applyRequestBody(request, doc.resolve(operation["requestBody"]))
+	}
+	doc.applySecurity(request, operation, authVariables)
+
+	return request
+}
+
+// parameters merges path-level and operation-level parameters, the latter taking precedence
+func (doc *openAPIDocument) parameters(pathItem, operation map[string]interface{}) []map[string]interface{} {
+	var parameters []map[string]interface{}
+	index := make(map[string]int)
+
+	for _, list := range [][]interface{}{listValue(pathItem["parameters"]), listValue(operation["parameters"])} {
+		for _, item := range list {
+			param := doc.resolve(item)
+			if param == nil {
+				continue
+			}
+			key := stringValue(param["in"]) + ":" + stringValue(param["name"])
+			if i, ok := index[key]; ok {
+				parameters[i] = param
+				continue
+			}
+			index[key] = len(parameters)
+			parameters = append(parameters, param)
+		}
+	}
+	return parameters
+}
+
+// parameterExample returns the example, default or first enum value of a parameter
+func (doc *openAPIDocument) parameterExample(param map[string]interface{}) (string, bool) {
+	if value, ok := param["example"]; ok {
+		return stringValue(value), true
+	}
+	for _, item := range mapValue(param["examples"]) {
+		if example := doc.resolve(item); example != nil {
+			if value, ok := example["value"]; ok {
+				return stringValue(value), true
+			}
+		}
+	}
+
+	schema := doc.resolve(param["schema"])
+	if schema == nil {
+		// Swagger 2 describes non-body parameters inline
+		schema = param
+	}
+	for _, key := range []string{"example", "default"} {
+		if value, ok := schema[key]; ok {
+			return stringValue(value), true
+		}
+	}
+	if enum := listValue(schema["enum"]); len(enum) > 0 {
+		return stringValue(enum[0]), true
+	}
+	return "", false
+}
+
+// applyRequestBody fills the body of an OpenAPI 3 request from the preferred media type
+func (doc *openAPIDocument) applyRequestBody(request *models.Request, requestBody map[string]interface{}) {
+	content := mapValue(requestBody["content"])
+	mediaType := preferredMediaType(content)
+	if mediaType == "" {
+		return
+	}
+	media := mapValue(content[mediaType])
+	schema := media["schema"]
+
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		request.BodyType = "form"
+		request.FormData = doc.formFields(schema, false)
+	case mediaType == "multipart/form-data":
+		request.BodyType = "multipart"
+		request.FormData = doc.formFields(schema, true)
+	default:
+		example, ok := doc.mediaExample(media)
+		if !ok {
+			example = doc.schemaExample(schema, nil)
+		}
+		request.BodyType = "text"
+		if isJSONMediaType(mediaType) {
+			request.BodyType = "json"
+		}
+		request.Body = exampleText(example, request.BodyType == "json")
+		request.Headers["Content-Type"] = mediaType
+	}
+}
+
+// applySwaggerBody fills the body of a Swagger 2 request from its body or formData parameters
+func (doc *openAPIDocument) applySwaggerBody(request *models.Request, operation map[string]interface{}, bodyParameter map[string]interface{}, formParameters []map[string]interface{}) {
+	consumes := listValue(operation["consumes"])
+	if len(consumes) == 0 {
+		consumes = listValue(doc.root["consumes"])
+	}
+	mediaType := "application/json"
+	if len(consumes) > 0 {
+		mediaType = stringValue(consumes[0])
+	}
+
+	if bodyParameter != nil {
+		example := doc.schemaExample(bodyParameter["schema"], nil)
+		request.BodyType = "text"
+		if isJSONMediaType(mediaType) {
+			request.BodyType = "json"
+		}
+		request.Body = exampleText(example, request.BodyType == "json")
+		request.Headers["Content-Type"] = mediaType
+		return
+	}
+	if len(formParameters) == 0 {
+		return
+	}
+
+	request.BodyType = "form"
+	for _, consumed := range consumes {
+		if stringValue(consumed) == "multipart/form-data" {
+			request.BodyType = "multipart"
+		}
+	}
+	for _, param := range formParameters {
+		value, _ := doc.parameterExample(param)
+		item := models.FormData{Key: stringValue(param["name"]), Value: value}
+		if stringValue(param["type"]) == "file" {
+			request.BodyType = "multipart"
+			item.Value, item.Type = "", "file"
+		}
+		request.FormData = append(request.FormData, item)
+	}
+}
+
+// formFields lists the properties of a form schema. Binary properties become file fields
+// when the body is multipart.
+func (doc *openAPIDocument) formFields(schema interface{}, multipart bool) []models.FormData {
+	fields := []models.FormData{}
+	properties := doc.schemaProperties(schema, nil)
+	for _, name := range sortedMapKeys(properties) {
+		property := doc.resolve(properties[name])
+		if multipart && doc.isBinarySchema(property) {
+			fields = append(fields, models.FormData{Key: name, Type: "file"})
+			continue
+		}
+
+		value := doc.schemaExample(properties[name], nil)
+		text := stringValue(value)
+		if _, ok := value.(string); !ok && value != nil {
+			encoded, _ := json.Marshal(value)
+			text = string(encoded)
+		}
+		fields = append(fields, models.FormData{Key: name, Value: text})
+	}
+	return fields
+}
+
+// applySecurity maps the first security requirement of the operation, or of the document,
+// to the request auth settings with {{variables}} for the credentials
+func (doc *openAPIDocument) applySecurity(request *models.Request, operation map[string]interface{}, authVariables map[string]bool) {
+	requirements, ok := operation["security"]
+	if !ok {
+		requirements = doc.root["security"]
+	}
+	list := listValue(requirements)
+	if len(list) == 0 {
+		return
+	}
+	requirement := mapValue(list[0])
+	if len(requirement) == 0 {
+		return
+	}
+	name := sortedMapKeys(requirement)[0]
+
+	definitions := mapValue(mapValue(doc.root["components"])["securitySchemes"])
+	if doc.swagger {
+		definitions = mapValue(doc.root["securityDefinitions"])
+	}
+	scheme := doc.resolve(definitions[name])
+	if scheme == nil {
+		doc.warn("security scheme %q is not defined", name)
+		return
+	}
+
+	switch strings.ToLower(stringValue(scheme["type"])) {
+	case "http":
+		switch strings.ToLower(stringValue(scheme["scheme"])) {
+		case "bearer":
+			request.AuthType = "bearer"
+			request.BearerToken = "{{bearerToken}}"
+			authVariables["bearerToken"] = true
+		case "basic":
+			request.AuthType = "basic"
+			request.BasicAuth = models.BasicAuth{Username: "{{username}}", Password: "{{password}}"}
+			authVariables["username"], authVariables["password"] = true, true
+		default:
+			doc.warn("HTTP %s authentication is not supported", stringValue(scheme["scheme"]))
+		}
+	case "basic":
+		request.AuthType = "basic"
+		request.BasicAuth = models.BasicAuth{Username: "{{username}}", Password: "{{password}}"}
+		authVariables["username"], authVariables["password"] = true, true
+	case "apikey":
+		keyName := stringValue(scheme["name"])
+		authVariables["apiKey"] = true
+		switch stringValue(scheme["in"]) {
+		case "query":
+			request.QueryParams = append(request.QueryParams, models.QueryParam{Key: keyName, Value: "{{apiKey}}", Enabled: true})
+		case "cookie":
+			cookie := keyName + "={{apiKey}}"
+			if existing := request.Headers["Cookie"]; existing != "" {
+				cookie = existing + "; " + cookie
+			}
+			request.Headers["Cookie"] = cookie
+		default:
+			request.Headers[keyName] = "{{apiKey}}"
+		}
+	case "oauth2", "openidconnect":
+		// The token has to be obtained outside Rikuest and is sent as a bearer token
+		request.AuthType = "bearer"
+		request.BearerToken = "{{accessToken}}"
+		authVariables["accessToken"] = true
+	default:
+		doc.warn("%s authentication is not supported", stringValue(scheme["type"]))
+	}
+}
+
+// responseExamples builds an example per documented status code from explicit examples or
+// from the response schema
+func (doc *openAPIDocument) responseExamples(operation map[string]interface{}) []models.ResponseExample {
+	responses := mapValue(operation["responses"])
+	var examples []models.ResponseExample
+
+	for _, code := range sortedMapKeys(responses) {
+		status, err := strconv.Atoi(code)
+		if err != nil {
+			continue
+		}
+		response := doc.resolve(responses[code])
+		if response == nil {
+			continue
+		}
+
+		var mediaType string
+		var example interface{}
+		found := false
+		if doc.swagger {
+			for _, candidate := range sortedMapKeys(mapValue(response["examples"])) {
+				mediaType, example, found = candidate, mapValue(response["examples"])[candidate], true
+				break
+			}
+			if !found && response["schema"] != nil {
+				mediaType, example, found = "application/json", doc.schemaExample(response["schema"], nil), true
+			}
+		} else {
+			content := mapValue(response["content"])
+			mediaType = preferredMediaType(content)
+			if mediaType != "" {
+				media := mapValue(content[mediaType])
+				example, found = doc.mediaExample(media)
+				if !found && media["schema"] != nil {
+					example, found = doc.schemaExample(media["schema"], nil), true
+				}
+			}
+		}
+		if !found {
+			continue
+		}
+
+		name := strings.TrimSpace(stringValue(response["description"]))
+		if name == "" || len(name) > 80 {
+			name = http.StatusText(status)
+		}
+		examples = append(examples, models.ResponseExample{
+			Name:    fmt.Sprintf("%d %s", status, name),
+			Status:  status,
+			Headers: map[string]string{"Content-Type": mediaType},
+			Body:    exampleText(example, isJSONMediaType(mediaType)),
+		})
+	}
+	return examples
+}
+
+// mediaExample returns the example of a media type object, or the first of its named examples
+func (doc *openAPIDocument) mediaExample(media map[string]interface{}) (interface{}, bool) {
+	if example, ok := media["example"]; ok {
+		return example, true
+	}
+	examples := mapValue(media["examples"])
+	for _, name := range sortedMapKeys(examples) {
+		if example := doc.resolve(examples[name]); example != nil {
+			if value, ok := example["value"]; ok {
+				return value, true
+			}
+			if external := stringValue(example["externalValue"]); external != "" {
+				doc.warn("external example %s is not supported", external)
+			}
+		}
+	}
+	return nil, false
+}
+
+// schemaExample generates a value for a schema, preferring the examples and defaults it declares.
+// visiting holds the references being expanded so recursive schemas stop instead of looping.
+func (doc *openAPIDocument) schemaExample(node interface{}, visiting map[string]bool) interface{} {
+	schema, visiting, ok := doc.enterSchema(node, visiting)
+	if !ok {
+		return nil
+	}
+
+	if value, ok := schema["example"]; ok {
+		return value
+	}
+	if examples := listValue(schema["examples"]); len(examples) > 0 {
+		return examples[0]
+	}
+	for _, key := range []string{"default", "const"} {
+		if value, ok := schema[key]; ok {
+			return value
+		}
+	}
+	if enum := listValue(schema["enum"]); len(enum) > 0 {
+		return enum[0]
+	}
+
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if options := listValue(schema[key]); len(options) > 0 {
+			return doc.schemaExample(options[0], visiting)
+		}
+	}
+
+	switch schemaType(schema) {
+	case "object":
+		object := make(map[string]interface{})
+		for name, property := range doc.schemaProperties(schema, visiting) {
+			if value := doc.schemaExample(property, visiting); value != nil {
+				object[name] = value
+			}
+		}
+		return object
+	case "array":
+		item := doc.schemaExample(schema["items"], visiting)
+		if item == nil {
+			return []interface{}{}
+		}
+		return []interface{}{item}
+	case "string":
+		switch stringValue(schema["format"]) {
+		case "date-time":
+			return "2024-01-01T00:00:00Z"
+		case "date":
+			return "2024-01-01"
+		case "email":
+			return "user@example.com"
+		case "uuid":
+			return "00000000-0000-0000-0000-000000000000"
+		case "uri", "url":
+			return "https://example.com"
+		case "binary", "byte":
+			return ""
+		}
+		return "string"
+	case "integer", "number":
+		if minimum, ok := schema["minimum"]; ok {
+			return minimum
+		}
+		return 0
+	case "boolean":
+		return false
+	}
+	return nil
+}
+
+// schemaProperties returns the properties of an object schema, merging allOf members
+func (doc *openAPIDocument) schemaProperties(node interface{}, visiting map[string]bool) map[string]interface{} {
+	properties := make(map[string]interface{})
+	schema, visiting, ok := doc.enterSchema(node, visiting)
+	if !ok {
+		return properties
+	}
+	for _, member := range listValue(schema["allOf"]) {
+		for name, property := range doc.schemaProperties(member, visiting) {
+			properties[name] = property
+		}
+	}
+	for name, property := range mapValue(schema["properties"]) {
+		properties[name] = property
+	}
+	return properties
+}
+
+// enterSchema resolves a schema node, returning false when it is missing or is a reference
+// already being expanded. The returned set includes the node's reference.
+func (doc *openAPIDocument) enterSchema(node interface{}, visiting map[string]bool) (map[string]interface{}, map[string]bool, bool) {
+	if ref, ok := mapValue(node)["$ref"].(string); ok {
+		if visiting[ref] {
+			return nil, visiting, false
+		}
+		next := make(map[string]bool, len(visiting)+1)
+		for key := range visiting {
+			next[key] = true
+		}
+		next[ref] = true
+		visiting = next
+	}
+
+	schema := doc.resolve(node)
+	return schema, visiting, schema != nil
+}
+
+// resolve follows local $ref pointers and returns the referenced object. External references
+// are reported and resolve to nil.
+func (doc *openAPIDocument) resolve(node interface{}) map[string]interface{} {
+	value := mapValue(node)
+	for hops := 0; value != nil; hops++ {
+		ref, ok := value["$ref"].(string)
+		if !ok {
+			return value
+		}
+		if hops > 32 {
+			doc.warn("reference %s is circular", ref)
+			return nil
+		}
+		if !strings.HasPrefix(ref, "#/") {
+			doc.warn("external reference %s is not supported", ref)
+			return nil
+		}
+
+		var current interface{} = doc.root
+		for _, token := range strings.Split(ref[2:], "/") {
+			token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+			current = mapValue(current)[token]
+		}
+		if current == nil {
+			doc.warn("reference %s could not be resolved", ref)
+			return nil
+		}
+		value = mapValue(current)
+	}
+	return value
+}
+
+func schemaType(schema map[string]interface{}) string {
+	switch value := schema["type"].(type) {
+	case string:
+		return value
+	case []interface{}:
+		// OpenAPI 3.1 allows a list such as ["string", "null"]
+		for _, item := range value {
+			if text := stringValue(item); text != "null" {
+				return text
+			}
+		}
+	}
+	if schema["properties"] != nil || schema["allOf"] != nil {
+		return "object"
+	}
+	if schema["items"] != nil {
+		return "array"
+	}
+	return ""
+}
+
+func (doc *openAPIDocument) isBinarySchema(schema map[string]interface{}) bool {
+	if schema == nil {
+		return false
+	}
+	if schemaType(schema) == "array" {
+		return doc.isBinarySchema(doc.resolve(schema["items"]))
+	}
+	format := stringValue(schema["format"])
+	return schemaType(schema) == "file" || format == "binary" || format == "base64" ||
+		schema["contentMediaType"] != nil
+}
+
+// preferredMediaType picks JSON, then forms, then text, then the first media type by name
+func preferredMediaType(content map[string]interface{}) string {
+	types := sortedMapKeys(content)
+	for _, match := range []func(string) bool{
+		isJSONMediaType,
+		func(t string) bool { return t == "application/x-www-form-urlencoded" },
+		func(t string) bool { return t == "multipart/form-data" },
+		func(t string) bool { return strings.HasPrefix(t, "text/") },
+		func(t string) bool { return true },
+	} {
+		for _, mediaType := range types {
+			if match(mediaType) {
+				return mediaType
+			}
+		}
+	}
+	return ""
+}
+
+func isJSONMediaType(mediaType string) bool {
+	mediaType = strings.ToLower(strings.TrimSpace(strings.Split(mediaType, ";")[0]))
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") || mediaType == "*/*"
+}
+
+// exampleText writes an example as a body, indenting JSON values
+func exampleText(example interface{}, asJSON bool) string {
+	if text, ok := example.(string); ok && !asJSON {
+		return text
+	}
+	if example == nil {
+		if asJSON {
+			return "{}"
+		}
+		return ""
+	}
+	encoded, err := json.MarshalIndent(example, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
+
+// normalizeYAML converts the map[interface{}]interface{} values produced for YAML mappings with
+// non-string keys, such as response codes, into map[string]interface{}
+func normalizeYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalizeYAML(item)
+		}
+		return v
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			converted[fmt.Sprint(key)] = normalizeYAML(item)
+		}
+		return converted
+	case time.Time:
+		// Unquoted dates are decoded as timestamps; write them back as they were likely written
+		if v.Equal(v.Truncate(24 * time.Hour)) {
+			return v.Format("2006-01-02")
+		}
+		return v.Format(time.RFC3339)
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeYAML(item)
+		}
+		return v
+	}
+	return value
+}
+
+// mappingChild returns the value node of a key in a YAML mapping node
+func mappingChild(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// mappingKeys returns the keys of a YAML mapping node in document order
+func mappingKeys(node *yaml.Node) []string {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	keys := make([]string, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keys = append(keys, node.Content[i].Value)
+	}
+	return keys
+}
+
+func mapValue(value interface{}) map[string]interface{} {
+	result, _ := value.(map[string]interface{})
+	return result
+}
+
+func listValue(value interface{}) []interface{} {
+	result, _ := value.([]interface{})
+	return result
+}
+
+// stringValue converts a scalar document value to text
+func stringValue(value interface{}) string {
+	switch v := value.(type) {
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return postmanValue(value)
+	}
+}
+
+func sortedMapKeys(values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedBoolKeys(values map[string]bool) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
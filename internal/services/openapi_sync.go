@@ -0,0 +1,423 @@
+package services
+
+import (
+	"fmt"
+
+	"rikuest/internal/database"
+	"rikuest/internal/models"
+)
+
+// OpenAPIChange is an operation added, removed or changed by a new version of a document.
+// LocalEdits reports that its request was edited since the last import; applying the change
+// keeps those edits.
+type OpenAPIChange struct {
+	OperationID string               `json:"operation_id"`
+	Method      string               `json:"method"`
+	Path        string               `json:"path"`
+	Name        string               `json:"name"`
+	Tag         string               `json:"tag"`
+	RequestID   int                  `json:"request_id,omitempty"`
+	Fields      []RequestFieldChange `json:"fields,omitempty"`
+	LocalEdits  bool                 `json:"local_edits"`
+}
+
+type OpenAPIDiff struct {
+	SpecID          int             `json:"spec_id"`
+	Title           string          `json:"title"`
+	Version         string          `json:"version"`
+	PreviousVersion string          `json:"previous_version"`
+	Added           []OpenAPIChange `json:"added"`
+	Removed         []OpenAPIChange `json:"removed"`
+	Changed         []OpenAPIChange `json:"changed"`
+	Unchanged       int             `json:"unchanged"`
+}
+
+// OpenAPISyncOptions selects the changes to apply by the operation IDs reported in the diff
+type OpenAPISyncOptions struct {
+	Add    []string `json:"add"`
+	Update []string `json:"update"`
+	Remove []string `json:"remove"`
+}
+
+type OpenAPISyncResult struct {
+	SpecID   int      `json:"spec_id"`
+	Added    int      `json:"added"`
+	Updated  int      `json:"updated"`
+	Removed  int      `json:"removed"`
+	Folders  int      `json:"folders"`
+	Examples int      `json:"examples"`
+	Warnings []string `json:"warnings"`
+}
+
+// openAPISyncPlan is the diff of a spec together with what is needed to apply it
+type openAPISyncPlan struct {
+	spec    *models.OpenAPISpec
+	doc     *openAPIDocument
+	target  *openAPITarget
+	diff    *OpenAPIDiff
+	added   map[string]openAPIPendingOperation
+	changed map[string]openAPIPendingOperation
+	removed map[string]models.OpenAPIOperationLink
+}
+
+// openAPIPendingOperation is an operation of the new document with the request it generates
+// and, for changed operations, the link to the request created from its previous version
+type openAPIPendingOperation struct {
+	path      string
+	method    string
+	pathItem  map[string]interface{}
+	operation map[string]interface{}
+	described OpenAPIOperation
+	generated *models.Request
+	link      models.OpenAPIOperationLink
+	current   *models.Request
+}
+
+// GetSpecs lists the documents imported into a project
+func (s *OpenAPIService) GetSpecs(projectID int) ([]models.OpenAPISpec, error) {
+	specs, err := s.db.GetOpenAPISpecs(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if specs == nil {
+		specs = []models.OpenAPISpec{}
+	}
+	return specs, nil
+}
+
+// DeleteSpec forgets an imported document. Its requests are kept but no longer synced.
+func (s *OpenAPIService) DeleteSpec(specID int) error {
+	if _, err := s.db.GetOpenAPISpec(specID); err != nil {
+		return fmt.Errorf("OpenAPI document not found: %w", err)
+	}
+	return s.db.DeleteOpenAPISpec(specID)
+}
+
+// Diff compares a new version of an imported document with the requests created from it
+func (s *OpenAPIService) Diff(specID int, data []byte) (*OpenAPIDiff, error) {
+	plan, err := s.plan(specID, data)
+	if err != nil {
+		return nil, err
+	}
+	return plan.diff, nil
+}
+
+// Sync applies the selected changes of a new version of an imported document. Changed requests
+// are merged field by field: fields edited locally since the last import are kept, the rest
+// follow the document. The changes are applied in one transaction, so a failure leaves the
+// project as it was.
+func (s *OpenAPIService) Sync(specID int, data []byte, options OpenAPISyncOptions) (*OpenAPISyncResult, error) {
+	var result *OpenAPISyncResult
+	err := s.db.InTransaction(func(tx *database.DB) error {
+		var err error
+		result, err = (&OpenAPIService{db: tx}).sync(specID, data, options)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *OpenAPIService) sync(specID int, data []byte, options OpenAPISyncOptions) (*OpenAPISyncResult, error) {
+	plan, err := s.plan(specID, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the selection before changing anything
+	for _, id := range options.Add {
+		if _, ok := plan.added[id]; !ok {
+			return nil, fmt.Errorf("operation %q is not an added operation", id)
+		}
+	}
+	for _, id := range options.Update {
+		if _, ok := plan.changed[id]; !ok {
+			return nil, fmt.Errorf("operation %q is not a changed operation", id)
+		}
+	}
+	for _, id := range options.Remove {
+		if _, ok := plan.removed[id]; !ok {
+			return nil, fmt.Errorf("operation %q is not a removed operation", id)
+		}
+	}
+
+	result := &OpenAPISyncResult{SpecID: specID, Warnings: []string{}}
+	imported := &models.ImportResult{Warnings: []string{}}
+
+	for _, id := range options.Add {
+		pending := plan.added[id]
+		link, err := s.createOperation(plan.doc, plan.target, pending.path, pending.method, pending.pathItem, pending.operation, imported)
+		if err != nil {
+			return nil, err
+		}
+		link.SpecID = specID
+		if err := s.db.SaveOpenAPIOperationLink(link); err != nil {
+			return nil, fmt.Errorf("failed to link request to operation %q: %w", id, err)
+		}
+		result.Added++
+	}
+
+	for _, id := range options.Update {
+		pending := plan.changed[id]
+		merged, kept := mergeOpenAPIRequest(&pending.link.Snapshot, pending.current, pending.generated)
+		for _, field := range kept {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: kept local %s", pending.current.Name, field))
+		}
+		if err := s.db.UpdateRequestBy(merged, "openapi-sync"); err != nil {
+			return nil, fmt.Errorf("failed to update request %q: %w", merged.Name, err)
+		}
+
+		link := pending.link
+		link.OperationID = pending.described.ID
+		link.Method = pending.described.Method
+		link.Path = pending.path
+		link.Snapshot = *pending.generated
+		if err := s.db.SaveOpenAPIOperationLink(&link); err != nil {
+			return nil, fmt.Errorf("failed to link request to operation %q: %w", id, err)
+		}
+		result.Updated++
+	}
+
+	for _, id := range options.Remove {
+		link := plan.removed[id]
+		if err := s.db.DeleteRequest(link.RequestID); err != nil {
+			return nil, fmt.Errorf("failed to delete request for operation %q: %w", id, err)
+		}
+		if err := s.db.DeleteOpenAPIOperationLink(link.RequestID); err != nil {
+			return nil, err
+		}
+		result.Removed++
+	}
+
+	info := mapValue(plan.doc.root["info"])
+	plan.spec.Content = string(data)
+	plan.spec.Version = stringValue(info["version"])
+	if title := stringValue(info["title"]); title != "" {
+		plan.spec.Title = title
+	}
+	if err := s.db.UpdateOpenAPISpec(plan.spec); err != nil {
+		return nil, fmt.Errorf("failed to save OpenAPI document: %w", err)
+	}
+
+	if len(options.Add) > 0 || len(options.Update) > 0 {
+		if err := s.warnMissingVariables(plan.spec.ProjectID, plan.target.authVariables, result); err != nil {
+			return nil, err
+		}
+	}
+
+	result.Folders = imported.Folders
+	result.Examples = imported.Examples
+	result.Warnings = append(result.Warnings, plan.doc.warnings...)
+	return result, nil
+}
+
+// plan matches the operations of the new document with the links of the spec, by operationId
+// first and then by method and path so that renaming an operationId is seen as a change
+func (s *OpenAPIService) plan(specID int, data []byte) (*openAPISyncPlan, error) {
+	spec, err := s.db.GetOpenAPISpec(specID)
+	if err != nil {
+		return nil, fmt.Errorf("OpenAPI document not found: %w", err)
+	}
+	doc, err := parseOpenAPIDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	links, err := s.db.GetOpenAPIOperationLinks(specID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Added operations go where the document was imported, or to the root if that folder is gone
+	folderID := spec.FolderID
+	if folderID != nil {
+		if folder, err := s.db.GetFolder(*folderID); err != nil || folder.ProjectID != spec.ProjectID {
+			folderID = nil
+		}
+	}
+
+	hasServers := spec.BaseURL != "" || len(doc.servers()) > 0
+	info := mapValue(doc.root["info"])
+	plan := &openAPISyncPlan{
+		spec:   spec,
+		doc:    doc,
+		target: newOpenAPITarget(spec.ProjectID, folderID, spec.CreateFolders, hasServers),
+		diff: &OpenAPIDiff{
+			SpecID:          specID,
+			Title:           stringValue(info["title"]),
+			Version:         stringValue(info["version"]),
+			PreviousVersion: spec.Version,
+			Added:           []OpenAPIChange{},
+			Removed:         []OpenAPIChange{},
+			Changed:         []OpenAPIChange{},
+		},
+		added:   make(map[string]openAPIPendingOperation),
+		changed: make(map[string]openAPIPendingOperation),
+		removed: make(map[string]models.OpenAPIOperationLink),
+	}
+
+	byID := make(map[string]int)
+	byRoute := make(map[string]int)
+	for i, link := range links {
+		byID[link.OperationID] = i
+		byRoute[link.Method+" "+link.Path] = i
+	}
+	matched := make(map[int]bool)
+
+	var planErr error
+	doc.eachOperation(func(path, method string, pathItem, operation map[string]interface{}) {
+		if planErr != nil {
+			return
+		}
+		described := doc.describeOperation(path, method, operation)
+		pending := openAPIPendingOperation{
+			path:      path,
+			method:    method,
+			pathItem:  pathItem,
+			operation: operation,
+			described: described,
+			generated: doc.generateRequest(plan.target, path, method, pathItem, operation),
+		}
+		change := OpenAPIChange{
+			OperationID: described.ID,
+			Method:      described.Method,
+			Path:        path,
+			Name:        described.Name,
+			Tag:         described.Tag,
+		}
+
+		index, ok := byID[described.ID]
+		if !ok || matched[index] {
+			index, ok = byRoute[described.Method+" "+path]
+		}
+		if !ok || matched[index] {
+			if _, duplicate := plan.added[described.ID]; !duplicate {
+				plan.added[described.ID] = pending
+				plan.diff.Added = append(plan.diff.Added, change)
+			}
+			return
+		}
+		matched[index] = true
+
+		link := links[index]
+		change.Fields = diffRequestFields(requestFields(&link.Snapshot), requestFields(pending.generated))
+		if link.OperationID != described.ID {
+			change.Fields = append([]RequestFieldChange{{Field: "operation", Kind: "changed", Before: link.OperationID, After: described.ID}}, change.Fields...)
+		}
+		if len(change.Fields) == 0 {
+			plan.diff.Unchanged++
+			return
+		}
+
+		current, err := s.db.GetRequest(link.RequestID)
+		if err != nil {
+			planErr = fmt.Errorf("failed to load request for operation %q: %w", described.ID, err)
+			return
+		}
+		change.RequestID = link.RequestID
+		change.Name = current.Name
+		change.LocalEdits = len(diffRequestFields(requestFields(&link.Snapshot), requestFields(current))) > 0
+
+		pending.link = link
+		pending.current = current
+		plan.changed[described.ID] = pending
+		plan.diff.Changed = append(plan.diff.Changed, change)
+	})
+	if planErr != nil {
+		return nil, planErr
+	}
+
+	for i, link := range links {
+		if matched[i] {
+			continue
+		}
+		change := OpenAPIChange{
+			OperationID: link.OperationID,
+			Method:      link.Method,
+			Path:        link.Path,
+			Name:        link.Snapshot.Name,
+			RequestID:   link.RequestID,
+		}
+		if current, err := s.db.GetRequest(link.RequestID); err == nil {
+			change.Name = current.Name
+			change.LocalEdits = len(diffRequestFields(requestFields(&link.Snapshot), requestFields(current))) > 0
+		}
+		plan.removed[link.OperationID] = link
+		plan.diff.Removed = append(plan.diff.Removed, change)
+	}
+
+	return plan, nil
+}
+
+// warnMissingVariables reports credentials variables used by the document that the active
+// environment does not define
+func (s *OpenAPIService) warnMissingVariables(projectID int, variables map[string]bool, result *OpenAPISyncResult) error {
+	environment, err := s.db.GetActiveEnvironment(projectID)
+	if err != nil {
+		return err
+	}
+	defined := make(map[string]bool)
+	if environment != nil {
+		for _, variable := range environment.Variables {
+			defined[variable.Key] = true
+		}
+	}
+	for _, key := range sortedBoolKeys(variables) {
+		if !defined[key] {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("variable {{%s}} is not defined in the active environment", key))
+		}
+	}
+	return nil
+}
+
+// mergeOpenAPIRequest rebuilds local with the changes from base to remote, a three-way merge
+// per field in which local edits win. It returns the request and the fields where a change of
+// the document was dropped in favour of a local edit.
+func mergeOpenAPIRequest(base, local, remote *models.Request) (*models.Request, []string) {
+	baseFields := requestFieldIndex(requestFields(base))
+	localList := requestFields(local)
+	localFields := requestFieldIndex(localList)
+	remoteList := requestFields(remote)
+	remoteFields := requestFieldIndex(remoteList)
+
+	var picked []requestField
+	var kept []string
+	for _, field := range remoteList {
+		baseField, inBase := baseFields[field.name]
+		localField, inLocal := localFields[field.name]
+		switch {
+		case !inLocal && inBase:
+			// Deleted locally
+			if baseField.value != field.value {
+				kept = append(kept, field.name)
+			}
+		case !inLocal:
+			picked = append(picked, field)
+		case inBase && localField.value == baseField.value:
+			picked = append(picked, field)
+		default:
+			// Edited or added locally
+			if localField.value != field.value && (!inBase || baseField.value != field.value) {
+				kept = append(kept, field.name)
+			}
+			picked = append(picked, localField)
+		}
+	}
+	for _, field := range localList {
+		if _, ok := remoteFields[field.name]; ok {
+			continue
+		}
+		// Removed from the document, kept only if edited or added locally
+		if baseField, inBase := baseFields[field.name]; inBase {
+			if baseField.value == field.value {
+				continue
+			}
+			kept = append(kept, field.name)
+		}
+		picked = append(picked, field)
+	}
+
+	merged := *local
+	applyRequestFields(&merged, picked)
+	return &merged, kept
+}
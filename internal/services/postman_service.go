@@ -0,0 +1,903 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"rikuest/internal/database"
+	"rikuest/internal/models"
+
+	"github.com/google/uuid"
+)
+
+const postmanCollectionSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// Postman Collection v2.1 document types (https://schema.postman.com/)
+type PostmanCollection struct {
+	Info     PostmanInfo       `json:"info"`
+	Item     []PostmanItem     `json:"item"`
+	Variable []PostmanVariable `json:"variable,omitempty"`
+	Auth     *PostmanAuth      `json:"auth,omitempty"`
+	Event    []json.RawMessage `json:"event,omitempty"`
+}
+
+type PostmanInfo struct {
+	PostmanID   string          `json:"_postman_id,omitempty"`
+	Name        string          `json:"name"`
+	Description json.RawMessage `json:"description,omitempty"`
+	Schema      string          `json:"schema"`
+}
+
+type PostmanItem struct {
+	Name        string            `json:"name"`
+	Description json.RawMessage   `json:"description,omitempty"`
+	Item        []PostmanItem     `json:"item,omitempty"`
+	Request     *PostmanRequest   `json:"request,omitempty"`
+	Response    []PostmanResponse `json:"response,omitempty"`
+	Auth        *PostmanAuth      `json:"auth,omitempty"`
+	Event       []json.RawMessage `json:"event,omitempty"`
+}
+
+type PostmanRequest struct {
+	Method      string            `json:"method"`
+	Header      []PostmanKeyValue `json:"header"`
+	URL         PostmanURL        `json:"url"`
+	Body        *PostmanBody      `json:"body,omitempty"`
+	Auth        *PostmanAuth      `json:"auth,omitempty"`
+	Description json.RawMessage   `json:"description,omitempty"`
+}
+
+// UnmarshalJSON accepts the short form where a request is just its URL
+func (r *PostmanRequest) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		*r = PostmanRequest{Method: "GET", URL: PostmanURL{Raw: raw}}
+		return nil
+	}
+
+	type plain PostmanRequest
+	var request plain
+	if err := json.Unmarshal(data, &request); err != nil {
+		return err
+	}
+	*r = PostmanRequest(request)
+	return nil
+}
+
+type PostmanURL struct {
+	Raw      string            `json:"raw"`
+	Protocol string            `json:"protocol,omitempty"`
+	Host     json.RawMessage   `json:"host,omitempty"`
+	Path     json.RawMessage   `json:"path,omitempty"`
+	Port     string            `json:"port,omitempty"`
+	Query    []PostmanKeyValue `json:"query,omitempty"`
+	Variable []PostmanKeyValue `json:"variable,omitempty"`
+}
+
+// UnmarshalJSON accepts URLs given either as a string or as an object
+func (u *PostmanURL) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		*u = PostmanURL{Raw: raw}
+		return nil
+	}
+
+	type plain PostmanURL
+	var parsed plain
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return err
+	}
+	*u = PostmanURL(parsed)
+	return nil
+}
+
+type PostmanKeyValue struct {
+	Key         string          `json:"key"`
+	Value       string          `json:"value"`
+	Disabled    bool            `json:"disabled,omitempty"`
+	Type        string          `json:"type,omitempty"`
+	Src         json.RawMessage `json:"src,omitempty"`
+	Description json.RawMessage `json:"description,omitempty"`
+}
+
+type PostmanVariable struct {
+	Key      string      `json:"key"`
+	Value    interface{} `json:"value"`
+	Type     string      `json:"type,omitempty"`
+	Disabled bool        `json:"disabled,omitempty"`
+}
+
+type PostmanBody struct {
+	Mode       string              `json:"mode"`
+	Raw        string              `json:"raw,omitempty"`
+	URLEncoded []PostmanKeyValue   `json:"urlencoded,omitempty"`
+	FormData   []PostmanKeyValue   `json:"formdata,omitempty"`
+	GraphQL    *PostmanGraphQL     `json:"graphql,omitempty"`
+	File       json.RawMessage     `json:"file,omitempty"`
+	Options    *PostmanBodyOptions `json:"options,omitempty"`
+	Disabled   bool                `json:"disabled,omitempty"`
+}
+
+type PostmanGraphQL struct {
+	Query     string `json:"query"`
+	Variables string `json:"variables,omitempty"`
+}
+
+type PostmanBodyOptions struct {
+	Raw struct {
+		Language string `json:"language"`
+	} `json:"raw"`
+}
+
+type PostmanAuth struct {
+	Type   string                 `json:"type"`
+	Bearer []PostmanAuthAttribute `json:"bearer,omitempty"`
+	Basic  []PostmanAuthAttribute `json:"basic,omitempty"`
+	APIKey []PostmanAuthAttribute `json:"apikey,omitempty"`
+}
+
+type PostmanAuthAttribute struct {
+	Key   string      `json:"key"`
+	Value interface{} `json:"value"`
+	Type  string      `json:"type,omitempty"`
+}
+
+type PostmanResponse struct {
+	Name            string            `json:"name"`
+	OriginalRequest *PostmanRequest   `json:"originalRequest,omitempty"`
+	Status          string            `json:"status,omitempty"`
+	Code            int               `json:"code,omitempty"`
+	Header          []PostmanKeyValue `json:"header,omitempty"`
+	Body            string            `json:"body,omitempty"`
+}
+
+type PostmanEnvironment struct {
+	ID     string                    `json:"id,omitempty"`
+	Name   string                    `json:"name"`
+	Values []PostmanEnvironmentValue `json:"values"`
+	Scope  string                    `json:"_postman_variable_scope,omitempty"`
+}
+
+type PostmanEnvironmentValue struct {
+	Key     string      `json:"key"`
+	Value   interface{} `json:"value"`
+	Type    string      `json:"type,omitempty"`
+	Enabled bool        `json:"enabled"`
+}
+
+// PostmanService imports and exports Postman collections and environments
+type PostmanService struct {
+	db *database.DB
+}
+
+func NewPostmanService(db *database.DB) *PostmanService {
+	return &PostmanService{db: db}
+}
+
+// postmanImporter carries the state of a single collection import
+type postmanImporter struct {
+	db     *database.DB
+	result *models.ImportResult
+}
+
+// ImportCollection imports a Postman v2.0/v2.1 collection under folderID of a project.
+// A projectID of 0 creates a new project named after the collection.
+func (s *PostmanService) ImportCollection(projectID int, folderID *int, data []byte) (*models.ImportResult, error) {
+	var collection PostmanCollection
+	if err := json.Unmarshal(data, &collection); err != nil {
+		return nil, fmt.Errorf("invalid Postman collection: %w", err)
+	}
+	if collection.Info.Schema != "" && !strings.Contains(collection.Info.Schema, "v2.") {
+		return nil, fmt.Errorf("unsupported Postman collection schema %q, export the collection as v2.1", collection.Info.Schema)
+	}
+
+	// A failure part way leaves no partial import behind
+	var result *models.ImportResult
+	err := s.db.InTransaction(func(tx *database.DB) error {
+		var err error
+		result, err = (&PostmanService{db: tx}).importCollection(projectID, folderID, &collection)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *PostmanService) importCollection(projectID int, folderID *int, collection *PostmanCollection) (*models.ImportResult, error) {
+	if projectID == 0 {
+		name := collection.Info.Name
+		if name == "" {
+			name = "Postman Collection"
+		}
+		name, err := uniqueProjectName(s.db, name)
+		if err != nil {
+			return nil, err
+		}
+		project := &models.Project{Name: name, Description: postmanText(collection.Info.Description)}
+		if err := s.db.CreateProject(project); err != nil {
+			return nil, err
+		}
+		projectID = project.ID
+	} else if _, err := s.db.GetProject(projectID); err != nil {
+		return nil, fmt.Errorf("project not found: %w", err)
+	}
+
+	importer := &postmanImporter{
+		db:     s.db,
+		result: &models.ImportResult{ProjectID: projectID, Warnings: []string{}},
+	}
+
+	if len(collection.Event) > 0 {
+		importer.warn("Collection scripts are not supported and were skipped")
+	}
+
+	if err := importer.importItems(projectID, folderID, collection.Item, collection.Auth, ""); err != nil {
+		return nil, err
+	}
+
+	if len(collection.Variable) > 0 {
+		environment := &models.Environment{
+			ProjectID: projectID,
+			Name:      collection.Info.Name,
+			Variables: []models.Variable{},
+		}
+		if environment.Name == "" {
+			environment.Name = "Collection variables"
+		}
+		for _, variable := range collection.Variable {
+			environment.Variables = append(environment.Variables, models.Variable{
+				Key:     variable.Key,
+				Value:   postmanValue(variable.Value),
+				Enabled: !variable.Disabled,
+			})
+		}
+		if err := importer.createEnvironment(environment); err != nil {
+			return nil, err
+		}
+	}
+
+	return importer.result, nil
+}
+
+// ImportEnvironment imports a Postman environment file as an environment of the project
+func (s *PostmanService) ImportEnvironment(projectID int, data []byte) (*models.ImportResult, error) {
+	var postmanEnvironment PostmanEnvironment
+	if err := json.Unmarshal(data, &postmanEnvironment); err != nil {
+		return nil, fmt.Errorf("invalid Postman environment: %w", err)
+	}
+
+	if _, err := s.db.GetProject(projectID); err != nil {
+		return nil, fmt.Errorf("project not found: %w", err)
+	}
+
+	environment := &models.Environment{
+		ProjectID: projectID,
+		Name:      postmanEnvironment.Name,
+		Variables: []models.Variable{},
+	}
+	if environment.Name == "" {
+		environment.Name = "Postman environment"
+	}
+	for _, value := range postmanEnvironment.Values {
+		environment.Variables = append(environment.Variables, models.Variable{
+			Key:     value.Key,
+			Value:   postmanValue(value.Value),
+			Enabled: value.Enabled,
+		})
+	}
+
+	importer := &postmanImporter{
+		db:     s.db,
+		result: &models.ImportResult{ProjectID: projectID, Warnings: []string{}},
+	}
+	if err := importer.createEnvironment(environment); err != nil {
+		return nil, err
+	}
+	return importer.result, nil
+}
+
+func (imp *postmanImporter) importItems(projectID int, parentID *int, items []PostmanItem, inheritedAuth *PostmanAuth, path string) error {
+	for _, item := range items {
+		itemPath := item.Name
+		if path != "" {
+			itemPath = path + "/" + item.Name
+		}
+
+		if len(item.Event) > 0 {
+			imp.warn("%s: pre-request and test scripts are not supported and were skipped", itemPath)
+		}
+
+		if item.Request == nil {
+			folder := &models.Folder{
+				ProjectID:   projectID,
+				Name:        item.Name,
+				Description: postmanText(item.Description),
+				ParentID:    parentID,
+			}
+			if err := imp.db.CreateFolder(folder); err != nil {
+				return fmt.Errorf("failed to create folder %q: %w", itemPath, err)
+			}
+			imp.result.Folders++
+
+			auth := inheritedAuth
+			if item.Auth != nil && item.Auth.Type != "inherit" {
+				auth = item.Auth
+			}
+			if err := imp.importItems(projectID, &folder.ID, item.Item, auth, itemPath); err != nil {
+				return err
+			}
+			continue
+		}
+
+		request := imp.convertRequest(item.Name, item.Request, inheritedAuth, itemPath)
+		request.ProjectID = projectID
+		request.FolderID = parentID
+		// Postman keeps the description on the request, older collections on the item
+		request.Description = postmanText(item.Request.Description)
+		if request.Description == "" {
+			request.Description = postmanText(item.Description)
+		}
+		if err := imp.db.CreateRequest(request); err != nil {
+			return fmt.Errorf("failed to create request %q: %w", itemPath, err)
+		}
+		imp.result.Requests++
+
+		for _, response := range item.Response {
+			example := &models.ResponseExample{
+				RequestID: request.ID,
+				Name:      response.Name,
+				Status:    response.Code,
+				Headers:   make(map[string]string),
+				Body:      response.Body,
+			}
+			if example.Name == "" {
+				example.Name = response.Status
+			}
+			if example.Status == 0 {
+				example.Status = 200
+			}
+			for _, header := range response.Header {
+				if !header.Disabled {
+					example.Headers[header.Key] = header.Value
+				}
+			}
+			if err := imp.db.CreateRequestExample(example); err != nil {
+				return fmt.Errorf("failed to create example %q of %q: %w", example.Name, itemPath, err)
+			}
+			imp.result.Examples++
+		}
+	}
+
+	return nil
+}
+
+// convertRequest maps a Postman request to a Rikuest request, recording warnings for anything lost
+func (imp *postmanImporter) convertRequest(name string, source *PostmanRequest, inheritedAuth *PostmanAuth, path string) *models.Request {
+	request := &models.Request{
+		Name:        name,
+		Method:      strings.ToUpper(source.Method),
+		Headers:     make(map[string]string),
+		QueryParams: []models.QueryParam{},
+		AuthType:    "none",
+		BodyType:    "none",
+		FormData:    []models.FormData{},
+	}
+	if request.Method == "" {
+		request.Method = "GET"
+	}
+
+	rawURL := source.URL.Raw
+	if rawURL == "" {
+		rawURL = postmanURLFromParts(source.URL)
+	}
+	base, rawQuery, hasQuery := strings.Cut(rawURL, "?")
+	request.URL = base
+	if len(source.URL.Query) > 0 {
+		for _, param := range source.URL.Query {
+			request.QueryParams = append(request.QueryParams, models.QueryParam{Key: param.Key, Value: param.Value, Enabled: !param.Disabled})
+		}
+	} else if hasQuery {
+		request.QueryParams = parseRawQuery(rawQuery)
+	}
+
+	for _, variable := range source.URL.Variable {
+		if variable.Value != "" {
+			imp.warn("%s: path variable :%s default value %q was not kept", path, variable.Key, variable.Value)
+		}
+	}
+
+	for _, header := range source.Header {
+		if header.Disabled {
+			imp.warn("%s: disabled header %q was skipped", path, header.Key)
+			continue
+		}
+		request.Headers[header.Key] = header.Value
+	}
+
+	auth := inheritedAuth
+	if source.Auth != nil && source.Auth.Type != "inherit" {
+		auth = source.Auth
+	}
+	imp.applyAuth(request, auth, path)
+
+	if source.Body != nil && !source.Body.Disabled {
+		imp.applyBody(request, source.Body, path)
+	}
+
+	return request
+}
+
+func (imp *postmanImporter) applyAuth(request *models.Request, auth *PostmanAuth, path string) {
+	if auth == nil {
+		return
+	}
+
+	switch auth.Type {
+	case "noauth", "":
+	case "bearer":
+		request.AuthType = "bearer"
+		request.BearerToken = postmanAuthValue(auth.Bearer, "token")
+	case "basic":
+		request.AuthType = "basic"
+		request.BasicAuth = models.BasicAuth{
+			Username: postmanAuthValue(auth.Basic, "username"),
+			Password: postmanAuthValue(auth.Basic, "password"),
+		}
+	case "apikey":
+		key := postmanAuthValue(auth.APIKey, "key")
+		value := postmanAuthValue(auth.APIKey, "value")
+		if postmanAuthValue(auth.APIKey, "in") == "query" {
+			request.QueryParams = append(request.QueryParams, models.QueryParam{Key: key, Value: value, Enabled: true})
+		} else {
+			request.Headers[key] = value
+		}
+	default:
+		imp.warn("%s: %s authentication is not supported and was skipped", path, auth.Type)
+	}
+}
+
+func (imp *postmanImporter) applyBody(request *models.Request, body *PostmanBody, path string) {
+	switch body.Mode {
+	case "raw":
+		if body.Raw == "" {
+			return
+		}
+		request.Body = body.Raw
+		language := ""
+		if body.Options != nil {
+			language = body.Options.Raw.Language
+		}
+		contentType := headerValue(request.Headers, "Content-Type")
+		if language == "json" || strings.Contains(contentType, "json") {
+			request.BodyType = "json"
+		} else {
+			request.BodyType = "text"
+		}
+		// Postman derives Content-Type from the raw language when the header is missing
+		if contentType == "" {
+			switch language {
+			case "json":
+				request.Headers["Content-Type"] = "application/json"
+			case "xml":
+				request.Headers["Content-Type"] = "application/xml"
+			case "html":
+				request.Headers["Content-Type"] = "text/html"
+			case "javascript":
+				request.Headers["Content-Type"] = "application/javascript"
+			default:
+				request.Headers["Content-Type"] = "text/plain"
+			}
+		}
+	case "urlencoded":
+		request.BodyType = "form"
+		for _, item := range body.URLEncoded {
+			if item.Disabled {
+				imp.warn("%s: disabled form field %q was skipped", path, item.Key)
+				continue
+			}
+			request.FormData = append(request.FormData, models.FormData{Key: item.Key, Value: item.Value})
+		}
+	case "formdata":
+		request.BodyType = "multipart"
+		for _, item := range body.FormData {
+			if item.Disabled {
+				imp.warn("%s: disabled form field %q was skipped", path, item.Key)
+				continue
+			}
+			if item.Type == "file" {
+				// Paths are files of the machine the collection comes from, so they are not kept
+				if len(stringOrList(item.Src)) > 0 {
+					imp.warn("%s: file field %q refers to a local file, upload it again", path, item.Key)
+				} else {
+					imp.warn("%s: file field %q has no file selected", path, item.Key)
+				}
+				request.FormData = append(request.FormData, models.FormData{Key: item.Key, Type: "file"})
+				continue
+			}
+			request.FormData = append(request.FormData, models.FormData{Key: item.Key, Value: item.Value})
+		}
+	case "graphql":
+		if body.GraphQL == nil {
+			return
+		}
+		payload := map[string]interface{}{"query": body.GraphQL.Query}
+		if strings.TrimSpace(body.GraphQL.Variables) != "" {
+			payload["variables"] = json.RawMessage(body.GraphQL.Variables)
+		}
+		encoded, err := json.MarshalIndent(payload, "", "  ")
+		if err != nil {
+			imp.warn("%s: GraphQL variables are not valid JSON and were skipped", path)
+			encoded, _ = json.MarshalIndent(map[string]string{"query": body.GraphQL.Query}, "", "  ")
+		}
+		request.BodyType = "json"
+		request.Body = string(encoded)
+		if headerValue(request.Headers, "Content-Type") == "" {
+			request.Headers["Content-Type"] = "application/json"
+		}
+	case "file":
+		imp.warn("%s: binary file bodies are not supported and were skipped", path)
+	default:
+		imp.warn("%s: body mode %q is not supported and was skipped", path, body.Mode)
+	}
+}
+
+// createEnvironment stores an imported environment, activating it when the project has none active
+func (imp *postmanImporter) createEnvironment(environment *models.Environment) error {
+	active, err := imp.db.GetActiveEnvironment(environment.ProjectID)
+	if err != nil {
+		return err
+	}
+	if err := imp.db.CreateEnvironment(environment); err != nil {
+		return fmt.Errorf("failed to create environment %q: %w", environment.Name, err)
+	}
+	if active == nil {
+		if err := imp.db.SetActiveEnvironment(environment.ProjectID, &environment.ID); err != nil {
+			return err
+		}
+		environment.IsActive = true
+	}
+	imp.result.Environments++
+	return nil
+}
+
+func (imp *postmanImporter) warn(format string, args ...interface{}) {
+	imp.result.Warnings = append(imp.result.Warnings, fmt.Sprintf(format, args...))
+}
+
+// ExportCollection exports a project as a Postman v2.1 collection, including saved examples
+func (s *PostmanService) ExportCollection(projectID int) (*PostmanCollection, error) {
+	project, err := s.db.GetProject(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("project not found: %w", err)
+	}
+	folders, err := s.db.GetFolders(projectID)
+	if err != nil {
+		return nil, err
+	}
+	requests, err := s.db.GetRequests(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	collection := &PostmanCollection{
+		Info: PostmanInfo{
+			PostmanID: uuid.NewString(),
+			Name:      project.Name,
+			Schema:    postmanCollectionSchema,
+		},
+		Item: []PostmanItem{},
+	}
+	if project.Description != "" {
+		collection.Info.Description, _ = json.Marshal(project.Description)
+	}
+
+	collection.Item, err = s.exportItems(nil, folders, requests)
+	if err != nil {
+		return nil, err
+	}
+	return collection, nil
+}
+
+// exportItems builds the items of one level of the tree, keeping the position order of folders and requests
+func (s *PostmanService) exportItems(parentID *int, folders []models.Folder, requests []models.Request) ([]PostmanItem, error) {
+	type positioned struct {
+		position int
+		item     PostmanItem
+	}
+	var entries []positioned
+
+	for _, folder := range folders {
+		if !sameFolder(folder.ParentID, parentID) {
+			continue
+		}
+		children, err := s.exportItems(&folder.ID, folders, requests)
+		if err != nil {
+			return nil, err
+		}
+		item := PostmanItem{Name: folder.Name, Item: children}
+		if folder.Description != "" {
+			item.Description, _ = json.Marshal(folder.Description)
+		}
+		entries = append(entries, positioned{folder.Position, item})
+	}
+
+	for i := range requests {
+		request := &requests[i]
+		if !sameFolder(request.FolderID, parentID) {
+			continue
+		}
+		item, err := s.exportRequest(request)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, positioned{request.Position, item})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].position < entries[j].position })
+
+	items := make([]PostmanItem, 0, len(entries))
+	for _, entry := range entries {
+		items = append(items, entry.item)
+	}
+	return items, nil
+}
+
+func (s *PostmanService) exportRequest(request *models.Request) (PostmanItem, error) {
+	postmanRequest := &PostmanRequest{
+		Method: request.Method,
+		Header: []PostmanKeyValue{},
+		URL:    PostmanURL{Raw: request.URL},
+	}
+	if request.Description != "" {
+		postmanRequest.Description, _ = json.Marshal(request.Description)
+	}
+
+	keys := make([]string, 0, len(request.Headers))
+	for key := range request.Headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		postmanRequest.Header = append(postmanRequest.Header, PostmanKeyValue{Key: key, Value: request.Headers[key]})
+	}
+
+	var enabledQuery []string
+	for _, param := range request.QueryParams {
+		postmanRequest.URL.Query = append(postmanRequest.URL.Query, PostmanKeyValue{Key: param.Key, Value: param.Value, Disabled: !param.Enabled})
+		if param.Enabled {
+			enabledQuery = append(enabledQuery, param.Key+"="+param.Value)
+		}
+	}
+	if len(enabledQuery) > 0 {
+		separator := "?"
+		if strings.Contains(request.URL, "?") {
+			separator = "&"
+		}
+		postmanRequest.URL.Raw = request.URL + separator + strings.Join(enabledQuery, "&")
+	}
+
+	switch request.AuthType {
+	case "bearer":
+		postmanRequest.Auth = &PostmanAuth{
+			Type:   "bearer",
+			Bearer: []PostmanAuthAttribute{{Key: "token", Value: request.BearerToken, Type: "string"}},
+		}
+	case "basic":
+		postmanRequest.Auth = &PostmanAuth{
+			Type: "basic",
+			Basic: []PostmanAuthAttribute{
+				{Key: "username", Value: request.BasicAuth.Username, Type: "string"},
+				{Key: "password", Value: request.BasicAuth.Password, Type: "string"},
+			},
+		}
+	default:
+		postmanRequest.Auth = &PostmanAuth{Type: "noauth"}
+	}
+
+	switch request.BodyType {
+	case "form":
+		body := &PostmanBody{Mode: "urlencoded", URLEncoded: []PostmanKeyValue{}}
+		for _, item := range request.FormData {
+			body.URLEncoded = append(body.URLEncoded, PostmanKeyValue{Key: item.Key, Value: item.Value, Type: "text"})
+		}
+		postmanRequest.Body = body
+	case "multipart":
+		body := &PostmanBody{Mode: "formdata", FormData: []PostmanKeyValue{}}
+		for _, item := range request.FormData {
+			if item.Type == "file" {
+				src, _ := json.Marshal(item.Value)
+				body.FormData = append(body.FormData, PostmanKeyValue{Key: item.Key, Type: "file", Src: src})
+				continue
+			}
+			body.FormData = append(body.FormData, PostmanKeyValue{Key: item.Key, Value: item.Value, Type: "text"})
+		}
+		postmanRequest.Body = body
+	case "json", "text":
+		if request.Body != "" {
+			body := &PostmanBody{Mode: "raw", Raw: request.Body, Options: &PostmanBodyOptions{}}
+			body.Options.Raw.Language = "text"
+			if request.BodyType == "json" {
+				body.Options.Raw.Language = "json"
+			}
+			postmanRequest.Body = body
+		}
+	}
+
+	item := PostmanItem{Name: request.Name, Request: postmanRequest, Response: []PostmanResponse{}}
+
+	examples, err := s.db.GetRequestExamples(request.ID)
+	if err != nil {
+		return item, err
+	}
+	for _, example := range examples {
+		response := PostmanResponse{
+			Name:            example.Name,
+			OriginalRequest: postmanRequest,
+			Status:          httpStatusText(example.Status),
+			Code:            example.Status,
+			Header:          []PostmanKeyValue{},
+			Body:            example.Body,
+		}
+		for _, key := range sortedKeys(example.Headers) {
+			response.Header = append(response.Header, PostmanKeyValue{Key: key, Value: example.Headers[key]})
+		}
+		item.Response = append(item.Response, response)
+	}
+
+	return item, nil
+}
+
+// ExportEnvironment exports an environment in Postman's environment format
+func (s *PostmanService) ExportEnvironment(environmentID int) (*PostmanEnvironment, error) {
+	environment, err := s.db.GetEnvironment(environmentID)
+	if err != nil {
+		return nil, fmt.Errorf("environment not found: %w", err)
+	}
+
+	postmanEnvironment := &PostmanEnvironment{
+		ID:     uuid.NewString(),
+		Name:   environment.Name,
+		Values: []PostmanEnvironmentValue{},
+		Scope:  "environment",
+	}
+	for _, variable := range environment.Variables {
+		postmanEnvironment.Values = append(postmanEnvironment.Values, PostmanEnvironmentValue{
+			Key:     variable.Key,
+			Value:   variable.Value,
+			Type:    "default",
+			Enabled: variable.Enabled,
+		})
+	}
+	return postmanEnvironment, nil
+}
+
+// postmanText reads a description given either as a string or as {"content": "..."}
+func postmanText(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+
+	var described struct {
+		Content string `json:"content"`
+	}
+	if err := json.Unmarshal(raw, &described); err == nil {
+		return described.Content
+	}
+	return ""
+}
+
+// postmanValue converts a variable value of any JSON type to its string form
+func postmanValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		encoded, _ := json.Marshal(v)
+		return string(encoded)
+	}
+}
+
+func postmanAuthValue(attributes []PostmanAuthAttribute, key string) string {
+	for _, attribute := range attributes {
+		if attribute.Key == key {
+			return postmanValue(attribute.Value)
+		}
+	}
+	return ""
+}
+
+// postmanURLFromParts rebuilds a URL from its host and path segments when raw is missing
+func postmanURLFromParts(parts PostmanURL) string {
+	host := strings.Join(stringOrList(parts.Host), ".")
+	path := strings.Join(stringOrList(parts.Path), "/")
+
+	var builder strings.Builder
+	if parts.Protocol != "" {
+		builder.WriteString(parts.Protocol + "://")
+	}
+	builder.WriteString(host)
+	if parts.Port != "" {
+		builder.WriteString(":" + parts.Port)
+	}
+	if path != "" {
+		builder.WriteString("/" + strings.TrimPrefix(path, "/"))
+	}
+	return builder.String()
+}
+
+func stringOrList(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return list
+	}
+
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return []string{single}
+	}
+	return nil
+}
+
+// parseRawQuery splits a query string without decoding it, so {{variables}} survive untouched
+func parseRawQuery(rawQuery string) []models.QueryParam {
+	params := []models.QueryParam{}
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(pair, "=")
+		if decoded, err := url.QueryUnescape(key); err == nil && !strings.Contains(key, "{{") {
+			key = decoded
+		}
+		if decoded, err := url.QueryUnescape(value); err == nil && !strings.Contains(value, "{{") {
+			value = decoded
+		}
+		params = append(params, models.QueryParam{Key: key, Value: value, Enabled: true})
+	}
+	return params
+}
+
+func sameFolder(a *int, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func httpStatusText(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return strconv.Itoa(status)
+	}
+	return text
+}
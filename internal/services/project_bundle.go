@@ -0,0 +1,271 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"rikuest/internal/database"
+	"rikuest/internal/models"
+)
+
+const (
+	ProjectBundleFormat  = "rikuest-project"
+	ProjectBundleVersion = 1
+)
+
+// ProjectBundle is a whole project in a single JSON file, used to share a project or move it
+// to another machine. IDs are the ones of the exporting database: they only link the items of
+// the bundle together and are replaced on import.
+type ProjectBundle struct {
+	Format       string                   `json:"format"`
+	Version      int                      `json:"version"`
+	ExportedAt   time.Time                `json:"exported_at"`
+	Project      models.Project           `json:"project"`
+	Folders      []models.Folder          `json:"folders"`
+	Requests     []models.Request         `json:"requests"`
+	Examples     []models.ResponseExample `json:"examples"`
+	Environments []models.Environment     `json:"environments"`
+	Tags         []models.Tag             `json:"tags,omitempty"`
+	History      []models.RequestHistory  `json:"history,omitempty"`
+}
+
+// bundleHistoryLimit is how many of the latest executions of each request a bundle carries
+const bundleHistoryLimit = 100
+
+// ExportProject bundles a project with its folders, requests, examples, environments and tags.
+// History is only included when includeHistory is set.
+func (s *ProjectService) ExportProject(id int, includeHistory bool) (*ProjectBundle, error) {
+	project, err := s.db.GetProject(id)
+	if err != nil {
+		return nil, fmt.Errorf("project not found: %w", err)
+	}
+
+	bundle := &ProjectBundle{
+		Format:       ProjectBundleFormat,
+		Version:      ProjectBundleVersion,
+		ExportedAt:   time.Now().UTC(),
+		Project:      *project,
+		Folders:      []models.Folder{},
+		Requests:     []models.Request{},
+		Examples:     []models.ResponseExample{},
+		Environments: []models.Environment{},
+	}
+
+	if folders, err := s.db.GetFolders(id); err != nil {
+		return nil, err
+	} else if folders != nil {
+		bundle.Folders = folders
+	}
+	if requests, err := s.db.GetRequests(id); err != nil {
+		return nil, err
+	} else if requests != nil {
+		bundle.Requests = requests
+	}
+	if examples, err := s.db.GetProjectExamples(id); err != nil {
+		return nil, err
+	} else if examples != nil {
+		bundle.Examples = examples
+	}
+	if environments, err := s.db.GetEnvironments(id); err != nil {
+		return nil, err
+	} else if environments != nil {
+		bundle.Environments = environments
+	}
+	if bundle.Tags, err = s.db.GetTags(id); err != nil {
+		return nil, err
+	}
+
+	if includeHistory {
+		for _, request := range bundle.Requests {
+			history, err := s.db.GetLatestRequestHistory(request.ID, bundleHistoryLimit)
+			if err != nil {
+				return nil, err
+			}
+			bundle.History = append(bundle.History, history...)
+		}
+	}
+
+	return bundle, nil
+}
+
+// ImportProject creates a new project from a bundle written by ExportProject. The project is
+// renamed when its name is already taken, and every item gets a new ID.
+func (s *ProjectService) ImportProject(data []byte) (*models.ImportResult, error) {
+	var bundle ProjectBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("invalid project bundle: %w", err)
+	}
+	if bundle.Format != ProjectBundleFormat {
+		return nil, fmt.Errorf("not a Rikuest project bundle")
+	}
+	if bundle.Version < 1 || bundle.Version > ProjectBundleVersion {
+		return nil, fmt.Errorf("unsupported project bundle version %d, update Rikuest to import it", bundle.Version)
+	}
+
+	// A failure part way leaves no partial project behind
+	var result *models.ImportResult
+	err := s.db.InTransaction(func(tx *database.DB) error {
+		var err error
+		result, err = (&ProjectService{db: tx}).importBundle(&bundle)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *ProjectService) importBundle(bundle *ProjectBundle) (*models.ImportResult, error) {
+	result := &models.ImportResult{Warnings: []string{}}
+
+	name := bundle.Project.Name
+	if name == "" {
+		name = "Imported Project"
+	}
+	name, err := uniqueProjectName(s.db, name)
+	if err != nil {
+		return nil, err
+	}
+	if bundle.Project.Name != "" && name != bundle.Project.Name {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("A project named %q already exists, imported as %q", bundle.Project.Name, name))
+	}
+
+	project := &models.Project{Name: name, Description: bundle.Project.Description}
+	if err := s.db.CreateProject(project); err != nil {
+		return nil, err
+	}
+	result.ProjectID = project.ID
+
+	folderIDs, err := s.importBundleFolders(project.ID, bundle.Folders, result)
+	if err != nil {
+		return nil, err
+	}
+
+	// Tags come first so that requests find them with their colors
+	for _, tag := range bundle.Tags {
+		tag.ID = 0
+		tag.ProjectID = project.ID
+		if err := s.db.CreateTag(&tag); err != nil {
+			return nil, fmt.Errorf("failed to create tag %q: %w", tag.Name, err)
+		}
+	}
+
+	requestIDs := make(map[int]int, len(bundle.Requests))
+	requests := append([]models.Request(nil), bundle.Requests...)
+	sort.SliceStable(requests, func(i, j int) bool { return requests[i].Position < requests[j].Position })
+	for _, request := range requests {
+		oldID := request.ID
+		request.ID = 0
+		request.ProjectID = project.ID
+		request.Response = nil
+		request.FolderID = bundleFolderID(request.FolderID, folderIDs)
+		if err := s.db.CreateRequest(&request); err != nil {
+			return nil, fmt.Errorf("failed to create request %q: %w", request.Name, err)
+		}
+		requestIDs[oldID] = request.ID
+		result.Requests++
+	}
+
+	for _, example := range bundle.Examples {
+		requestID, ok := requestIDs[example.RequestID]
+		if !ok {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Example %q belongs to no request and was skipped", example.Name))
+			continue
+		}
+		example.ID = 0
+		example.RequestID = requestID
+		if err := s.db.CreateRequestExample(&example); err != nil {
+			return nil, fmt.Errorf("failed to create example %q: %w", example.Name, err)
+		}
+		result.Examples++
+	}
+
+	for _, entry := range bundle.History {
+		requestID, ok := requestIDs[entry.RequestID]
+		if !ok {
+			continue
+		}
+		entry.ID = 0
+		entry.RequestID = requestID
+		if err := s.db.SaveRequestHistory(&entry); err != nil {
+			return nil, fmt.Errorf("failed to import history: %w", err)
+		}
+		result.History++
+	}
+
+	for _, environment := range bundle.Environments {
+		environment.ID = 0
+		environment.ProjectID = project.ID
+		if err := s.db.CreateEnvironment(&environment); err != nil {
+			return nil, fmt.Errorf("failed to create environment %q: %w", environment.Name, err)
+		}
+		result.Environments++
+	}
+
+	return result, nil
+}
+
+// importBundleFolders creates folders parents first, keeping their order, and returns the new
+// ID of each bundle folder ID
+func (s *ProjectService) importBundleFolders(projectID int, folders []models.Folder, result *models.ImportResult) (map[int]int, error) {
+	known := make(map[int]bool, len(folders))
+	for _, folder := range folders {
+		known[folder.ID] = true
+	}
+
+	children := make(map[int][]models.Folder)
+	for _, folder := range folders {
+		parentID := 0
+		if folder.ParentID != nil && known[*folder.ParentID] && *folder.ParentID != folder.ID {
+			parentID = *folder.ParentID
+		} else if folder.ParentID != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Folder %q has no parent in the bundle and was imported at the root", folder.Name))
+		}
+		children[parentID] = append(children[parentID], folder)
+	}
+
+	folderIDs := make(map[int]int, len(folders))
+	var create func(oldParentID int, parentID *int) error
+	create = func(oldParentID int, parentID *int) error {
+		level := children[oldParentID]
+		sort.SliceStable(level, func(i, j int) bool { return level[i].Position < level[j].Position })
+		for _, folder := range level {
+			if _, done := folderIDs[folder.ID]; done {
+				continue
+			}
+			created := &models.Folder{ProjectID: projectID, Name: folder.Name, Description: folder.Description, ParentID: parentID}
+			if err := s.db.CreateFolder(created); err != nil {
+				return fmt.Errorf("failed to create folder %q: %w", folder.Name, err)
+			}
+			folderIDs[folder.ID] = created.ID
+			result.Folders++
+			if err := create(folder.ID, &created.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := create(0, nil); err != nil {
+		return nil, err
+	}
+
+	// Folders in a parent cycle are never reached from the root
+	for _, folder := range folders {
+		if _, done := folderIDs[folder.ID]; !done {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Folder %q is nested in itself and was skipped", folder.Name))
+		}
+	}
+	return folderIDs, nil
+}
+
+func bundleFolderID(folderID *int, folderIDs map[int]int) *int {
+	if folderID == nil {
+		return nil
+	}
+	if id, ok := folderIDs[*folderID]; ok {
+		return &id
+	}
+	return nil
+}
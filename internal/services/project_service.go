@@ -1,6 +1,8 @@
 package services
 
 import (
+	"fmt"
+
 	"rikuest/internal/database"
 	"rikuest/internal/models"
 )
@@ -21,7 +23,17 @@ func (s *ProjectService) GetProject(id int) (*models.Project, error) {
 	return s.db.GetProject(id)
 }
 
+// CreateProject creates a project, unless a project with the same name is in the trash
 func (s *ProjectService) CreateProject(project *models.Project) error {
+	trashed, err := s.db.GetTrashedProjects()
+	if err != nil {
+		return err
+	}
+	for _, item := range trashed {
+		if item.Name == project.Name {
+			return fmt.Errorf("a project named %q is in the trash, restore or purge it first", project.Name)
+		}
+	}
 	return s.db.CreateProject(project)
 }
 
@@ -31,4 +43,33 @@ func (s *ProjectService) UpdateProject(project *models.Project) error {
 
 func (s *ProjectService) DeleteProject(id int) error {
 	return s.db.DeleteProject(id)
-}
\ No newline at end of file
+}
+
+// uniqueProjectName returns name, or name with a " (n)" suffix when another project, possibly
+// in the trash, already uses it, since project names are unique
+func uniqueProjectName(db *database.DB, name string) (string, error) {
+	projects, err := db.GetProjects()
+	if err != nil {
+		return "", err
+	}
+
+	taken := make(map[string]bool, len(projects))
+	for _, project := range projects {
+		taken[project.Name] = true
+	}
+
+	// Trashed projects keep their name until they are purged
+	trashed, err := db.GetTrashedProjects()
+	if err != nil {
+		return "", err
+	}
+	for _, item := range trashed {
+		taken[item.Name] = true
+	}
+
+	candidate := name
+	for i := 2; taken[candidate]; i++ {
+		candidate = fmt.Sprintf("%s (%d)", name, i)
+	}
+	return candidate, nil
+}
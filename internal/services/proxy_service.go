@@ -0,0 +1,871 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"rikuest/internal/database"
+	"rikuest/internal/models"
+)
+
+const defaultProxyAddress = "127.0.0.1:8888"
+
+// hopHeaders are connection-level headers that must not be forwarded or recorded
+var hopHeaders = []string{
+	"Connection",
+	"Proxy-Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// ProxyService runs a local forward proxy that records every exchange into a project
+type ProxyService struct {
+	db        *database.DB
+	format    *FormatService
+	transport *http.Transport
+
+	mutex    sync.Mutex
+	server   *http.Server
+	config   models.ProxyConfig
+	captured int
+	tree     *captureTree
+
+	certMutex sync.Mutex
+	ca        *tls.Certificate
+	leafCerts map[string]*tls.Certificate
+
+	// AllowRemote lets the proxy listen on addresses other machines can reach. It is off by
+	// default because whoever reaches the proxy can send traffic through this machine.
+	AllowRemote bool
+}
+
+func NewProxyService(db *database.DB, format *FormatService) *ProxyService {
+	return &ProxyService{
+		db:     db,
+		format: format,
+		transport: &http.Transport{
+			Proxy:              nil,
+			DisableCompression: true,
+			ForceAttemptHTTP2:  true,
+		},
+		leafCerts: make(map[string]*tls.Certificate),
+	}
+}
+
+// Start launches the recording proxy in the background
+func (s *ProxyService) Start(config models.ProxyConfig) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.server != nil {
+		return fmt.Errorf("proxy is already running on %s", s.config.Address)
+	}
+
+	if _, err := s.db.GetProject(config.ProjectID); err != nil {
+		return fmt.Errorf("project not found: %w", err)
+	}
+	if config.FolderID != nil {
+		folder, err := s.db.GetFolder(*config.FolderID)
+		if err != nil || folder.ProjectID != config.ProjectID {
+			return fmt.Errorf("folder not found in project")
+		}
+	}
+
+	if config.Address == "" {
+		config.Address = defaultProxyAddress
+	}
+	if !s.AllowRemote && !isLoopbackAddress(config.Address) {
+		return fmt.Errorf("the proxy only listens on loopback addresses such as %s, not on %s", defaultProxyAddress, config.Address)
+	}
+
+	if config.InterceptHTTPS {
+		if _, err := s.loadCA(); err != nil {
+			return fmt.Errorf("failed to load proxy CA: %w", err)
+		}
+	}
+
+	tree, err := newCaptureTree(s.db, config.ProjectID, config.FolderID)
+	if err != nil {
+		return fmt.Errorf("failed to load project tree: %w", err)
+	}
+
+	listener, err := net.Listen("tcp", config.Address)
+	if err != nil {
+		return fmt.Errorf("failed to start proxy: %w", err)
+	}
+
+	server := &http.Server{Handler: s}
+	s.server = server
+	s.config = config
+	s.tree = tree
+	s.captured = 0
+
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Printf("Recording proxy stopped: %v", err)
+		}
+	}()
+
+	log.Printf("Recording proxy for project %d listening on %s", config.ProjectID, config.Address)
+	return nil
+}
+
+// Stop shuts down the proxy, if running
+func (s *ProxyService) Stop() error {
+	s.mutex.Lock()
+	server := s.server
+	s.server = nil
+	s.mutex.Unlock()
+
+	if server == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Hijacked CONNECT tunnels are not tracked by Shutdown and end with their clients
+	return server.Shutdown(ctx)
+}
+
+func (s *ProxyService) Status() models.ProxyStatus {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return models.ProxyStatus{
+		Running:  s.server != nil,
+		Config:   s.config,
+		Captured: s.captured,
+	}
+}
+
+// CACertificatePEM returns the local CA certificate that clients must trust for HTTPS interception
+func (s *ProxyService) CACertificatePEM() (string, error) {
+	ca, err := s.loadCA()
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Certificate[0]})), nil
+}
+
+// ServeHTTP handles both plain HTTP proxy requests and CONNECT tunnels
+func (s *ProxyService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodConnect {
+		s.handleConnect(w, r)
+		return
+	}
+
+	if !r.URL.IsAbs() {
+		http.Error(w, "Rikuest recording proxy: configure this address as an HTTP proxy", http.StatusBadRequest)
+		return
+	}
+
+	resp, respBody, err := s.forward(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	for key, values := range resp.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	w.Write(respBody)
+}
+
+func (s *ProxyService) handleConnect(w http.ResponseWriter, r *http.Request) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "Tunneling not supported", http.StatusInternalServerError)
+		return
+	}
+
+	s.mutex.Lock()
+	intercept := s.config.InterceptHTTPS
+	s.mutex.Unlock()
+
+	var upstream net.Conn
+	if !intercept {
+		var err error
+		upstream, err = net.DialTimeout("tcp", r.Host, 30*time.Second)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+	}
+
+	clientConn, _, err := hijacker.Hijack()
+	if err != nil {
+		if upstream != nil {
+			upstream.Close()
+		}
+		return
+	}
+	defer clientConn.Close()
+
+	if _, err := clientConn.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n")); err != nil {
+		return
+	}
+
+	if !intercept {
+		// Without interception the encrypted stream is relayed as-is and nothing is recorded
+		defer upstream.Close()
+		go io.Copy(upstream, clientConn)
+		io.Copy(clientConn, upstream)
+		return
+	}
+
+	hostname, port, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		hostname, port = r.Host, "443"
+	}
+	urlHost := r.Host
+	if port == "443" {
+		urlHost = hostname
+	}
+
+	tlsConn := tls.Server(clientConn, &tls.Config{
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			name := hello.ServerName
+			if name == "" {
+				name = hostname
+			}
+			return s.leafCertificate(name)
+		},
+		// Only HTTP/1.1 is spoken on the client side of the tunnel
+		NextProtos: []string{"http/1.1"},
+	})
+	if err := tlsConn.Handshake(); err != nil {
+		return
+	}
+	defer tlsConn.Close()
+
+	reader := bufio.NewReader(tlsConn)
+	for {
+		req, err := http.ReadRequest(reader)
+		if err != nil {
+			return
+		}
+		req.URL.Scheme = "https"
+		req.URL.Host = urlHost
+
+		resp, respBody, err := s.forward(req)
+		if err != nil {
+			resp = &http.Response{
+				StatusCode: http.StatusBadGateway,
+				ProtoMajor: 1,
+				ProtoMinor: 1,
+				Header:     http.Header{"Content-Type": {"text/plain"}},
+			}
+			respBody = []byte(err.Error())
+		}
+
+		resp.Body = io.NopCloser(bytes.NewReader(respBody))
+		resp.ContentLength = int64(len(respBody))
+		resp.TransferEncoding = nil
+		resp.Header.Del("Content-Length")
+		resp.ProtoMajor, resp.ProtoMinor = 1, 1
+		if err := resp.Write(tlsConn); err != nil {
+			return
+		}
+
+		if req.Close || resp.Close {
+			return
+		}
+	}
+}
+
+// forward sends the request upstream, records the exchange and returns the response with its
+// body fully read. Hop-by-hop headers are removed from the returned response.
+func (s *ProxyService) forward(r *http.Request) (*http.Response, []byte, error) {
+	var reqBody []byte
+	if r.Body != nil {
+		var err error
+		reqBody, err = io.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+
+	outgoing, err := http.NewRequest(r.Method, r.URL.String(), bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, nil, err
+	}
+	outgoing.Header = r.Header.Clone()
+	removeHopHeaders(outgoing.Header)
+	if len(reqBody) == 0 {
+		outgoing.Body = nil
+		outgoing.ContentLength = 0
+	}
+
+	start := time.Now()
+	resp, err := s.transport.RoundTrip(outgoing)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	duration := time.Since(start)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	removeHopHeaders(resp.Header)
+
+	if err := s.record(outgoing, reqBody, resp, respBody, duration); err != nil {
+		log.Printf("Warning: Failed to record proxied request: %v", err)
+	}
+
+	return resp, respBody, nil
+}
+
+// record stores the exchange as a request grouped by host and path, with the response in its history
+func (s *ProxyService) record(r *http.Request, reqBody []byte, resp *http.Response, respBody []byte, duration time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	request := capturedRequest(r, reqBody)
+
+	// The body is stored decoded, so the headers describing its encoding no longer apply
+	encoded := resp.Header.Get("Content-Encoding") != ""
+	headers := make(map[string]string)
+	for key, values := range resp.Header {
+		if encoded && (strings.EqualFold(key, "Content-Encoding") || strings.EqualFold(key, "Content-Length")) {
+			continue
+		}
+		headers[key] = strings.Join(values, ", ")
+	}
+
+	history := &models.RequestHistory{
+		Request: request,
+		Response: models.RequestResponse{
+			Status:     resp.StatusCode,
+			StatusText: strconv.Itoa(resp.StatusCode) + " " + http.StatusText(resp.StatusCode),
+			Headers:    headers,
+			Body:       decodedBody(resp.Header, respBody),
+			Duration:   duration.Milliseconds(),
+			Size:       int64(len(respBody)),
+			RawRequest: s.format.BuildRawRequest(request),
+		},
+	}
+	if err := s.tree.store(request, history); err != nil {
+		return err
+	}
+
+	s.captured++
+	return nil
+}
+
+// captureTree files captured requests under <root>/<host>/<first path segment>, reusing the
+// folders and requests that already exist so repeated calls only add history entries
+type captureTree struct {
+	db        *database.DB
+	projectID int
+	rootID    *int
+	folders   map[string]int
+	requests  map[string]int
+
+	createdFolders  int
+	createdRequests int
+	createdHistory  int
+}
+
+func newCaptureTree(db *database.DB, projectID int, rootID *int) (*captureTree, error) {
+	folders, err := db.GetFolders(projectID)
+	if err != nil {
+		return nil, err
+	}
+	requests, err := db.GetRequests(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := &captureTree{
+		db:        db,
+		projectID: projectID,
+		rootID:    rootID,
+		folders:   make(map[string]int),
+		requests:  make(map[string]int),
+	}
+
+	for _, folder := range folders {
+		key := folderKey(folder.ParentID) + "/" + folder.Name
+		if _, exists := tree.folders[key]; !exists {
+			tree.folders[key] = folder.ID
+		}
+	}
+
+	for _, request := range requests {
+		key := fmt.Sprintf("%s|%s|%s", folderKey(request.FolderID), request.Method, request.URL)
+		if _, exists := tree.requests[key]; !exists {
+			tree.requests[key] = request.ID
+		}
+	}
+
+	return tree, nil
+}
+
+// store files the request, creating it unless an identical endpoint was captured before, and
+// saves history against it
+func (t *captureTree) store(request *models.Request, history *models.RequestHistory) error {
+	parsedURL, err := url.Parse(request.URL)
+	if err != nil {
+		return fmt.Errorf("invalid captured URL %q: %w", request.URL, err)
+	}
+
+	folderID, err := t.ensureFolder(t.rootID, parsedURL.Host)
+	if err != nil {
+		return err
+	}
+	segments := splitMockPath(parsedURL.Path)
+	if len(segments) > 1 {
+		folderID, err = t.ensureFolder(folderID, "/"+segments[0])
+		if err != nil {
+			return err
+		}
+	}
+
+	request.ProjectID = t.projectID
+	request.FolderID = folderID
+
+	key := fmt.Sprintf("%s|%s|%s", folderKey(folderID), request.Method, request.URL)
+	requestID, ok := t.requests[key]
+	if !ok {
+		if err := t.db.CreateRequest(request); err != nil {
+			return err
+		}
+		requestID = request.ID
+		t.requests[key] = requestID
+		t.createdRequests++
+	}
+
+	history.RequestID = requestID
+	if err := t.db.SaveRequestHistory(history); err != nil {
+		return err
+	}
+	t.createdHistory++
+
+	return nil
+}
+
+// ensureFolder returns the ID of the folder with the given name under parentID, creating it if needed
+func (t *captureTree) ensureFolder(parentID *int, name string) (*int, error) {
+	key := folderKey(parentID) + "/" + name
+	if id, ok := t.folders[key]; ok {
+		return &id, nil
+	}
+
+	folder := &models.Folder{
+		ProjectID: t.projectID,
+		Name:      name,
+		ParentID:  parentID,
+	}
+	if err := t.db.CreateFolder(folder); err != nil {
+		return nil, err
+	}
+
+	t.folders[key] = folder.ID
+	t.createdFolders++
+	return &folder.ID, nil
+}
+
+func folderKey(folderID *int) string {
+	if folderID == nil {
+		return "root"
+	}
+	return strconv.Itoa(*folderID)
+}
+
+// capturedRequest converts an outgoing HTTP request into a Rikuest request
+func capturedRequest(r *http.Request, body []byte) *models.Request {
+	endpoint := *r.URL
+	endpoint.RawQuery = ""
+	endpoint.Fragment = ""
+
+	request := &models.Request{
+		Name:        r.Method + " " + endpoint.EscapedPath(),
+		Method:      r.Method,
+		URL:         endpoint.String(),
+		Headers:     make(map[string]string),
+		QueryParams: []models.QueryParam{},
+		AuthType:    "none",
+		BodyType:    "none",
+		FormData:    []models.FormData{},
+	}
+
+	query := r.URL.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range query[key] {
+			request.QueryParams = append(request.QueryParams, models.QueryParam{Key: key, Value: value, Enabled: true})
+		}
+	}
+
+	for key, values := range r.Header {
+		value := strings.Join(values, ", ")
+		if strings.EqualFold(key, "Authorization") {
+			if applyAuthorizationHeader(request, value) {
+				continue
+			}
+		}
+		if strings.EqualFold(key, "Content-Length") {
+			continue
+		}
+		request.Headers[key] = value
+	}
+
+	if len(body) > 0 {
+		contentType := strings.ToLower(r.Header.Get("Content-Type"))
+		switch {
+		case strings.Contains(contentType, "application/x-www-form-urlencoded"):
+			if values, err := url.ParseQuery(string(body)); err == nil {
+				request.BodyType = "form"
+				keys := make([]string, 0, len(values))
+				for key := range values {
+					keys = append(keys, key)
+				}
+				sort.Strings(keys)
+				for _, key := range keys {
+					for _, value := range values[key] {
+						request.FormData = append(request.FormData, models.FormData{Key: key, Value: value})
+					}
+				}
+				break
+			}
+			request.BodyType = "text"
+			request.Body = string(body)
+		case strings.Contains(contentType, "json"):
+			request.BodyType = "json"
+			request.Body = string(body)
+		default:
+			request.BodyType = "text"
+			request.Body = string(body)
+		}
+	}
+
+	return request
+}
+
+// applyAuthorizationHeader maps Bearer and Basic credentials to the request auth fields.
+// It returns false when the header uses another scheme and should be kept as a plain header.
+func applyAuthorizationHeader(request *models.Request, value string) bool {
+	scheme, credentials, found := strings.Cut(strings.TrimSpace(value), " ")
+	if !found {
+		return false
+	}
+
+	switch strings.ToLower(scheme) {
+	case "bearer":
+		request.AuthType = "bearer"
+		request.BearerToken = strings.TrimSpace(credentials)
+		return true
+	case "basic":
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(credentials))
+		if err != nil {
+			return false
+		}
+		username, password, _ := strings.Cut(string(decoded), ":")
+		request.AuthType = "basic"
+		request.BasicAuth = models.BasicAuth{Username: username, Password: password}
+		return true
+	}
+
+	return false
+}
+
+// decodedBody returns the response body as text, decompressing gzip and deflate bodies. Bodies
+// in other encodings, such as br, are replaced with a note rather than stored as garbled text.
+func decodedBody(header http.Header, body []byte) string {
+	encoding := strings.ToLower(strings.TrimSpace(header.Get("Content-Encoding")))
+	var reader io.ReadCloser
+	var err error
+	switch encoding {
+	case "", "identity":
+		return string(body)
+	case "gzip", "x-gzip":
+		reader, err = gzip.NewReader(bytes.NewReader(body))
+	case "deflate":
+		// Servers send either zlib streams, as the specification says, or raw deflate data
+		if reader, err = zlib.NewReader(bytes.NewReader(body)); err != nil {
+			reader, err = flate.NewReader(bytes.NewReader(body)), nil
+		}
+	default:
+		err = fmt.Errorf("unsupported encoding")
+	}
+	if err == nil {
+		defer reader.Close()
+		if decoded, err := io.ReadAll(reader); err == nil {
+			return string(decoded)
+		}
+	}
+	return fmt.Sprintf("<%s-encoded body, %d bytes>", encoding, len(body))
+}
+
+func removeHopHeaders(header http.Header) {
+	for _, key := range hopHeaders {
+		header.Del(key)
+	}
+}
+
+// isLoopbackAddress reports whether a listen address only accepts connections from this machine
+func isLoopbackAddress(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// loadCA returns the proxy CA, generating it on first use. The CA is kept in files next to the
+// database, with the key readable by the owner only, so that neither the settings table nor
+// backups hold the key. An in-memory database keeps it in memory.
+func (s *ProxyService) loadCA() (*tls.Certificate, error) {
+	s.certMutex.Lock()
+	defer s.certMutex.Unlock()
+
+	if s.ca != nil {
+		return s.ca, nil
+	}
+
+	certPath, keyPath := s.caFiles()
+	certPEM, keyPEM, err := readProxyCA(certPath, keyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Earlier versions kept the CA in the settings table, and restored backups may bring it back
+	legacyCert, err := s.db.GetSetting("proxy_ca_cert")
+	if err != nil {
+		return nil, err
+	}
+	legacyKey, err := s.db.GetSetting("proxy_ca_key")
+	if err != nil {
+		return nil, err
+	}
+
+	if certPEM == "" || keyPEM == "" {
+		if legacyCert != "" && legacyKey != "" {
+			certPEM, keyPEM = legacyCert, legacyKey
+		} else if certPEM, keyPEM, err = generateProxyCA(); err != nil {
+			return nil, err
+		}
+		if err := writeProxyCA(certPath, keyPath, certPEM, keyPEM); err != nil {
+			return nil, fmt.Errorf("failed to save proxy CA: %w", err)
+		}
+	}
+	if legacyCert != "" || legacyKey != "" {
+		if err := s.db.DeleteSetting("proxy_ca_cert"); err != nil {
+			return nil, err
+		}
+		if err := s.db.DeleteSetting("proxy_ca_key"); err != nil {
+			return nil, err
+		}
+	}
+
+	ca, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+	if err != nil {
+		return nil, err
+	}
+	ca.Leaf, err = x509.ParseCertificate(ca.Certificate[0])
+	if err != nil {
+		return nil, err
+	}
+
+	s.ca = &ca
+	return s.ca, nil
+}
+
+// caFiles returns the paths of the CA certificate and key, or empty paths for an in-memory database
+func (s *ProxyService) caFiles() (string, string) {
+	if s.db.Path() == "" {
+		return "", ""
+	}
+	dir := filepath.Dir(s.db.Path())
+	return filepath.Join(dir, "proxy-ca.pem"), filepath.Join(dir, "proxy-ca-key.pem")
+}
+
+// readProxyCA reads the CA files, returning empty strings when they do not exist yet
+func readProxyCA(certPath, keyPath string) (string, string, error) {
+	if certPath == "" {
+		return "", "", nil
+	}
+	certPEM, err := os.ReadFile(certPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", "", nil
+	} else if err != nil {
+		return "", "", err
+	}
+	keyPEM, err := os.ReadFile(keyPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", "", nil
+	} else if err != nil {
+		return "", "", err
+	}
+	return string(certPEM), string(keyPEM), nil
+}
+
+func writeProxyCA(certPath, keyPath, certPEM, keyPEM string) error {
+	if certPath == "" {
+		return nil
+	}
+	// The key is written first, so that a certificate file never exists without its key
+	if err := writePrivateFile(keyPath, keyPEM); err != nil {
+		return err
+	}
+	return os.WriteFile(certPath, []byte(certPEM), 0644)
+}
+
+// writePrivateFile writes a file only its owner can read, including when it already exists
+func writePrivateFile(path, content string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if err := file.Chmod(0600); err != nil {
+		file.Close()
+		return err
+	}
+	if _, err := file.WriteString(content); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// leafCertificate returns a certificate for host signed by the proxy CA
+func (s *ProxyService) leafCertificate(host string) (*tls.Certificate, error) {
+	ca, err := s.loadCA()
+	if err != nil {
+		return nil, err
+	}
+
+	s.certMutex.Lock()
+	defer s.certMutex.Unlock()
+
+	if cert, ok := s.leafCerts[host]; ok && time.Now().Before(cert.Leaf.NotAfter) {
+		return cert, nil
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: host},
+		NotBefore:    time.Now().Add(-time.Hour),
+		// Kept under 398 days, the maximum lifetime browsers accept for leaf certificates
+		NotAfter:    time.Now().Add(365 * 24 * time.Hour),
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		template.IPAddresses = []net.IP{ip}
+	} else {
+		template.DNSNames = []string{host}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.Leaf, &key.PublicKey, ca.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	cert := &tls.Certificate{
+		Certificate: [][]byte{der, ca.Certificate[0]},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}
+	s.leafCerts[host] = cert
+	return cert, nil
+}
+
+func generateProxyCA() (string, string, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return "", "", err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "Rikuest Local Proxy CA", Organization: []string{"Rikuest"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().AddDate(10, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return "", "", err
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return "", "", err
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return string(certPEM), string(keyPEM), nil
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+
+	"rikuest/internal/models"
+)
+
+// RequestFieldChange is a request field that differs between two versions of a request. Field
+// is "method", "url", "body", "header Accept", "query limit" and so on, and Kind is "added",
+// "removed" or "changed".
+type RequestFieldChange struct {
+	Field  string `json:"field"`
+	Kind   string `json:"kind"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// requestField is a comparable part of a request. apply copies the field into another request,
+// so a request can be rebuilt from fields picked from different versions.
+type requestField struct {
+	name  string
+	value string
+	apply func(*models.Request)
+}
+
+// requestFields lists the fields of a request that are compared and merged. Repeated query
+// parameters and form fields are numbered so that every field has a unique name.
+func requestFields(request *models.Request) []requestField {
+	r := request
+	fields := []requestField{
+		{"name", r.Name, func(t *models.Request) { t.Name = r.Name }},
+		{"description", r.Description, func(t *models.Request) { t.Description = r.Description }},
+		{"method", r.Method, func(t *models.Request) { t.Method = r.Method }},
+		{"url", r.URL, func(t *models.Request) { t.URL = r.URL }},
+	}
+
+	seen := make(map[string]int)
+	unique := func(name string) string {
+		if seen[name]++; seen[name] > 1 {
+			return fmt.Sprintf("%s [%d]", name, seen[name])
+		}
+		return name
+	}
+
+	for _, param := range r.QueryParams {
+		param := param
+		value := param.Value
+		if !param.Enabled {
+			value += " (disabled)"
+		}
+		fields = append(fields, requestField{unique("query " + param.Key), value, func(t *models.Request) {
+			t.QueryParams = append(t.QueryParams, param)
+		}})
+	}
+	for _, key := range sortedKeys(r.Headers) {
+		key, value := key, r.Headers[key]
+		fields = append(fields, requestField{"header " + key, value, func(t *models.Request) {
+			t.Headers[key] = value
+		}})
+	}
+
+	fields = append(fields,
+		requestField{"body_type", r.BodyType, func(t *models.Request) { t.BodyType = r.BodyType }},
+		requestField{"body", r.Body, func(t *models.Request) { t.Body = r.Body }},
+	)
+	for _, item := range r.FormData {
+		item := item
+		value := item.Value
+		if item.Type == "file" {
+			value = "@" + item.Value
+		}
+		fields = append(fields, requestField{unique("form " + item.Key), value, func(t *models.Request) {
+			t.FormData = append(t.FormData, item)
+		}})
+	}
+
+	return append(fields,
+		requestField{"auth_type", r.AuthType, func(t *models.Request) { t.AuthType = r.AuthType }},
+		requestField{"bearer_token", r.BearerToken, func(t *models.Request) { t.BearerToken = r.BearerToken }},
+		requestField{"basic_auth.username", r.BasicAuth.Username, func(t *models.Request) { t.BasicAuth.Username = r.BasicAuth.Username }},
+		requestField{"basic_auth.password", r.BasicAuth.Password, func(t *models.Request) { t.BasicAuth.Password = r.BasicAuth.Password }},
+		requestField{"expected_status", strconv.Itoa(r.ExpectedStatus), func(t *models.Request) { t.ExpectedStatus = r.ExpectedStatus }},
+	)
+}
+
+// applyRequestFields rebuilds the content of a request from fields, such as those of another
+// version of it. Where the request sits is left alone.
+func applyRequestFields(request *models.Request, fields []requestField) {
+	request.Headers = make(map[string]string)
+	request.QueryParams = []models.QueryParam{}
+	request.FormData = []models.FormData{}
+	request.BasicAuth = models.BasicAuth{}
+	for _, field := range fields {
+		field.apply(request)
+	}
+}
+
+// diffRequestFields lists the fields that differ, in the order of after followed by the
+// fields only present in before
+func diffRequestFields(before, after []requestField) []RequestFieldChange {
+	beforeValues := requestFieldIndex(before)
+	afterValues := requestFieldIndex(after)
+
+	var changes []RequestFieldChange
+	for _, field := range after {
+		previous, ok := beforeValues[field.name]
+		switch {
+		case !ok:
+			changes = append(changes, RequestFieldChange{Field: field.name, Kind: "added", After: field.value})
+		case previous.value != field.value:
+			changes = append(changes, RequestFieldChange{Field: field.name, Kind: "changed", Before: previous.value, After: field.value})
+		}
+	}
+	for _, field := range before {
+		if _, ok := afterValues[field.name]; !ok {
+			changes = append(changes, RequestFieldChange{Field: field.name, Kind: "removed", Before: field.value})
+		}
+	}
+	return changes
+}
+
+func requestFieldIndex(fields []requestField) map[string]requestField {
+	index := make(map[string]requestField, len(fields))
+	for _, field := range fields {
+		index[field.name] = field
+	}
+	return index
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"fmt"
+
+	"rikuest/internal/models"
+)
+
+// GetRevisions lists the earlier states of a request, newest first
+func (s *RequestService) GetRevisions(requestID int) ([]models.RequestRevision, error) {
+	if _, err := s.db.GetRequest(requestID); err != nil {
+		return nil, fmt.Errorf("request not found: %w", err)
+	}
+	return s.db.GetRequestRevisions(requestID)
+}
+
+// DiffRevisions compares two states of a request field by field. From and to are revision
+// IDs, and 0 stands for the current state of the request.
+func (s *RequestService) DiffRevisions(requestID, from, to int) ([]RequestFieldChange, error) {
+	before, err := s.revisionState(requestID, from)
+	if err != nil {
+		return nil, err
+	}
+	after, err := s.revisionState(requestID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := diffRequestFields(requestFields(before), requestFields(after))
+	if changes == nil {
+		changes = []RequestFieldChange{}
+	}
+	return changes, nil
+}
+
+// RollbackRequest brings a request back to the state of one of its revisions. The request
+// stays where it is, and the state it had before the rollback is kept as a new revision, so a
+// rollback can itself be undone.
+func (s *RequestService) RollbackRequest(requestID, revisionID int, author string) (*models.Request, error) {
+	request, err := s.db.GetRequest(requestID)
+	if err != nil {
+		return nil, fmt.Errorf("request not found: %w", err)
+	}
+	revision, err := s.revision(requestID, revisionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every compared field comes back, so that fields are never left out of a rollback
+	applyRequestFields(request, requestFields(&revision.Request))
+
+	if err := s.db.UpdateRequestBy(request, author); err != nil {
+		return nil, err
+	}
+	return s.db.GetRequest(requestID)
+}
+
+// revisionState returns the request as it was in a revision, or as it is now for revision 0
+func (s *RequestService) revisionState(requestID, revisionID int) (*models.Request, error) {
+	if revisionID == 0 {
+		request, err := s.db.GetRequest(requestID)
+		if err != nil {
+			return nil, fmt.Errorf("request not found: %w", err)
+		}
+		return request, nil
+	}
+	revision, err := s.revision(requestID, revisionID)
+	if err != nil {
+		return nil, err
+	}
+	return &revision.Request, nil
+}
+
+func (s *RequestService) revision(requestID, revisionID int) (*models.RequestRevision, error) {
+	revision, err := s.db.GetRequestRevision(revisionID)
+	if err != nil {
+		return nil, err
+	}
+	if revision == nil || revision.RequestID != requestID {
+		return nil, fmt.Errorf("revision %d not found for request %d", revisionID, requestID)
+	}
+	return revision, nil
+}
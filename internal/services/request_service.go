@@ -1,6 +1,7 @@
 package services
 
 import (
+	"bytes"
 	"encoding/base64"
 	"fmt"
 	"io"
@@ -16,12 +17,14 @@ import (
 type RequestService struct {
 	db     *database.DB
 	config *ConfigService
+	files  FileAccess
 }
 
-func NewRequestService(db *database.DB) *RequestService {
+func NewRequestService(db *database.DB, files FileAccess) *RequestService {
 	return &RequestService{
 		db:     db,
 		config: NewConfigService(db),
+		files:  files,
 	}
 }
 
@@ -33,12 +36,73 @@ func (s *RequestService) GetRequest(id int) (*models.Request, error) {
 	return s.db.GetRequest(id)
 }
 
+// GetRequestWithResponse returns a request with its latest recorded response, if any
+func (s *RequestService) GetRequestWithResponse(id int) (*models.Request, error) {
+	request, err := s.db.GetRequest(id)
+	if err != nil {
+		return nil, err
+	}
+	history, err := s.db.GetLatestRequestHistory(id, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(history) > 0 {
+		request.Response = &history[0].Response
+	}
+	return request, nil
+}
+
 func (s *RequestService) CreateRequest(request *models.Request) error {
+	if err := checkExpectedStatus(request.ExpectedStatus); err != nil {
+		return err
+	}
 	return s.db.CreateRequest(request)
 }
 
-func (s *RequestService) UpdateRequest(request *models.Request) error {
-	return s.db.UpdateRequest(request)
+// ImportCurl parses a curl command and saves it as a new request. An empty name keeps the
+// name derived from the method and URL.
+func (s *RequestService) ImportCurl(projectID int, folderID *int, name string, command string) (*models.Request, error) {
+	if _, err := s.db.GetProject(projectID); err != nil {
+		return nil, fmt.Errorf("project not found: %w", err)
+	}
+	if folderID != nil {
+		folder, err := s.db.GetFolder(*folderID)
+		if err != nil || folder.ProjectID != projectID {
+			return nil, fmt.Errorf("folder not found in project")
+		}
+	}
+
+	request, err := parseCurlCommand(command)
+	if err != nil {
+		return nil, err
+	}
+
+	request.ProjectID = projectID
+	request.FolderID = folderID
+	if name != "" {
+		request.Name = name
+	}
+	if err := s.db.CreateRequest(request); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// UpdateRequest saves a request. Author is who made the edit and is recorded in the revision
+// of the previous state.
+func (s *RequestService) UpdateRequest(request *models.Request, author string) error {
+	if err := checkExpectedStatus(request.ExpectedStatus); err != nil {
+		return err
+	}
+	return s.db.UpdateRequestBy(request, author)
+}
+
+// checkExpectedStatus accepts no expected status, 0, or a valid HTTP status
+func checkExpectedStatus(status int) error {
+	if status != 0 && !isHTTPStatus(status) {
+		return fmt.Errorf("invalid expected status %d, expected a status between 100 and 599", status)
+	}
+	return nil
 }
 
 func (s *RequestService) DeleteRequest(id int) error {
@@ -49,10 +113,6 @@ func (s *RequestService) SaveRequestHistory(history *models.RequestHistory) erro
 	return s.db.SaveRequestHistory(history)
 }
 
-func (s *RequestService) GetRequestHistory(requestID int) ([]models.RequestHistory, error) {
-	return s.db.GetRequestHistory(requestID)
-}
-
 func (s *RequestService) DeleteRequestHistoryItem(requestID int, historyID int) error {
 	return s.db.DeleteRequestHistoryItem(requestID, historyID)
 }
@@ -61,6 +121,68 @@ func (s *RequestService) MoveRequest(requestID int, folderID *int, position int)
 	return s.db.MoveRequest(requestID, folderID, position)
 }
 
+func (s *RequestService) GetExamples(requestID int) ([]models.ResponseExample, error) {
+	return s.db.GetRequestExamples(requestID)
+}
+
+func (s *RequestService) GetExample(id int) (*models.ResponseExample, error) {
+	return s.db.GetRequestExample(id)
+}
+
+func (s *RequestService) CreateExample(example *models.ResponseExample) error {
+	if example.Name == "" {
+		return fmt.Errorf("example name is required")
+	}
+	if example.Status == 0 {
+		example.Status = http.StatusOK
+	}
+	if !isHTTPStatus(example.Status) {
+		return fmt.Errorf("invalid example status %d, expected a status between 100 and 599", example.Status)
+	}
+	return s.db.CreateRequestExample(example)
+}
+
+func (s *RequestService) UpdateExample(example *models.ResponseExample) error {
+	if example.Name == "" {
+		return fmt.Errorf("example name is required")
+	}
+	if !isHTTPStatus(example.Status) {
+		return fmt.Errorf("invalid example status %d, expected a status between 100 and 599", example.Status)
+	}
+	return s.db.UpdateRequestExample(example)
+}
+
+func (s *RequestService) DeleteExample(id int) error {
+	return s.db.DeleteRequestExample(id)
+}
+
+// SaveHistoryAsExample stores a recorded response from the request history as a named example
+func (s *RequestService) SaveHistoryAsExample(requestID int, historyID int, name string) (*models.ResponseExample, error) {
+	history, err := s.db.GetRequestHistoryItem(requestID, historyID)
+	if err != nil {
+		return nil, fmt.Errorf("history item not found: %w", err)
+	}
+	if history.Response.Status == 0 {
+		return nil, fmt.Errorf("history item has no HTTP response to save")
+	}
+
+	if name == "" {
+		name = history.Response.StatusText
+	}
+
+	example := &models.ResponseExample{
+		RequestID: requestID,
+		Name:      name,
+		Status:    history.Response.Status,
+		Headers:   history.Response.Headers,
+		Body:      history.Response.Body,
+	}
+	if err := s.CreateExample(example); err != nil {
+		return nil, err
+	}
+	return example, nil
+}
+
 func (s *RequestService) ExecuteRequest(requestID int) (*models.RequestResponse, error) {
 	// Get the request details
 	request, err := s.GetRequest(requestID)
@@ -68,15 +190,32 @@ func (s *RequestService) ExecuteRequest(requestID int) (*models.RequestResponse,
 		return nil, err
 	}
 
+	// Substitute {{variables}} from the project's active environment
+	environment, err := s.db.GetActiveEnvironment(request.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load active environment: %w", err)
+	}
+	return s.executeInEnvironment(request, environment)
+}
+
+// executeInEnvironment sends a request with the variables of an environment, which may be nil,
+// and records it in history
+func (s *RequestService) executeInEnvironment(request *models.Request, environment *models.Environment) (*models.RequestResponse, error) {
+	requestID := request.ID
+	if environment != nil {
+		request = applyEnvironment(request, environment.Variables)
+	}
+
 	// Execute the request using the same logic as the HTTP handler
 	response, err := s.executeHTTPRequest(request)
 	if err != nil {
 		return nil, err
 	}
 
-	// Save to history
+	// Save to history, with the request as it was sent
 	history := &models.RequestHistory{
 		RequestID: requestID,
+		Request:   request,
 		Response:  *response,
 	}
 	
@@ -124,8 +263,17 @@ func (s *RequestService) executeHTTPRequest(request *models.Request) (*models.Re
 	// Prepare the request body based on body type
 	var body io.Reader
 	var bodyString string
+	var multipartContentType string
 	
-	if request.BodyType == "form" && len(request.FormData) > 0 {
+	if request.BodyType == "multipart" && len(request.FormData) > 0 {
+		// Handle multipart form data, reading file fields from disk
+		multipartBody, contentType, err := buildMultipartBody(request.FormData, "", &s.files)
+		if err != nil {
+			return nil, err
+		}
+		multipartContentType = contentType
+		body = bytes.NewReader(multipartBody)
+	} else if request.BodyType == "form" && len(request.FormData) > 0 {
 		// Handle form data
 		formValues := url.Values{}
 		for _, item := range request.FormData {
@@ -175,6 +323,11 @@ func (s *RequestService) executeHTTPRequest(request *models.Request) (*models.Re
 		}
 	}
 
+	// Multipart bodies always need the boundary chosen by the writer
+	if multipartContentType != "" {
+		req.Header.Set("Content-Type", multipartContentType)
+	}
+
 	resp, err := client.Do(req)
 	duration := time.Since(start)
 	
@@ -291,7 +444,7 @@ func (s *RequestService) buildRawRequest(request *models.Request) string {
 	}
 	
 	// Add Content-Type for form data if not already present
-	if request.BodyType == "form" && len(request.FormData) > 0 {
+	if (request.BodyType == "form" || request.BodyType == "multipart") && len(request.FormData) > 0 {
 		hasContentType := false
 		for key := range request.Headers {
 			if strings.ToLower(key) == "content-type" {
@@ -300,13 +453,20 @@ func (s *RequestService) buildRawRequest(request *models.Request) string {
 			}
 		}
 		if !hasContentType {
-			rawRequest.WriteString("Content-Type: application/x-www-form-urlencoded\r\n")
+			contentType := "application/x-www-form-urlencoded"
+			if request.BodyType == "multipart" {
+				contentType = "multipart/form-data; boundary=" + multipartPreviewBoundary
+			}
+			rawRequest.WriteString("Content-Type: " + contentType + "\r\n")
 		}
 	}
 	
 	// Add Content-Length if there's a body
 	var bodyContent string
-	if request.BodyType == "form" && len(request.FormData) > 0 {
+	if request.BodyType == "multipart" && len(request.FormData) > 0 {
+		multipartBody, _, _ := buildMultipartBody(request.FormData, multipartPreviewBoundary, nil)
+		bodyContent = string(multipartBody)
+	} else if request.BodyType == "form" && len(request.FormData) > 0 {
 		formValues := url.Values{}
 		for _, item := range request.FormData {
 			if item.Key != "" {
@@ -360,4 +520,9 @@ func (s *RequestService) getErrorStatusText(errorMsg string) string {
 	
 	// Default for unknown network errors
 	return "Connection Failed"
-}
\ No newline at end of file
+}
+
+// isHTTPStatus reports whether a status can be sent in a response
+func isHTTPStatus(status int) bool {
+	return status >= 100 && status <= 599
+}
@@ -0,0 +1,338 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"rikuest/internal/models"
+)
+
+// ResponseChange is a difference between two responses. Path is a header name for headers and a
+// path such as $.items[0].id for JSON bodies, and Kind is "added", "removed" or "changed".
+type ResponseChange struct {
+	Path   string      `json:"path"`
+	Kind   string      `json:"kind"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// ResponseDiff compares the responses of two history entries. Bodies that are both JSON are
+// compared structurally and BodyFormat is "json"; other bodies are compared as a whole and
+// BodyFormat is "text".
+type ResponseDiff struct {
+	From       int              `json:"from"`
+	To         int              `json:"to"`
+	Equal      bool             `json:"equal"`
+	Status     *ResponseChange  `json:"status,omitempty"`
+	Headers    []ResponseChange `json:"headers"`
+	BodyFormat string           `json:"body_format"`
+	Body       []ResponseChange `json:"body"`
+}
+
+// diffResponses compares two responses, leaving out the headers and body paths in config
+func diffResponses(before, after models.RequestResponse, config models.ResponseDiffConfig) (*ResponseDiff, error) {
+	ignore := make([][]pathSegment, 0, len(config.IgnorePaths))
+	for _, pattern := range config.IgnorePaths {
+		segments, err := parseJSONPath(pattern)
+		if err != nil {
+			return nil, err
+		}
+		ignore = append(ignore, segments)
+	}
+
+	diff := &ResponseDiff{Headers: []ResponseChange{}, Body: []ResponseChange{}}
+	if before.Status != after.Status {
+		diff.Status = &ResponseChange{Path: "status", Kind: "changed", Before: before.Status, After: after.Status}
+	}
+	diff.Headers = diffHeaders(before.Headers, after.Headers, config.IgnoreHeaders)
+
+	beforeBody, beforeErr := decodeJSONBody(before.Body)
+	afterBody, afterErr := decodeJSONBody(after.Body)
+	if beforeErr == nil && afterErr == nil {
+		diff.BodyFormat = "json"
+		diffJSON(nil, beforeBody, afterBody, ignore, &diff.Body)
+	} else {
+		diff.BodyFormat = "text"
+		if before.Body != after.Body {
+			diff.Body = append(diff.Body, ResponseChange{Path: "$", Kind: "changed", Before: before.Body, After: after.Body})
+		}
+	}
+
+	diff.Equal = diff.Status == nil && len(diff.Headers) == 0 && len(diff.Body) == 0
+	return diff, nil
+}
+
+func diffHeaders(before, after map[string]string, ignoreHeaders []string) []ResponseChange {
+	ignored := make(map[string]bool, len(ignoreHeaders))
+	for _, name := range ignoreHeaders {
+		ignored[http.CanonicalHeaderKey(name)] = true
+	}
+	canonical := func(headers map[string]string) map[string]string {
+		result := make(map[string]string, len(headers))
+		for name, value := range headers {
+			if name = http.CanonicalHeaderKey(name); !ignored[name] {
+				result[name] = value
+			}
+		}
+		return result
+	}
+	beforeHeaders, afterHeaders := canonical(before), canonical(after)
+
+	changes := []ResponseChange{}
+	for _, name := range sortedKeys(mergeKeys(beforeHeaders, afterHeaders)) {
+		previous, hadBefore := beforeHeaders[name]
+		current, hasAfter := afterHeaders[name]
+		switch {
+		case !hadBefore:
+			changes = append(changes, ResponseChange{Path: name, Kind: "added", After: current})
+		case !hasAfter:
+			changes = append(changes, ResponseChange{Path: name, Kind: "removed", Before: previous})
+		case previous != current:
+			changes = append(changes, ResponseChange{Path: name, Kind: "changed", Before: previous, After: current})
+		}
+	}
+	return changes
+}
+
+func mergeKeys(a, b map[string]string) map[string]string {
+	merged := make(map[string]string, len(a)+len(b))
+	for key, value := range a {
+		merged[key] = value
+	}
+	for key, value := range b {
+		merged[key] = value
+	}
+	return merged
+}
+
+// decodeJSONBody parses a JSON body keeping numbers exact. Empty bodies are not JSON.
+func decodeJSONBody(body string) (interface{}, error) {
+	if strings.TrimSpace(body) == "" {
+		return nil, fmt.Errorf("empty body")
+	}
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("trailing data after JSON value")
+	}
+	return value, nil
+}
+
+// diffJSON appends the differences between two JSON values to changes, recursing into objects
+// and arrays. Arrays are compared item by item.
+func diffJSON(path []pathSegment, before, after interface{}, ignore [][]pathSegment, changes *[]ResponseChange) {
+	for _, pattern := range ignore {
+		if matchJSONPath(pattern, path) {
+			return
+		}
+	}
+
+	switch b := before.(type) {
+	case map[string]interface{}:
+		if a, ok := after.(map[string]interface{}); ok {
+			keys := make([]string, 0, len(b)+len(a))
+			for key := range b {
+				keys = append(keys, key)
+			}
+			for key := range a {
+				if _, ok := b[key]; !ok {
+					keys = append(keys, key)
+				}
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				diffJSONChild(append(path, pathSegment{key: key}), b, a, key, ignore, changes)
+			}
+			return
+		}
+	case []interface{}:
+		if a, ok := after.([]interface{}); ok {
+			for i := 0; i < len(b) || i < len(a); i++ {
+				child := append(path, pathSegment{index: i, isIndex: true})
+				switch {
+				case i >= len(a):
+					appendJSONChange(child, "removed", b[i], nil, ignore, changes)
+				case i >= len(b):
+					appendJSONChange(child, "added", nil, a[i], ignore, changes)
+				default:
+					diffJSON(child, b[i], a[i], ignore, changes)
+				}
+			}
+			return
+		}
+	}
+
+	if !jsonEqual(before, after) {
+		*changes = append(*changes, ResponseChange{Path: formatJSONPath(path), Kind: "changed", Before: before, After: after})
+	}
+}
+
+func diffJSONChild(path []pathSegment, before, after map[string]interface{}, key string, ignore [][]pathSegment, changes *[]ResponseChange) {
+	previous, hadBefore := before[key]
+	current, hasAfter := after[key]
+	switch {
+	case !hadBefore:
+		appendJSONChange(path, "added", nil, current, ignore, changes)
+	case !hasAfter:
+		appendJSONChange(path, "removed", previous, nil, ignore, changes)
+	default:
+		diffJSON(path, previous, current, ignore, changes)
+	}
+}
+
+func appendJSONChange(path []pathSegment, kind string, before, after interface{}, ignore [][]pathSegment, changes *[]ResponseChange) {
+	for _, pattern := range ignore {
+		if matchJSONPath(pattern, path) {
+			return
+		}
+	}
+	*changes = append(*changes, ResponseChange{Path: formatJSONPath(path), Kind: kind, Before: before, After: after})
+}
+
+func jsonEqual(a, b interface{}) bool {
+	left, _ := json.Marshal(a)
+	right, _ := json.Marshal(b)
+	return bytes.Equal(left, right)
+}
+
+// pathSegment is one step of a JSON path: an object key or an array index. In ignore patterns
+// a wildcard matches any single step and a recursive segment matches any number of steps.
+type pathSegment struct {
+	key       string
+	index     int
+	isIndex   bool
+	wildcard  bool
+	recursive bool
+}
+
+func formatJSONPath(path []pathSegment) string {
+	var builder strings.Builder
+	builder.WriteString("$")
+	for _, segment := range path {
+		switch {
+		case segment.isIndex:
+			builder.WriteString("[" + strconv.Itoa(segment.index) + "]")
+		case isJSONPathIdentifier(segment.key):
+			builder.WriteString("." + segment.key)
+		default:
+			quoted, _ := json.Marshal(segment.key)
+			builder.WriteString("[" + string(quoted) + "]")
+		}
+	}
+	return builder.String()
+}
+
+func isJSONPathIdentifier(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i, r := range key {
+		if r != '_' && !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') && !(i > 0 && r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// parseJSONPath reads an ignore pattern such as $.meta.timestamp, $.items[*].id or
+// $..updated_at, where * matches any key or index and .. any depth
+func parseJSONPath(pattern string) ([]pathSegment, error) {
+	invalid := func() ([]pathSegment, error) {
+		return nil, fmt.Errorf("invalid ignore path %q, use paths such as $.meta.timestamp, $.items[*].id or $..updated_at", pattern)
+	}
+	if !strings.HasPrefix(pattern, "$") {
+		return invalid()
+	}
+
+	var segments []pathSegment
+	rest := pattern[1:]
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			segments = append(segments, pathSegment{recursive: true})
+			rest = "." + rest[2:]
+			if rest == "." {
+				return invalid()
+			}
+		case strings.HasPrefix(rest, "."):
+			end := strings.IndexAny(rest[1:], ".[")
+			if end == -1 {
+				end = len(rest) - 1
+			}
+			key := rest[1 : end+1]
+			if key == "" {
+				return invalid()
+			}
+			segments = append(segments, pathSegment{key: key, wildcard: key == "*"})
+			rest = rest[end+1:]
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end == -1 {
+				return invalid()
+			}
+			inner := rest[1:end]
+			switch {
+			case inner == "*":
+				segments = append(segments, pathSegment{wildcard: true})
+			case strings.HasPrefix(inner, `"`):
+				var key string
+				if err := json.Unmarshal([]byte(inner), &key); err != nil {
+					return invalid()
+				}
+				segments = append(segments, pathSegment{key: key})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil || index < 0 {
+					return invalid()
+				}
+				segments = append(segments, pathSegment{index: index, isIndex: true})
+			}
+			rest = rest[end+1:]
+		default:
+			return invalid()
+		}
+	}
+	return segments, nil
+}
+
+// matchJSONPath reports whether pattern matches path or one of its ancestors, so that ignoring
+// a path also ignores everything below it
+func matchJSONPath(pattern, path []pathSegment) bool {
+	if len(pattern) == 0 {
+		return true
+	}
+	head := pattern[0]
+	if head.recursive {
+		for skip := 0; skip <= len(path); skip++ {
+			if matchJSONPath(pattern[1:], path[skip:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(path) == 0 {
+		return false
+	}
+	step := path[0]
+	switch {
+	case head.wildcard:
+	case head.isIndex:
+		if !step.isIndex || step.index != head.index {
+			return false
+		}
+	default:
+		if step.isIndex || step.key != head.key {
+			return false
+		}
+	}
+	return matchJSONPath(pattern[1:], path[1:])
+}
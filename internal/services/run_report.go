@@ -0,0 +1,419 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"rikuest/internal/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Formats of run reports
+const (
+	RunReportJUnit = "junit"
+	RunReportTAP   = "tap"
+	RunReportJSON  = "json"
+	RunReportHTML  = "html"
+)
+
+// RunReportFormats lists the formats WriteRunReport writes
+var RunReportFormats = []string{RunReportJUnit, RunReportTAP, RunReportJSON, RunReportHTML}
+
+// runReportBodyLimit truncates the response bodies quoted in failure details
+const runReportBodyLimit = 4 * 1024
+
+// RunReportContentType returns the media type of a report format, or "" for an unknown one
+func RunReportContentType(format string) string {
+	switch format {
+	case RunReportJUnit:
+		return "application/xml; charset=utf-8"
+	case RunReportTAP:
+		return "text/plain; charset=utf-8"
+	case RunReportJSON:
+		return "application/json; charset=utf-8"
+	case RunReportHTML:
+		return "text/html; charset=utf-8"
+	}
+	return ""
+}
+
+// WriteRunReport writes the result of a run in a report format. Reports leave out the raw
+// request of each response, which holds the credentials that were sent.
+func WriteRunReport(w io.Writer, result *models.RunResult, format string) error {
+	result = withoutRawRequests(result)
+	switch format {
+	case RunReportJUnit:
+		return writeJUnitReport(w, result)
+	case RunReportTAP:
+		return writeTAPReport(w, result)
+	case RunReportJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	case RunReportHTML:
+		return runReportTemplate.Execute(w, result)
+	}
+	return fmt.Errorf("unknown report format %q, expected one of %s", format, strings.Join(RunReportFormats, ", "))
+}
+
+func withoutRawRequests(result *models.RunResult) *models.RunResult {
+	copied := *result
+	copied.Results = make([]models.RunItemResult, len(result.Results))
+	for i, item := range result.Results {
+		if item.Response != nil {
+			response := *item.Response
+			response.RawRequest = ""
+			item.Response = &response
+		}
+		copied.Results[i] = item
+	}
+	return &copied
+}
+
+// runFailure describes why a request of a run failed
+type runFailure struct {
+	// Kind is "error" when the request got no response and "status" when the response has a
+	// failing status
+	Kind      string
+	Message   string
+	Status    int
+	Headers   []docsParam
+	Body      string
+	Truncated bool
+}
+
+func describeRunFailure(item models.RunItemResult) *runFailure {
+	if item.Passed {
+		return nil
+	}
+	failure := &runFailure{Kind: "error", Message: item.Error}
+	response := item.Response
+	if response == nil || response.Status == 0 {
+		return failure
+	}
+
+	failure.Kind = "status"
+	failure.Status = response.Status
+	for _, header := range sortedKeys(response.Headers) {
+		if !strings.EqualFold(header, "Set-Cookie") {
+			failure.Headers = append(failure.Headers, docsParam{Name: header, Value: response.Headers[header]})
+		}
+	}
+	failure.Body, failure.Truncated = bodyExcerpt(response.Body, runReportBodyLimit)
+	return failure
+}
+
+// text renders the failure as plain text, for reports without structure of their own
+func (f *runFailure) text(item models.RunItemResult) string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "%s %s\n%s\n", item.Method, item.URL, f.Message)
+	if len(f.Headers) > 0 {
+		out.WriteString("\n")
+		for _, header := range f.Headers {
+			fmt.Fprintf(&out, "%s: %s\n", header.Name, header.Value)
+		}
+	}
+	if f.Body != "" {
+		out.WriteString("\n" + f.Body + "\n")
+		if f.Truncated {
+			out.WriteString("[truncated]\n")
+		}
+	}
+	return out.String()
+}
+
+func runItemTitle(item models.RunItemResult) string {
+	return item.Method + " " + item.Name
+}
+
+// junitTime formats a duration in milliseconds as the seconds JUnit reports expect
+func junitTime(milliseconds int64) string {
+	return fmt.Sprintf("%.3f", float64(milliseconds)/1000)
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+
+	duration int64
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",cdata"`
+}
+
+// writeJUnitReport writes a testcase per request, in a testsuite per folder. Requests that got
+// a failing status are failures, and requests that got no response errors.
+func writeJUnitReport(w io.Writer, result *models.RunResult) error {
+	report := junitTestSuites{Name: result.Name, Time: junitTime(result.Duration)}
+	suites := make(map[string]int)
+	for _, item := range result.Results {
+		name := result.Name
+		if item.Folder != "" {
+			name += " / " + item.Folder
+		}
+		index, ok := suites[name]
+		if !ok {
+			index = len(report.Suites)
+			suites[name] = index
+			report.Suites = append(report.Suites, junitTestSuite{
+				Name:      name,
+				Timestamp: result.StartedAt.UTC().Format("2006-01-02T15:04:05"),
+			})
+		}
+		suite := &report.Suites[index]
+
+		testCase := junitTestCase{Name: runItemTitle(item), Classname: name, Time: junitTime(item.Duration)}
+		if failure := describeRunFailure(item); failure != nil {
+			problem := &junitProblem{Message: failure.Message, Type: failure.Kind, Text: xmlText(failure.text(item))}
+			if failure.Kind == "error" {
+				testCase.Error = problem
+				suite.Errors++
+				report.Errors++
+			} else {
+				testCase.Failure = problem
+				suite.Failures++
+				report.Failures++
+			}
+		}
+		suite.Cases = append(suite.Cases, testCase)
+		suite.Tests++
+		suite.duration += item.Duration
+		report.Tests++
+	}
+	for i := range report.Suites {
+		report.Suites[i].Time = junitTime(report.Suites[i].duration)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// xmlText replaces the characters XML 1.0 does not allow, such as the NUL and escape codes of
+// binary bodies, and invalid UTF-8 with U+FFFD. The encoder escapes attributes but writes CDATA
+// as it is, which would leave the report unreadable.
+func xmlText(text string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\t' || r == '\n' || r == '\r',
+			r >= 0x20 && r <= 0xD7FF,
+			r >= 0xE000 && r <= 0xFFFD,
+			r >= 0x10000 && r <= 0x10FFFF:
+			return r
+		}
+		return '\uFFFD'
+	}, text)
+}
+
+// tapDiagnostic is the YAML block following each test point of a TAP report
+type tapDiagnostic struct {
+	Message    string            `yaml:"message,omitempty"`
+	Severity   string            `yaml:"severity,omitempty"`
+	Folder     string            `yaml:"folder,omitempty"`
+	Method     string            `yaml:"method"`
+	URL        string            `yaml:"url"`
+	Status     int               `yaml:"status,omitempty"`
+	DurationMs int64             `yaml:"duration_ms"`
+	Headers    map[string]string `yaml:"headers,omitempty"`
+	Body       string            `yaml:"body,omitempty"`
+}
+
+// writeTAPReport writes a TAP version 13 report, with a test point per request
+func writeTAPReport(w io.Writer, result *models.RunResult) error {
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "TAP version 13\n1..%d\n# %s\n", len(result.Results), result.Name)
+	for i, item := range result.Results {
+		diagnostic := tapDiagnostic{Folder: item.Folder, Method: item.Method, URL: item.URL, DurationMs: item.Duration}
+		if item.Response != nil {
+			diagnostic.Status = item.Response.Status
+		}
+
+		status := "ok"
+		if failure := describeRunFailure(item); failure != nil {
+			status = "not ok"
+			diagnostic.Message = failure.Message
+			diagnostic.Severity = "fail"
+			if len(failure.Headers) > 0 {
+				diagnostic.Headers = make(map[string]string, len(failure.Headers))
+				for _, header := range failure.Headers {
+					diagnostic.Headers[header.Name] = header.Value
+				}
+			}
+			diagnostic.Body = failure.Body
+		}
+		// "#" starts a directive in a test point description
+		fmt.Fprintf(&out, "%s %d - %s\n", status, i+1, strings.ReplaceAll(runItemTitle(item), "#", `\#`))
+
+		var block bytes.Buffer
+		encoder := yaml.NewEncoder(&block)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(diagnostic); err != nil {
+			return err
+		}
+		out.WriteString("  ---\n")
+		for _, line := range strings.Split(strings.TrimRight(block.String(), "\n"), "\n") {
+			out.WriteString("  " + line + "\n")
+		}
+		out.WriteString("  ...\n")
+	}
+	fmt.Fprintf(&out, "# passed %d\n# failed %d\n# duration %s\n", result.Passed, result.Failed, time.Duration(result.Duration)*time.Millisecond)
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// runReportRow is a request of the HTML report
+type runReportRow struct {
+	models.RunItemResult
+	Failure *runFailure
+}
+
+var runReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"rows": func(result *models.RunResult) []runReportRow {
+		rows := make([]runReportRow, len(result.Results))
+		for i, item := range result.Results {
+			rows[i] = runReportRow{RunItemResult: item, Failure: describeRunFailure(item)}
+		}
+		return rows
+	},
+	"lower":       strings.ToLower,
+	"statusClass": func(status int) string { return fmt.Sprintf("s%dxx", status/100) },
+	"duration": func(milliseconds int64) string {
+		return (time.Duration(milliseconds) * time.Millisecond).String()
+	},
+	"timestamp": func(t time.Time) string { return t.UTC().Format("2006-01-02 15:04:05 UTC") },
+	"css":       func() template.CSS { return template.CSS(runReportStyle) },
+}).Parse(runReportLayout))
+
+const runReportLayout = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Name}} - Run report</title>
+<style>{{css}}</style>
+</head>
+<body>
+<main>
+<h1>{{.Name}}</h1>
+<p class="muted">Started {{timestamp .StartedAt}}, took {{duration .Duration}}</p>
+<div class="summary">
+  <div class="count passed"><strong>{{.Passed}}</strong> passed</div>
+  <div class="count failed"><strong>{{.Failed}}</strong> failed</div>
+  <div class="count"><strong>{{len .Results}}</strong> requests</div>
+</div>
+<table>
+<thead><tr><th>Result</th><th>Request</th><th>Response</th><th class="time">Time</th></tr></thead>
+<tbody>
+{{- range rows .}}
+<tr class="{{if .Passed}}pass{{else}}fail{{end}}">
+  <td><span class="result">{{if .Passed}}PASS{{else}}FAIL{{end}}</span></td>
+  <td>
+    {{- if .Folder}}<div class="muted">{{.Folder}}</div>{{end}}
+    <span class="method {{lower .Method}}">{{.Method}}</span> {{.Name}}
+    <div class="url"><code>{{.URL}}</code></div>
+    {{- with .Failure}}{{if or .Headers .Body}}
+    <details>
+      <summary>{{.Message}}</summary>
+      {{- if .Headers}}
+      <table class="headers">
+        {{- range .Headers}}<tr><th>{{.Name}}</th><td><code>{{.Value}}</code></td></tr>{{end}}
+      </table>
+      {{- end}}
+      {{- if .Body}}
+      <pre><code>{{.Body}}</code></pre>
+      {{- if .Truncated}}<p class="muted">The body is truncated.</p>{{end}}
+      {{- end}}
+    </details>
+    {{- end}}{{end}}
+  </td>
+  <td>{{with .Response}}{{if .Status}}<span class="status {{statusClass .Status}}">{{.StatusText}}</span>{{else}}{{.StatusText}}{{end}}{{else}}{{.Error}}{{end}}</td>
+  <td class="time">{{duration .Duration}}</td>
+</tr>
+{{- end}}
+</tbody>
+</table>
+<footer>Generated by Rikuest</footer>
+</main>
+</body>
+</html>
+`
+
+const runReportStyle = `:root {
+  --text: #1f2937; --muted: #6b7280; --border: #e5e7eb; --subtle: #f9fafb;
+  --pass: #16a34a; --fail: #dc2626;
+}
+* { box-sizing: border-box; }
+body { margin: 0; color: var(--text); font: 15px/1.6 -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif; }
+main { max-width: 1100px; margin: 0 auto; padding: 32px 24px; }
+h1 { margin: 0; }
+.muted, footer { color: var(--muted); font-size: 13px; }
+.summary { display: flex; gap: 16px; margin: 24px 0; }
+.count { padding: 12px 20px; border: 1px solid var(--border); border-radius: 8px; background: var(--subtle); }
+.count strong { font-size: 24px; margin-right: 4px; }
+.count.passed strong { color: var(--pass); }
+.count.failed strong { color: var(--fail); }
+table { border-collapse: collapse; width: 100%; font-size: 14px; }
+th, td { text-align: left; padding: 8px 10px; border-bottom: 1px solid var(--border); vertical-align: top; }
+th { background: var(--subtle); }
+.time { text-align: right; white-space: nowrap; }
+.result { font: 600 12px/1.5 ui-monospace, SFMono-Regular, Menlo, monospace; }
+tr.pass .result { color: var(--pass); }
+tr.fail .result { color: var(--fail); }
+tr.fail { background: #fef2f2; }
+code { font: 13px/1.5 ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; word-break: break-all; }
+.url code { color: var(--muted); }
+pre { background: #fff; border: 1px solid var(--border); border-radius: 6px; padding: 12px; overflow-x: auto; max-height: 400px; }
+pre code { word-break: normal; }
+details { margin-top: 8px; }
+details summary { cursor: pointer; color: var(--fail); }
+table.headers { margin: 8px 0; }
+table.headers th { width: 30%; font-weight: normal; }
+.method { font: 600 12px/1.5 ui-monospace, SFMono-Regular, Menlo, monospace; color: #6b7280; }
+.method.get { color: #16a34a; } .method.post { color: #ca8a04; } .method.put { color: #2563eb; }
+.method.patch { color: #7c3aed; } .method.delete { color: #dc2626; }
+.status { font-weight: 600; padding: 1px 6px; border-radius: 4px; color: #fff; background: #6b7280; white-space: nowrap; }
+.status.s2xx { background: #16a34a; } .status.s3xx { background: #2563eb; }
+.status.s4xx { background: #ca8a04; } .status.s5xx { background: #dc2626; }
+footer { margin-top: 32px; }
+`
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"rikuest/internal/database"
+	"rikuest/internal/models"
+)
+
+// RunnerService runs sets of requests one after another, like a collection run. Requests are
+// executed as they are from the app, with the active environment, and recorded in history.
+type RunnerService struct {
+	db       *database.DB
+	requests *RequestService
+	tags     *TagService
+}
+
+func NewRunnerService(db *database.DB, files FileAccess) *RunnerService {
+	return &RunnerService{db: db, requests: NewRequestService(db, files), tags: NewTagService(db)}
+}
+
+// RunSavedFilter runs the requests a saved filter selects, in the order of the folder tree
+func (s *RunnerService) RunSavedFilter(filterID int) (*models.RunResult, error) {
+	filter, err := s.tags.GetSavedFilter(filterID)
+	if err != nil {
+		return nil, err
+	}
+	requests, err := s.tags.FindRequests(filter.ProjectID, filter.Filter)
+	if err != nil {
+		return nil, err
+	}
+	folders, err := s.db.GetFolders(filter.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	environment, err := s.db.GetActiveEnvironment(filter.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load active environment: %w", err)
+	}
+	return s.run(filter.Name, treeOrder(folders, requests), folders, environment), nil
+}
+
+// RunProject runs the requests of a project in the order of the folder tree. When a folder is
+// selected, only the requests of that folder and its subfolders run.
+func (s *RunnerService) RunProject(projectID int, options models.RunOptions) (*models.RunResult, error) {
+	project, err := s.db.GetProject(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("project not found: %w", err)
+	}
+	folders, err := s.db.GetFolders(projectID)
+	if err != nil {
+		return nil, err
+	}
+	requests, err := s.db.GetRequests(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	name := project.Name
+	if options.FolderID != nil {
+		folder, err := s.db.GetFolder(*options.FolderID)
+		if err != nil || folder.ProjectID != projectID {
+			return nil, fmt.Errorf("folder %d not found in project %q", *options.FolderID, project.Name)
+		}
+		name = project.Name + " / " + folder.Name
+
+		subtree := folderSubtree(folders, folder.ID)
+		selected := requests[:0]
+		for _, request := range requests {
+			if request.FolderID != nil && subtree[*request.FolderID] {
+				selected = append(selected, request)
+			}
+		}
+		requests = selected
+	}
+
+	var environment *models.Environment
+	if options.EnvironmentID != nil {
+		environment, err = s.db.GetEnvironment(*options.EnvironmentID)
+		if err != nil || environment.ProjectID != projectID {
+			return nil, fmt.Errorf("environment %d not found in project %q", *options.EnvironmentID, project.Name)
+		}
+	} else if environment, err = s.db.GetActiveEnvironment(projectID); err != nil {
+		return nil, fmt.Errorf("failed to load active environment: %w", err)
+	}
+	if len(options.Variables) > 0 {
+		environment = withVariables(environment, options.Variables)
+	}
+
+	return s.run(name, treeOrder(folders, requests), folders, environment), nil
+}
+
+// withVariables returns a copy of an environment, which may be nil, with variables added or
+// replacing the ones of the same name
+func withVariables(environment *models.Environment, variables []models.Variable) *models.Environment {
+	merged := &models.Environment{Name: "Run variables"}
+	if environment != nil {
+		*merged = *environment
+	}
+	merged.Variables = nil
+
+	overridden := make(map[string]bool, len(variables))
+	for _, variable := range variables {
+		overridden[variable.Key] = true
+	}
+	if environment != nil {
+		for _, variable := range environment.Variables {
+			if !overridden[variable.Key] {
+				merged.Variables = append(merged.Variables, variable)
+			}
+		}
+	}
+	merged.Variables = append(merged.Variables, variables...)
+	return merged
+}
+
+// run executes requests one after another with the variables of an environment, which may be nil
+func (s *RunnerService) run(name string, requests []models.Request, folders []models.Folder, environment *models.Environment) *models.RunResult {
+	result := &models.RunResult{Name: name, StartedAt: time.Now().UTC(), Results: []models.RunItemResult{}}
+	for _, request := range requests {
+		item := models.RunItemResult{
+			RequestID: request.ID,
+			Name:      request.Name,
+			Method:    request.Method,
+			Folder:    strings.Join(folderNames(folders, request.FolderID), " / "),
+			URL:       request.URL,
+		}
+		started := time.Now()
+		response, err := s.requests.executeInEnvironment(&request, environment)
+		item.Duration = time.Since(started).Milliseconds()
+		switch {
+		case err != nil:
+			item.Error = err.Error()
+		case request.ExpectedStatus != 0 && response.Status != request.ExpectedStatus:
+			item.Response = response
+			item.Error = fmt.Sprintf("expected status %d, got %s", request.ExpectedStatus, response.StatusText)
+		case request.ExpectedStatus == 0 && (response.Status == 0 || response.Status >= 400):
+			item.Response = response
+			item.Error = response.StatusText
+		default:
+			item.Response = response
+			item.Passed = true
+		}
+
+		if item.Passed {
+			result.Passed++
+		} else {
+			result.Failed++
+		}
+		result.Results = append(result.Results, item)
+	}
+	result.Duration = time.Since(result.StartedAt).Milliseconds()
+	return result
+}
+
+// treeOrder sorts requests as the folder tree shows them: the requests of a folder first, then
+// its subfolders, each in position order
+func treeOrder(folders []models.Folder, requests []models.Request) []models.Request {
+	byFolder := make(map[int][]models.Request)
+	for _, request := range requests {
+		key := 0
+		if request.FolderID != nil {
+			key = *request.FolderID
+		}
+		byFolder[key] = append(byFolder[key], request)
+	}
+	children := make(map[int][]models.Folder)
+	for _, folder := range folders {
+		key := 0
+		if folder.ParentID != nil {
+			key = *folder.ParentID
+		}
+		children[key] = append(children[key], folder)
+	}
+
+	ordered := make([]models.Request, 0, len(requests))
+	var walk func(folderID int)
+	walk = func(folderID int) {
+		ordered = append(ordered, byFolder[folderID]...)
+		for _, child := range children[folderID] {
+			walk(child.ID)
+		}
+	}
+	walk(0)
+	return ordered
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"rikuest/internal/database"
+	"rikuest/internal/models"
+)
+
+// SearchService finds requests, folders and history responses by their content
+type SearchService struct {
+	db *database.DB
+}
+
+func NewSearchService(db *database.DB) *SearchService {
+	return &SearchService{db: db}
+}
+
+// Search returns the items matching every word of the query, best matches first
+func (s *SearchService) Search(query models.SearchQuery) ([]models.SearchResult, error) {
+	if strings.TrimSpace(query.Query) == "" {
+		return []models.SearchResult{}, nil
+	}
+	if query.ProjectID != 0 {
+		if _, err := s.db.GetProject(query.ProjectID); err != nil {
+			return nil, fmt.Errorf("project not found: %w", err)
+		}
+	}
+	return s.db.Search(query)
+}
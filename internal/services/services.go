@@ -4,22 +4,59 @@ import "rikuest/internal/database"
 
 // Services contains all business logic services
 type Services struct {
-	Project   *ProjectService
-	Request   *RequestService
-	Folder    *FolderService
-	Format    *FormatService
-	Config    *ConfigService
-	Telemetry *TelemetryService
+	Project     *ProjectService
+	Request     *RequestService
+	Folder      *FolderService
+	Format      *FormatService
+	Config      *ConfigService
+	Telemetry   *TelemetryService
+	Mock        *MockService
+	Proxy       *ProxyService
+	HAR         *HARService
+	Environment *EnvironmentService
+	Postman     *PostmanService
+	Insomnia    *InsomniaService
+	HTTPFile    *HTTPFileService
+	OpenAPI     *OpenAPIService
+	Storage     *StorageService
+	Backup      *BackupService
+	Trash       *TrashService
+	History     *HistoryService
+	Search      *SearchService
+	Tag         *TagService
+	Runner      *RunnerService
+	Docs        *DocsService
+	Files       FileAccess
 }
 
-// NewServices creates a new services container
-func NewServices(db *database.DB, webhookURL string) *Services {
+// NewServices creates a new services container. files sets the local files services may read and
+// write for their clients.
+func NewServices(db *database.DB, webhookURL string, files FileAccess) *Services {
+	format := NewFormatService()
+
 	return &Services{
-		Project:   NewProjectService(db),
-		Request:   NewRequestService(db),
-		Folder:    NewFolderService(db),
-		Format:    NewFormatService(),
-		Config:    NewConfigService(db),
-		Telemetry: NewTelemetryService(db, webhookURL),
+		Project:     NewProjectService(db),
+		Request:     NewRequestService(db, files),
+		Folder:      NewFolderService(db),
+		Format:      format,
+		Config:      NewConfigService(db),
+		Telemetry:   NewTelemetryService(db, webhookURL),
+		Mock:        NewMockService(db),
+		Proxy:       NewProxyService(db, format),
+		HAR:         NewHARService(db, format),
+		Environment: NewEnvironmentService(db),
+		Postman:     NewPostmanService(db),
+		Insomnia:    NewInsomniaService(db),
+		HTTPFile:    NewHTTPFileService(db, format),
+		OpenAPI:     NewOpenAPIService(db),
+		Storage:     NewStorageService(db, files),
+		Backup:      NewBackupService(db),
+		Trash:       NewTrashService(db),
+		History:     NewHistoryService(db, files),
+		Search:      NewSearchService(db),
+		Tag:         NewTagService(db),
+		Runner:      NewRunnerService(db, files),
+		Docs:        NewDocsService(db, files),
+		Files:       files,
 	}
 }
@@ -0,0 +1,896 @@
+package services
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"rikuest/internal/database"
+	"rikuest/internal/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	storageProjectFile = "project.yaml"
+	storageFolderFile  = "folder.yaml"
+	storagePollEvery   = 2 * time.Second
+)
+
+// storageHeader starts every file written for a stored project. Its kind tells the files of the
+// app from other YAML files sharing the directory, which are left alone.
+type storageHeader struct {
+	Kind string `yaml:"kind"`
+}
+
+// storageProject is the content of project.yaml at the root of a stored project
+type storageProject struct {
+	Kind        string `yaml:"kind"`
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+}
+
+// storageFolder is the content of folder.yaml in the directory of a folder
+type storageFolder struct {
+	Kind        string `yaml:"kind"`
+	Name        string `yaml:"name"`
+	Order       int    `yaml:"order"`
+	Description string `yaml:"description,omitempty"`
+}
+
+// storageRequest is the content of a request file. Fields are written in a fixed order and
+// headers sorted by name, so saving a request only changes the lines that were edited.
+type storageRequest struct {
+	Kind        string              `yaml:"kind"`
+	Name        string              `yaml:"name"`
+	Order       int                 `yaml:"order"`
+	Method      string              `yaml:"method"`
+	URL         string              `yaml:"url"`
+	Headers     map[string]string   `yaml:"headers,omitempty"`
+	Query       []storageQueryParam `yaml:"query,omitempty"`
+	Auth        *storageAuth        `yaml:"auth,omitempty"`
+	Body        *storageBody        `yaml:"body,omitempty"`
+	Expect      int                 `yaml:"expect,omitempty"`
+	Description string              `yaml:"description,omitempty"`
+}
+
+type storageQueryParam struct {
+	Key      string `yaml:"key"`
+	Value    string `yaml:"value"`
+	Disabled bool   `yaml:"disabled,omitempty"`
+}
+
+type storageAuth struct {
+	Type     string `yaml:"type"`
+	Token    string `yaml:"token,omitempty"`
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+}
+
+type storageBody struct {
+	Type    string             `yaml:"type"`
+	Content string             `yaml:"content,omitempty"`
+	Form    []storageFormField `yaml:"form,omitempty"`
+}
+
+type storageFormField struct {
+	Key   string `yaml:"key"`
+	Value string `yaml:"value"`
+	Type  string `yaml:"type,omitempty"`
+}
+
+// storageState is what the watcher knows about a stored project since the app started
+type storageState struct {
+	files    map[string][]byte
+	syncedAt *time.Time
+	err      string
+}
+
+// StorageService keeps projects in directories of YAML files, one per request, so they can be
+// versioned with git. A watcher polls linked directories: files changed on disk are loaded into
+// the database and changes made in the app are written back. Directories must be within reach
+// of files.
+type StorageService struct {
+	db     *database.DB
+	files  FileAccess
+	mutex  sync.Mutex
+	states map[int]*storageState
+	stop   chan struct{}
+	// stopped is closed once the watcher has returned
+	stopped chan struct{}
+}
+
+func NewStorageService(db *database.DB, files FileAccess) *StorageService {
+	return &StorageService{db: db, files: files, states: make(map[int]*storageState)}
+}
+
+// Start polls linked directories in the background until Stop is called
+func (s *StorageService) Start() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.stop != nil {
+		return
+	}
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	s.stop = stop
+	s.stopped = stopped
+
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(storagePollEvery)
+		defer ticker.Stop()
+
+		s.syncAll()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				s.syncAll()
+			}
+		}
+	}()
+}
+
+// Stop stops the watcher and waits for a sync in progress to finish
+func (s *StorageService) Stop() {
+	s.mutex.Lock()
+	stop, stopped := s.stop, s.stopped
+	s.stop, s.stopped = nil, nil
+	s.mutex.Unlock()
+
+	// The watcher takes the mutex to sync, so it is waited for without holding it
+	if stop != nil {
+		close(stop)
+		<-stopped
+	}
+}
+
+// GetStorage returns the directory of a project, or nil if it is only kept in the database
+func (s *StorageService) GetStorage(projectID int) (*models.ProjectStorage, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	storage, err := s.db.GetProjectStorage(projectID)
+	if err != nil || storage == nil {
+		return storage, err
+	}
+	s.describe(storage)
+	return storage, nil
+}
+
+// Link stores a project in a directory. Requests already in the directory are loaded into the
+// project, and the project is then written to the directory.
+func (s *StorageService) Link(projectID int, dir string) (*models.ProjectStorage, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, err := s.db.GetProject(projectID); err != nil {
+		return nil, fmt.Errorf("project not found: %w", err)
+	}
+	if strings.TrimSpace(dir) == "" {
+		return nil, fmt.Errorf("directory is required")
+	}
+	dir, err := s.files.Resolve(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	storages, err := s.db.GetProjectStorages()
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range storages {
+		if existing.Path == dir && existing.ProjectID != projectID {
+			return nil, fmt.Errorf("directory is already used by project %d", existing.ProjectID)
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	storage := &models.ProjectStorage{ProjectID: projectID, Path: dir}
+	if err := s.db.SetProjectStorage(storage); err != nil {
+		return nil, err
+	}
+	delete(s.states, projectID)
+
+	if err := s.sync(storage); err != nil {
+		return nil, err
+	}
+	s.describe(storage)
+	return storage, nil
+}
+
+// OpenDirectory creates a project from a directory written by Link, for example one checked
+// out from git, and keeps it in sync
+func (s *StorageService) OpenDirectory(dir string) (*models.Project, error) {
+	dir, err := s.files.Resolve(dir)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(filepath.Join(dir, storageProjectFile))
+	if err != nil {
+		return nil, fmt.Errorf("not a Rikuest project directory: %w", err)
+	}
+	var file storageProject
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", storageProjectFile, err)
+	}
+	if file.Kind != "project" {
+		return nil, fmt.Errorf("not a Rikuest project directory: %s has no kind: project", storageProjectFile)
+	}
+	if file.Name == "" {
+		file.Name = filepath.Base(dir)
+	}
+
+	project := &models.Project{Name: file.Name, Description: file.Description}
+	if err := s.db.CreateProject(project); err != nil {
+		return nil, fmt.Errorf("failed to create project %q: %w", file.Name, err)
+	}
+	if _, err := s.Link(project.ID, dir); err != nil {
+		s.db.PurgeProject(project.ID)
+		return nil, err
+	}
+	return project, nil
+}
+
+// Unlink stops syncing a project. The directory and its files are left as they are.
+func (s *StorageService) Unlink(projectID int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.states, projectID)
+	return s.db.DeleteProjectStorage(projectID)
+}
+
+// Sync synchronizes a project with its directory right away instead of waiting for the watcher
+func (s *StorageService) Sync(projectID int) (*models.ProjectStorage, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	storage, err := s.db.GetProjectStorage(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if storage == nil {
+		return nil, fmt.Errorf("project is not stored in a directory")
+	}
+	if err := s.sync(storage); err != nil {
+		return nil, err
+	}
+	s.describe(storage)
+	return storage, nil
+}
+
+func (s *StorageService) syncAll() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	storages, err := s.db.GetProjectStorages()
+	if err != nil {
+		log.Printf("Failed to list stored projects: %v", err)
+		return
+	}
+	for i := range storages {
+		s.sync(&storages[i])
+	}
+}
+
+func (s *StorageService) describe(storage *models.ProjectStorage) {
+	if state := s.states[storage.ProjectID]; state != nil {
+		storage.LastSyncedAt = state.syncedAt
+		storage.Error = state.err
+	}
+}
+
+// sync loads the directory when its files changed since the last sync, or on the first sync
+// after linking or starting the app, and then writes whatever differs from the database.
+// A directory with files that cannot be read is left untouched until they are fixed.
+func (s *StorageService) sync(storage *models.ProjectStorage) error {
+	state := s.states[storage.ProjectID]
+	if state == nil {
+		state = &storageState{}
+		s.states[storage.ProjectID] = state
+	}
+
+	err := s.syncFiles(storage, state)
+	if err != nil {
+		if err.Error() != state.err {
+			log.Printf("Failed to sync project %d with %s: %v", storage.ProjectID, storage.Path, err)
+		}
+		state.err = err.Error()
+		return err
+	}
+
+	now := time.Now()
+	state.syncedAt = &now
+	state.err = ""
+	return nil
+}
+
+func (s *StorageService) syncFiles(storage *models.ProjectStorage, state *storageState) error {
+	if _, err := s.db.GetProject(storage.ProjectID); err == sql.ErrNoRows {
+		// The project was deleted, stop syncing it
+		delete(s.states, storage.ProjectID)
+		return s.db.DeleteProjectStorage(storage.ProjectID)
+	} else if err != nil {
+		return err
+	}
+	if _, err := s.files.Resolve(storage.Path); err != nil {
+		return err
+	}
+
+	disk, err := readStorageFiles(storage.Path)
+	if err != nil {
+		return err
+	}
+	known, err := s.db.GetStorageEntries(storage.ProjectID)
+	if err != nil {
+		return err
+	}
+	disk, foreign := ownStorageFiles(disk, known)
+	if foreign[storageProjectFile] {
+		return fmt.Errorf("%s was not written by Rikuest, use another directory", storageProjectFile)
+	}
+	if len(disk) > 0 && (state.files == nil || !sameStorageFiles(disk, state.files)) {
+		if err := s.load(storage.ProjectID, disk); err != nil {
+			return err
+		}
+	}
+
+	files, entries, err := s.render(storage.ProjectID, foreign)
+	if err != nil {
+		return err
+	}
+	if !sameStorageFiles(files, disk) {
+		if err := writeStorageFiles(storage.Path, files, disk); err != nil {
+			return err
+		}
+	}
+	if err := s.db.ReplaceStorageEntries(storage.ProjectID, entries); err != nil {
+		return err
+	}
+
+	state.files = files
+	return nil
+}
+
+// render lays out a project as files. Items keep the file name they were loaded from or last
+// written to while they stay in the same folder, so renaming a request does not move its file.
+// Names of foreign files are avoided.
+func (s *StorageService) render(projectID int, foreign map[string]bool) (map[string][]byte, []models.StorageEntry, error) {
+	project, err := s.db.GetProject(projectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	folders, err := s.db.GetFolders(projectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	requests, err := s.db.GetRequests(projectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	existing, err := s.db.GetStorageEntries(projectID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Items whose folder no longer exists are written one level up, at the root
+	folderIDs := make(map[int]bool, len(folders))
+	for _, folder := range folders {
+		folderIDs[folder.ID] = true
+	}
+	for i := range folders {
+		if folders[i].ParentID != nil && !folderIDs[*folders[i].ParentID] {
+			folders[i].ParentID = nil
+		}
+	}
+	for i := range requests {
+		if requests[i].FolderID != nil && !folderIDs[*requests[i].FolderID] {
+			requests[i].FolderID = nil
+		}
+	}
+
+	previous := make(map[string]string)
+	for _, entry := range existing {
+		previous[fmt.Sprintf("%s:%d", entry.Kind, entry.ItemID)] = entry.Path
+	}
+
+	files := make(map[string][]byte)
+	var entries []models.StorageEntry
+	var renderErr error
+	encode := func(name string, value interface{}) {
+		content, err := encodeStorageYAML(value)
+		if err != nil && renderErr == nil {
+			renderErr = err
+		}
+		files[name] = content
+	}
+	encode(storageProjectFile, storageProject{Kind: "project", Name: project.Name, Description: project.Description})
+
+	var renderLevel func(parentID *int, dir string)
+	renderLevel = func(parentID *int, dir string) {
+		used := map[string]bool{"project": true, "folder": true}
+		for name := range foreign {
+			if path.Dir(name) == path.Join(".", dir) {
+				used[strings.TrimSuffix(path.Base(name), ".yaml")] = true
+			} else if path.Base(name) == storageFolderFile && path.Dir(path.Dir(name)) == path.Join(".", dir) {
+				used[path.Base(path.Dir(name))] = true
+			}
+		}
+
+		for i := range folders {
+			folder := &folders[i]
+			if !sameFolder(folder.ParentID, parentID) {
+				continue
+			}
+			name := storageName(previous[fmt.Sprintf("folder:%d", folder.ID)], dir, folder.Name, "folder", "", used)
+			folderDir := path.Join(dir, name)
+			encode(path.Join(folderDir, storageFolderFile), storageFolder{Kind: "folder", Name: folder.Name, Order: folder.Position, Description: folder.Description})
+			entries = append(entries, models.StorageEntry{ProjectID: projectID, Path: folderDir, Kind: "folder", ItemID: folder.ID})
+			renderLevel(&folder.ID, folderDir)
+		}
+
+		for i := range requests {
+			request := &requests[i]
+			if !sameFolder(request.FolderID, parentID) {
+				continue
+			}
+			name := storageName(previous[fmt.Sprintf("request:%d", request.ID)], dir, request.Name, "request", ".yaml", used)
+			file := path.Join(dir, name+".yaml")
+			encode(file, storageRequestFromModel(request))
+			entries = append(entries, models.StorageEntry{ProjectID: projectID, Path: file, Kind: "request", ItemID: request.ID})
+		}
+	}
+	renderLevel(nil, "")
+
+	if renderErr != nil {
+		return nil, nil, renderErr
+	}
+	return files, entries, nil
+}
+
+// storageName picks the file or directory name of an item in dir: its previous name if it was
+// already in dir, or else the slug of its name made unique
+func storageName(previous, dir, name, fallback, extension string, used map[string]bool) string {
+	if dir == "" {
+		dir = "."
+	}
+	if previous != "" && path.Dir(previous) == dir {
+		base := strings.TrimSuffix(path.Base(previous), extension)
+		if !used[base] {
+			used[base] = true
+			return base
+		}
+	}
+
+	slug := storageSlug(name, fallback)
+	candidate := slug
+	for i := 2; used[candidate]; i++ {
+		candidate = fmt.Sprintf("%s-%d", slug, i)
+	}
+	used[candidate] = true
+	return candidate
+}
+
+// storageSlug turns a name into a lowercase file name, such as "Get user (v2)" into get-user-v2
+func storageSlug(name, fallback string) string {
+	var slug strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && slug.Len() > 0 {
+				slug.WriteRune('-')
+			}
+			slug.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	if slug.Len() == 0 {
+		return fallback
+	}
+	return slug.String()
+}
+
+// load applies the files of a directory to a project, in one transaction so that a failure
+// leaves the project as it was. Items are matched with the database through the paths they were
+// written to, so their history and examples are kept. Items that were on disk before and are
+// gone now are deleted; items created in the app since the last sync are kept and written on
+// the next one.
+func (s *StorageService) load(projectID int, files map[string][]byte) error {
+	var project storageProject
+	folders := make(map[string]*storageFolder)
+	requests := make(map[string]*storageRequest)
+
+	// Parse everything before changing the database
+	for name, content := range files {
+		switch {
+		case name == storageProjectFile:
+			if err := yaml.Unmarshal(content, &project); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		case path.Base(name) == storageFolderFile:
+			var folder storageFolder
+			if err := yaml.Unmarshal(content, &folder); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			folders[path.Dir(name)] = &folder
+		default:
+			var request storageRequest
+			if err := yaml.Unmarshal(content, &request); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			requests[name] = &request
+		}
+	}
+	// Directories without folder.yaml are folders named after the directory
+	for name := range requests {
+		for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+			if folders[dir] == nil {
+				folders[dir] = &storageFolder{Name: path.Base(dir), Order: len(folders)}
+			}
+		}
+	}
+
+	return s.db.InTransaction(func(tx *database.DB) error {
+		return (&StorageService{db: tx}).apply(projectID, &project, folders, requests)
+	})
+}
+
+// apply updates a project to match the parsed files of its directory
+func (s *StorageService) apply(projectID int, project *storageProject, folders map[string]*storageFolder, requests map[string]*storageRequest) error {
+	existing, err := s.db.GetStorageEntries(projectID)
+	if err != nil {
+		return err
+	}
+	previous := make(map[string]int)
+	for _, entry := range existing {
+		previous[entry.Kind+":"+entry.Path] = entry.ItemID
+	}
+
+	if current, err := s.db.GetProject(projectID); err == nil && project.Name != "" &&
+		(current.Name != project.Name || current.Description != project.Description) {
+		current.Name = project.Name
+		current.Description = project.Description
+		if err := s.db.UpdateProject(current); err != nil {
+			log.Printf("Failed to rename project %d to %q: %v", projectID, project.Name, err)
+		}
+	}
+
+	// Parents are created before their children
+	dirs := make([]string, 0, len(folders))
+	for dir := range folders {
+		dirs = append(dirs, dir)
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		if depthI, depthJ := strings.Count(dirs[i], "/"), strings.Count(dirs[j], "/"); depthI != depthJ {
+			return depthI < depthJ
+		}
+		return dirs[i] < dirs[j]
+	})
+
+	folderIDs := make(map[string]int)
+	kept := make(map[string]bool)
+	for _, dir := range dirs {
+		file := folders[dir]
+		var parentID *int
+		if parent := path.Dir(dir); parent != "." {
+			id := folderIDs[parent]
+			parentID = &id
+		}
+
+		folder := &models.Folder{ProjectID: projectID}
+		if id, ok := previous["folder:"+dir]; ok {
+			if found, err := s.db.GetFolder(id); err == nil && found.ProjectID == projectID {
+				folder = found
+			}
+		}
+		folder.Name = file.Name
+		folder.Description = file.Description
+		folder.ParentID = parentID
+		if folder.ID == 0 {
+			if err := s.db.CreateFolder(folder); err != nil {
+				return fmt.Errorf("failed to create folder %s: %w", dir, err)
+			}
+		}
+		folder.Position = file.Order
+		if err := s.db.UpdateFolder(folder); err != nil {
+			return fmt.Errorf("failed to update folder %s: %w", dir, err)
+		}
+		folderIDs[dir] = folder.ID
+		kept[fmt.Sprintf("folder:%d", folder.ID)] = true
+	}
+
+	requestIDs := make(map[string]int)
+	for name, file := range requests {
+		var folderID *int
+		if dir := path.Dir(name); dir != "." {
+			id := folderIDs[dir]
+			folderID = &id
+		}
+
+		request := &models.Request{ProjectID: projectID}
+		if id, ok := previous["request:"+name]; ok {
+			if found, err := s.db.GetRequest(id); err == nil && found.ProjectID == projectID {
+				request = found
+			}
+		}
+		file.applyTo(request)
+		request.FolderID = folderID
+		if request.ID == 0 {
+			if err := s.db.CreateRequest(request); err != nil {
+				return fmt.Errorf("failed to create request %s: %w", name, err)
+			}
+		}
+		request.Position = file.Order
+		if err := s.db.UpdateRequestBy(request, "storage"); err != nil {
+			return fmt.Errorf("failed to update request %s: %w", name, err)
+		}
+		requestIDs[name] = request.ID
+		kept[fmt.Sprintf("request:%d", request.ID)] = true
+	}
+
+	for _, entry := range existing {
+		if kept[fmt.Sprintf("%s:%d", entry.Kind, entry.ItemID)] {
+			continue
+		}
+		var err error
+		if entry.Kind == "request" {
+			err = s.db.DeleteRequest(entry.ItemID)
+		} else {
+			err = s.db.DeleteFolder(entry.ItemID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to delete %s: %w", entry.Path, err)
+		}
+	}
+
+	// Remember the loaded paths so that render keeps them
+	var entries []models.StorageEntry
+	for dir, id := range folderIDs {
+		entries = append(entries, models.StorageEntry{ProjectID: projectID, Path: dir, Kind: "folder", ItemID: id})
+	}
+	for name, id := range requestIDs {
+		entries = append(entries, models.StorageEntry{ProjectID: projectID, Path: name, Kind: "request", ItemID: id})
+	}
+	return s.db.ReplaceStorageEntries(projectID, entries)
+}
+
+func storageRequestFromModel(request *models.Request) storageRequest {
+	file := storageRequest{
+		Kind:        "request",
+		Name:        request.Name,
+		Order:       request.Position,
+		Method:      request.Method,
+		URL:         request.URL,
+		Headers:     request.Headers,
+		Expect:      request.ExpectedStatus,
+		Description: request.Description,
+	}
+	for _, param := range request.QueryParams {
+		file.Query = append(file.Query, storageQueryParam{Key: param.Key, Value: param.Value, Disabled: !param.Enabled})
+	}
+
+	switch request.AuthType {
+	case "bearer":
+		file.Auth = &storageAuth{Type: "bearer", Token: request.BearerToken}
+	case "basic":
+		file.Auth = &storageAuth{Type: "basic", Username: request.BasicAuth.Username, Password: request.BasicAuth.Password}
+	}
+
+	switch request.BodyType {
+	case "", "none":
+	case "form", "multipart":
+		file.Body = &storageBody{Type: request.BodyType}
+		for _, item := range request.FormData {
+			field := storageFormField{Key: item.Key, Value: item.Value}
+			if item.Type == "file" {
+				field.Type = "file"
+			}
+			file.Body.Form = append(file.Body.Form, field)
+		}
+	default:
+		file.Body = &storageBody{Type: request.BodyType, Content: request.Body}
+	}
+	return file
+}
+
+// applyTo copies the file into a request, leaving its ID, project and history alone
+func (file *storageRequest) applyTo(request *models.Request) {
+	request.Name = file.Name
+	if request.Name == "" {
+		request.Name = "Untitled request"
+	}
+	request.Method = strings.ToUpper(file.Method)
+	if request.Method == "" {
+		request.Method = "GET"
+	}
+	request.URL = file.URL
+	request.ExpectedStatus = file.Expect
+	request.Description = file.Description
+	request.Headers = file.Headers
+	if request.Headers == nil {
+		request.Headers = make(map[string]string)
+	}
+
+	request.QueryParams = []models.QueryParam{}
+	for _, param := range file.Query {
+		request.QueryParams = append(request.QueryParams, models.QueryParam{Key: param.Key, Value: param.Value, Enabled: !param.Disabled})
+	}
+
+	request.AuthType = "none"
+	request.BearerToken = ""
+	request.BasicAuth = models.BasicAuth{}
+	if file.Auth != nil {
+		switch file.Auth.Type {
+		case "bearer":
+			request.AuthType = "bearer"
+			request.BearerToken = file.Auth.Token
+		case "basic":
+			request.AuthType = "basic"
+			request.BasicAuth = models.BasicAuth{Username: file.Auth.Username, Password: file.Auth.Password}
+		}
+	}
+
+	request.BodyType = "none"
+	request.Body = ""
+	request.FormData = []models.FormData{}
+	if file.Body != nil && file.Body.Type != "" {
+		request.BodyType = file.Body.Type
+		request.Body = file.Body.Content
+		for _, field := range file.Body.Form {
+			request.FormData = append(request.FormData, models.FormData{Key: field.Key, Value: field.Value, Type: field.Type})
+		}
+	}
+}
+
+func encodeStorageYAML(value interface{}) ([]byte, error) {
+	var content bytes.Buffer
+	encoder := yaml.NewEncoder(&content)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return content.Bytes(), nil
+}
+
+// storageKind is the kind of item a file of a stored project describes, by its path
+func storageKind(name string) string {
+	switch {
+	case name == storageProjectFile:
+		return "project"
+	case path.Base(name) == storageFolderFile:
+		return "folder"
+	}
+	return "request"
+}
+
+// ownStorageFiles separates the files written for a project from the other YAML files of its
+// directory, which are never loaded, overwritten or removed. Files written for the project
+// declare their kind; files at the paths recorded on the last sync are its own even when they
+// no longer parse, so that the error is reported rather than the item deleted.
+func ownStorageFiles(disk map[string][]byte, known []models.StorageEntry) (map[string][]byte, map[string]bool) {
+	recorded := make(map[string]bool)
+	for _, entry := range known {
+		if entry.Kind == "folder" {
+			recorded[path.Join(entry.Path, storageFolderFile)] = true
+		} else {
+			recorded[entry.Path] = true
+		}
+	}
+
+	own := make(map[string][]byte)
+	foreign := make(map[string]bool)
+	for name, content := range disk {
+		var header storageHeader
+		err := yaml.Unmarshal(content, &header)
+		if (err == nil && header.Kind == storageKind(name)) || (recorded[name] && (err != nil || header.Kind == "")) {
+			own[name] = content
+		} else {
+			foreign[name] = true
+		}
+	}
+	return own, foreign
+}
+
+// readStorageFiles returns the YAML files under dir by slash-separated relative path. Hidden
+// files and directories, such as .git, are skipped.
+func readStorageFiles(dir string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	err := filepath.WalkDir(dir, func(name string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name != dir && strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() || filepath.Ext(name) != ".yaml" {
+			return nil
+		}
+
+		relative, err := filepath.Rel(dir, name)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(relative)] = content
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return files, os.MkdirAll(dir, 0755)
+	}
+	return files, err
+}
+
+// writeStorageFiles writes the files that changed and removes the YAML files that are no longer
+// part of the project, along with directories left empty
+func writeStorageFiles(dir string, files, disk map[string][]byte) error {
+	for name, content := range files {
+		if existing, ok := disk[name]; ok && bytes.Equal(existing, content) {
+			continue
+		}
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, content, 0644); err != nil {
+			return err
+		}
+	}
+
+	var removed []string
+	for name := range disk {
+		if _, ok := files[name]; !ok {
+			if err := os.Remove(filepath.Join(dir, filepath.FromSlash(name))); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			removed = append(removed, path.Dir(name))
+		}
+	}
+	// Deepest directories first so parents can become empty
+	sort.Slice(removed, func(i, j int) bool { return len(removed[i]) > len(removed[j]) })
+	for _, name := range removed {
+		for ; name != "."; name = path.Dir(name) {
+			if os.Remove(filepath.Join(dir, filepath.FromSlash(name))) != nil {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func sameStorageFiles(a, b map[string][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, content := range a {
+		if other, ok := b[name]; !ok || !bytes.Equal(content, other) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"rikuest/internal/database"
+	"rikuest/internal/models"
+)
+
+var tagColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// TagService manages the tags of projects and the saved filters selecting requests by them
+type TagService struct {
+	db *database.DB
+}
+
+func NewTagService(db *database.DB) *TagService {
+	return &TagService{db: db}
+}
+
+func (s *TagService) GetTags(projectID int) ([]models.Tag, error) {
+	return s.db.GetTags(projectID)
+}
+
+// CreateTag defines a tag for a project. An empty color is the default tag color.
+func (s *TagService) CreateTag(tag *models.Tag) error {
+	if _, err := s.db.GetProject(tag.ProjectID); err != nil {
+		return fmt.Errorf("project not found: %w", err)
+	}
+	if err := normalizeTag(tag); err != nil {
+		return err
+	}
+	return s.db.CreateTag(tag)
+}
+
+// UpdateTag renames or recolors a tag
+func (s *TagService) UpdateTag(tag *models.Tag) (*models.Tag, error) {
+	existing, err := s.db.GetTag(tag.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, fmt.Errorf("tag not found")
+	}
+	tag.ProjectID = existing.ProjectID
+	if err := normalizeTag(tag); err != nil {
+		return nil, err
+	}
+	if err := s.db.UpdateTag(tag); err != nil {
+		return nil, err
+	}
+	return s.db.GetTag(tag.ID)
+}
+
+// DeleteTag deletes a tag and removes it from its requests
+func (s *TagService) DeleteTag(id int) error {
+	return s.db.DeleteTag(id)
+}
+
+func normalizeTag(tag *models.Tag) error {
+	tag.Name = strings.TrimSpace(tag.Name)
+	if tag.Name == "" {
+		return fmt.Errorf("tag name is required")
+	}
+	if tag.Color == "" {
+		tag.Color = database.DefaultTagColor
+	}
+	if !tagColorPattern.MatchString(tag.Color) {
+		return fmt.Errorf("invalid tag color %q, use a hex color such as #22c55e", tag.Color)
+	}
+	return nil
+}
+
+// FindRequests returns the requests of a project matching a filter
+func (s *TagService) FindRequests(projectID int, filter models.RequestFilter) ([]models.Request, error) {
+	if _, err := s.db.GetProject(projectID); err != nil {
+		return nil, fmt.Errorf("project not found: %w", err)
+	}
+	requests, err := s.db.FindRequests(projectID, filter)
+	if err != nil {
+		return nil, err
+	}
+	if requests == nil {
+		requests = []models.Request{}
+	}
+	return requests, nil
+}
+
+func (s *TagService) GetSavedFilters(projectID int) ([]models.SavedFilter, error) {
+	return s.db.GetSavedFilters(projectID)
+}
+
+// GetSavedFilter returns a saved filter, or an error if there is none with that ID
+func (s *TagService) GetSavedFilter(id int) (*models.SavedFilter, error) {
+	filter, err := s.db.GetSavedFilter(id)
+	if err != nil {
+		return nil, err
+	}
+	if filter == nil {
+		return nil, fmt.Errorf("saved filter not found")
+	}
+	return filter, nil
+}
+
+// CreateSavedFilter saves a filter under a name
+func (s *TagService) CreateSavedFilter(filter *models.SavedFilter) error {
+	if err := s.checkSavedFilter(filter); err != nil {
+		return err
+	}
+	return s.db.CreateSavedFilter(filter)
+}
+
+// UpdateSavedFilter renames a saved filter or changes what it selects
+func (s *TagService) UpdateSavedFilter(filter *models.SavedFilter) (*models.SavedFilter, error) {
+	existing, err := s.GetSavedFilter(filter.ID)
+	if err != nil {
+		return nil, err
+	}
+	filter.ProjectID = existing.ProjectID
+	if err := s.checkSavedFilter(filter); err != nil {
+		return nil, err
+	}
+	if err := s.db.UpdateSavedFilter(filter); err != nil {
+		return nil, err
+	}
+	return s.db.GetSavedFilter(filter.ID)
+}
+
+func (s *TagService) DeleteSavedFilter(id int) error {
+	return s.db.DeleteSavedFilter(id)
+}
+
+// FindSavedFilterRequests returns the requests a saved filter currently selects
+func (s *TagService) FindSavedFilterRequests(id int) ([]models.Request, error) {
+	filter, err := s.GetSavedFilter(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.FindRequests(filter.ProjectID, filter.Filter)
+}
+
+// checkSavedFilter validates a filter by running it once, which also checks the project
+func (s *TagService) checkSavedFilter(filter *models.SavedFilter) error {
+	filter.Name = strings.TrimSpace(filter.Name)
+	if filter.Name == "" {
+		return fmt.Errorf("filter name is required")
+	}
+	_, err := s.FindRequests(filter.ProjectID, filter.Filter)
+	return err
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"rikuest/internal/database"
+	"rikuest/internal/models"
+)
+
+const trashPurgeEvery = time.Hour
+
+// TrashService lists, restores and purges deleted projects, folders and requests. Items older
+// than the configured retention are purged in the background.
+type TrashService struct {
+	db    *database.DB
+	mutex sync.Mutex
+	stop  chan struct{}
+}
+
+func NewTrashService(db *database.DB) *TrashService {
+	return &TrashService{db: db}
+}
+
+// Start purges expired items now and then every hour, until Stop is called
+func (s *TrashService) Start() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.stop != nil {
+		return
+	}
+	stop := make(chan struct{})
+	s.stop = stop
+
+	go func() {
+		ticker := time.NewTicker(trashPurgeEvery)
+		defer ticker.Stop()
+
+		s.purgeExpired()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				s.purgeExpired()
+			}
+		}
+	}()
+}
+
+func (s *TrashService) Stop() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+}
+
+func (s *TrashService) purgeExpired() {
+	retention := s.GetConfig().RetentionDays
+	items, err := s.db.GetTrashBefore(time.Now().AddDate(0, 0, -retention))
+	if err != nil {
+		log.Printf("Failed to list expired trash: %v", err)
+		return
+	}
+	for _, item := range items {
+		if err := s.db.PurgeTrash(item.ID); err != nil && err != sql.ErrNoRows {
+			log.Printf("Failed to purge %s %q from the trash: %v", item.Kind, item.Name, err)
+		}
+	}
+}
+
+// GetConfig returns the trash settings, falling back to 30 days
+func (s *TrashService) GetConfig() models.TrashConfig {
+	config := models.TrashConfig{RetentionDays: 30}
+	if value, err := s.db.GetSetting("trash_retention_days"); err == nil && value != "" {
+		if days, err := strconv.Atoi(value); err == nil && days > 0 {
+			config.RetentionDays = days
+		}
+	}
+	return config
+}
+
+func (s *TrashService) SetConfig(config models.TrashConfig) error {
+	if config.RetentionDays < 1 {
+		return fmt.Errorf("items must stay in the trash for at least one day")
+	}
+	return s.db.SetSetting("trash_retention_days", strconv.Itoa(config.RetentionDays))
+}
+
+// GetTrash lists the folders and requests deleted from a project
+func (s *TrashService) GetTrash(projectID int) ([]models.TrashItem, error) {
+	items, err := s.db.GetTrash(projectID)
+	if err != nil {
+		return nil, err
+	}
+	return s.withPurgeDates(items), nil
+}
+
+func (s *TrashService) GetTrashedProjects() ([]models.TrashItem, error) {
+	items, err := s.db.GetTrashedProjects()
+	if err != nil {
+		return nil, err
+	}
+	return s.withPurgeDates(items), nil
+}
+
+func (s *TrashService) withPurgeDates(items []models.TrashItem) []models.TrashItem {
+	retention := s.GetConfig().RetentionDays
+	for i := range items {
+		items[i].PurgeAt = items[i].DeletedAt.AddDate(0, 0, retention)
+	}
+	return items
+}
+
+// Restore puts a trashed item back in its folder and position
+func (s *TrashService) Restore(id int) error {
+	if err := s.db.RestoreTrash(id); err == sql.ErrNoRows {
+		return fmt.Errorf("trash item not found")
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Purge permanently deletes a trashed item
+func (s *TrashService) Purge(id int) error {
+	if err := s.db.PurgeTrash(id); err == sql.ErrNoRows {
+		return fmt.Errorf("trash item not found")
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
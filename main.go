@@ -1,8 +1,10 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"embed"
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
@@ -61,8 +63,12 @@ func (a *App) OnStartup(ctx context.Context) {
 	// Get webhook URL from centralized config
 	webhookURL := config.DiscordWebhookURL()
 
-	// Initialize services
-	a.services = services.NewServices(db, webhookURL)
+	// Initialize services. The desktop app reads local files anywhere, as its user can, and keeps
+	// uploaded files in the data directory.
+	a.services = services.NewServices(db, webhookURL, services.FileAccess{
+		Unrestricted: true,
+		Root:         filepath.Join(dataDir, "files"),
+	})
 
 	// Update webhook URL from config if available (allows runtime override from DB)
 	telemetryConfig, err := a.services.Telemetry.GetConfig()
@@ -71,6 +77,13 @@ func (a *App) OnStartup(ctx context.Context) {
 		a.services.Telemetry = services.NewTelemetryService(db, telemetryConfig.WebhookURL)
 	}
 
+	// Back up the database now and on schedule, purge expired trash and history, and keep
+	// projects stored as files in sync
+	a.services.Backup.Start()
+	a.services.Trash.Start()
+	a.services.History.Start()
+	a.services.Storage.Start()
+
 	// Setup panic recovery
 	defer func() {
 		if r := recover(); r != nil {
@@ -96,6 +109,24 @@ func (a *App) OnShutdown(ctx context.Context) {
 	if a.services != nil && a.services.Telemetry != nil {
 		a.services.Telemetry.ReportSessionEnd()
 	}
+	if a.services != nil && a.services.Mock != nil {
+		a.services.Mock.Stop()
+	}
+	if a.services != nil && a.services.Proxy != nil {
+		a.services.Proxy.Stop()
+	}
+	if a.services != nil && a.services.Storage != nil {
+		a.services.Storage.Stop()
+	}
+	if a.services != nil && a.services.Backup != nil {
+		a.services.Backup.Stop()
+	}
+	if a.services != nil && a.services.Trash != nil {
+		a.services.Trash.Stop()
+	}
+	if a.services != nil && a.services.History != nil {
+		a.services.History.Stop()
+	}
 }
 
 // GetPlatform returns the current platform
@@ -145,6 +176,28 @@ func (a *App) DeleteProject(id int) error {
 	return a.services.Project.DeleteProject(id)
 }
 
+// ExportProject returns a project as a bundle file to share it or move it to another machine
+func (a *App) ExportProject(projectID int, includeHistory bool) (string, error) {
+	bundle, err := a.services.Project.ExportProject(projectID, includeHistory)
+	if err != nil {
+		return "", err
+	}
+	return marshalIndented(bundle)
+}
+
+// ImportProject creates a new project from a bundle file, renaming it if the name is taken
+func (a *App) ImportProject(content string) (*models.ImportResult, error) {
+	result, err := a.services.Project.ImportProject([]byte(content))
+	if err != nil {
+		return nil, err
+	}
+	a.services.Telemetry.ReportUsageEvent("project_imported", map[string]interface{}{
+		"project_id": result.ProjectID,
+		"requests":   result.Requests,
+	})
+	return result, nil
+}
+
 // ===== REQUEST BINDINGS =====
 
 func (a *App) GetRequests(projectID int) ([]models.Request, error) {
@@ -164,7 +217,7 @@ func (a *App) CreateRequest(request models.Request) (*models.Request, error) {
 }
 
 func (a *App) UpdateRequest(request models.Request) (*models.Request, error) {
-	err := a.services.Request.UpdateRequest(&request)
+	err := a.services.Request.UpdateRequest(&request, "")
 	if err != nil {
 		return nil, err
 	}
@@ -175,8 +228,9 @@ func (a *App) DeleteRequest(id int) error {
 	return a.services.Request.DeleteRequest(id)
 }
 
-func (a *App) GetRequestHistory(requestID int) ([]models.RequestHistory, error) {
-	return a.services.Request.GetRequestHistory(requestID)
+// GetRequestHistory returns a page of the executions of a request matching a filter
+func (a *App) GetRequestHistory(requestID int, filter models.HistoryFilter) (*models.HistoryPage, error) {
+	return a.services.History.GetRequestHistory(requestID, filter)
 }
 
 func (a *App) ExecuteRequest(requestID int) (*models.RequestResponse, error) {
@@ -203,7 +257,7 @@ func (a *App) MoveRequest(requestID int, folderID *int, position int) error {
 }
 
 func (a *App) CopyRequest(requestID int, format string) (string, error) {
-	request, err := a.services.Request.GetRequest(requestID)
+	request, err := a.services.Request.GetRequestWithResponse(requestID)
 	if err != nil {
 		return "", err
 	}
@@ -212,22 +266,367 @@ func (a *App) CopyRequest(requestID int, format string) (string, error) {
 }
 
 func (a *App) CopyAllRequestFormats(requestID int) (map[string]string, error) {
-	request, err := a.services.Request.GetRequest(requestID)
+	request, err := a.services.Request.GetRequestWithResponse(requestID)
 	if err != nil {
 		return nil, err
 	}
 
 	allFormats := a.services.Format.GetAllFormats(request)
 	formats := map[string]string{
-		"raw":    allFormats.Raw,
-		"curl":   allFormats.Curl,
-		"fetch":  allFormats.Fetch,
-		"python": allFormats.Python,
+		"raw":       allFormats.Raw,
+		"curl":      allFormats.Curl,
+		"fetch":     allFormats.Fetch,
+		"python":    allFormats.Python,
+		"http-file": allFormats.HTTPFile,
+		"openapi":   allFormats.OpenAPI,
 	}
 
 	return formats, nil
 }
 
+// ParseCurl converts a pasted curl command into a request without saving it
+func (a *App) ParseCurl(command string) (*models.Request, error) {
+	return a.services.Format.ParseCurlRequest(command)
+}
+
+// ImportCurl saves a pasted curl command as a new request
+func (a *App) ImportCurl(projectID int, folderID *int, command string) (*models.Request, error) {
+	request, err := a.services.Request.ImportCurl(projectID, folderID, "", command)
+	if err != nil {
+		return nil, err
+	}
+	a.services.Telemetry.ReportUsageEvent("curl_imported", map[string]interface{}{
+		"project_id": projectID,
+	})
+	return request, nil
+}
+
+// UploadFile stores a file for multipart requests to send and returns the path their file
+// fields refer to it by
+func (a *App) UploadFile(name string, content []byte) (string, error) {
+	return a.services.Files.SaveUpload(name, bytes.NewReader(content))
+}
+
+// ===== RESPONSE EXAMPLE BINDINGS =====
+
+func (a *App) GetRequestExamples(requestID int) ([]models.ResponseExample, error) {
+	return a.services.Request.GetExamples(requestID)
+}
+
+func (a *App) CreateRequestExample(example models.ResponseExample) (*models.ResponseExample, error) {
+	err := a.services.Request.CreateExample(&example)
+	if err != nil {
+		return nil, err
+	}
+	return &example, nil
+}
+
+func (a *App) SaveHistoryAsExample(requestID int, historyID int, name string) (*models.ResponseExample, error) {
+	return a.services.Request.SaveHistoryAsExample(requestID, historyID, name)
+}
+
+func (a *App) UpdateRequestExample(example models.ResponseExample) (*models.ResponseExample, error) {
+	err := a.services.Request.UpdateExample(&example)
+	if err != nil {
+		return nil, err
+	}
+	return &example, nil
+}
+
+func (a *App) DeleteRequestExample(id int) error {
+	return a.services.Request.DeleteExample(id)
+}
+
+// ===== MOCK SERVER BINDINGS =====
+
+func (a *App) StartMockServer(config models.MockServerConfig) (models.MockServerStatus, error) {
+	if err := a.services.Mock.Start(config); err != nil {
+		return a.services.Mock.Status(), err
+	}
+	a.services.Telemetry.ReportUsageEvent("mock_server_started", map[string]interface{}{
+		"project_id": config.ProjectID,
+	})
+	return a.services.Mock.Status(), nil
+}
+
+func (a *App) StopMockServer() error {
+	return a.services.Mock.Stop()
+}
+
+func (a *App) GetMockServerStatus() models.MockServerStatus {
+	return a.services.Mock.Status()
+}
+
+// ===== RECORDING PROXY BINDINGS =====
+
+func (a *App) StartProxy(config models.ProxyConfig) (models.ProxyStatus, error) {
+	if err := a.services.Proxy.Start(config); err != nil {
+		return a.services.Proxy.Status(), err
+	}
+	a.services.Telemetry.ReportUsageEvent("proxy_started", map[string]interface{}{
+		"project_id":      config.ProjectID,
+		"intercept_https": config.InterceptHTTPS,
+	})
+	return a.services.Proxy.Status(), nil
+}
+
+func (a *App) StopProxy() error {
+	return a.services.Proxy.Stop()
+}
+
+func (a *App) GetProxyStatus() models.ProxyStatus {
+	return a.services.Proxy.Status()
+}
+
+func (a *App) GetProxyCACertificate() (string, error) {
+	return a.services.Proxy.CACertificatePEM()
+}
+
+// ===== ENVIRONMENT BINDINGS =====
+
+func (a *App) GetEnvironments(projectID int) ([]models.Environment, error) {
+	return a.services.Environment.GetEnvironments(projectID)
+}
+
+func (a *App) CreateEnvironment(environment models.Environment) (*models.Environment, error) {
+	err := a.services.Environment.CreateEnvironment(&environment)
+	if err != nil {
+		return nil, err
+	}
+	return &environment, nil
+}
+
+func (a *App) UpdateEnvironment(environment models.Environment) (*models.Environment, error) {
+	err := a.services.Environment.UpdateEnvironment(&environment)
+	if err != nil {
+		return nil, err
+	}
+	return &environment, nil
+}
+
+func (a *App) DeleteEnvironment(id int) error {
+	return a.services.Environment.DeleteEnvironment(id)
+}
+
+func (a *App) SetActiveEnvironment(projectID int, environmentID *int) error {
+	return a.services.Environment.SetActiveEnvironment(projectID, environmentID)
+}
+
+// ===== POSTMAN BINDINGS =====
+
+// ImportPostmanCollection imports a collection into a project, or into a new project when projectID is 0
+func (a *App) ImportPostmanCollection(projectID int, folderID *int, content string) (*models.ImportResult, error) {
+	result, err := a.services.Postman.ImportCollection(projectID, folderID, []byte(content))
+	if err != nil {
+		return nil, err
+	}
+	a.services.Telemetry.ReportUsageEvent("postman_imported", map[string]interface{}{
+		"project_id": result.ProjectID,
+		"requests":   result.Requests,
+	})
+	return result, nil
+}
+
+func (a *App) ImportPostmanEnvironment(projectID int, content string) (*models.ImportResult, error) {
+	return a.services.Postman.ImportEnvironment(projectID, []byte(content))
+}
+
+func (a *App) ExportPostmanCollection(projectID int) (string, error) {
+	collection, err := a.services.Postman.ExportCollection(projectID)
+	if err != nil {
+		return "", err
+	}
+	return marshalIndented(collection)
+}
+
+func (a *App) ExportPostmanEnvironment(environmentID int) (string, error) {
+	environment, err := a.services.Postman.ExportEnvironment(environmentID)
+	if err != nil {
+		return "", err
+	}
+	return marshalIndented(environment)
+}
+
+// ===== INSOMNIA BINDINGS =====
+
+// ImportInsomnia imports an Insomnia v4 export (JSON or YAML), creating one project per workspace
+func (a *App) ImportInsomnia(content string) ([]*models.ImportResult, error) {
+	results, err := a.services.Insomnia.Import([]byte(content))
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range results {
+		a.services.Telemetry.ReportUsageEvent("insomnia_imported", map[string]interface{}{
+			"project_id": result.ProjectID,
+			"requests":   result.Requests,
+		})
+	}
+	return results, nil
+}
+
+// ===== HAR BINDINGS =====
+
+func (a *App) ImportHAR(projectID int, folderID *int, content string) (*models.ImportResult, error) {
+	result, err := a.services.HAR.ImportHAR(projectID, folderID, []byte(content))
+	if err != nil {
+		return nil, err
+	}
+	a.services.Telemetry.ReportUsageEvent("har_imported", map[string]interface{}{
+		"project_id": projectID,
+		"requests":   result.Requests,
+	})
+	return result, nil
+}
+
+func (a *App) ExportProjectHAR(projectID int) (string, error) {
+	file, err := a.services.HAR.ExportProject(projectID)
+	if err != nil {
+		return "", err
+	}
+	return marshalIndented(file)
+}
+
+func (a *App) ExportFolderHAR(folderID int) (string, error) {
+	file, err := a.services.HAR.ExportFolder(folderID)
+	if err != nil {
+		return "", err
+	}
+	return marshalIndented(file)
+}
+
+func (a *App) ExportHistoryHAR(historyIDs []int) (string, error) {
+	file, err := a.services.HAR.ExportHistory(historyIDs)
+	if err != nil {
+		return "", err
+	}
+	return marshalIndented(file)
+}
+
+// ===== OPENAPI BINDINGS =====
+
+// PreviewOpenAPI lists the operations of an OpenAPI 3 or Swagger 2 document (JSON or YAML)
+func (a *App) PreviewOpenAPI(content string) (*services.OpenAPIPreview, error) {
+	return a.services.OpenAPI.Preview([]byte(content))
+}
+
+func (a *App) ImportOpenAPI(projectID int, content string, options services.OpenAPIImportOptions) (*models.ImportResult, error) {
+	result, err := a.services.OpenAPI.Import(projectID, []byte(content), options)
+	if err != nil {
+		return nil, err
+	}
+	a.services.Telemetry.ReportUsageEvent("openapi_imported", map[string]interface{}{
+		"project_id": projectID,
+		"requests":   result.Requests,
+	})
+	return result, nil
+}
+
+func (a *App) GetOpenAPISpecs(projectID int) ([]models.OpenAPISpec, error) {
+	return a.services.OpenAPI.GetSpecs(projectID)
+}
+
+func (a *App) DeleteOpenAPISpec(specID int) error {
+	return a.services.OpenAPI.DeleteSpec(specID)
+}
+
+// DiffOpenAPISpec compares a new version of an imported document with the requests created from it
+func (a *App) DiffOpenAPISpec(specID int, content string) (*services.OpenAPIDiff, error) {
+	return a.services.OpenAPI.Diff(specID, []byte(content))
+}
+
+func (a *App) SyncOpenAPISpec(specID int, content string, options services.OpenAPISyncOptions) (*services.OpenAPISyncResult, error) {
+	result, err := a.services.OpenAPI.Sync(specID, []byte(content), options)
+	if err != nil {
+		return nil, err
+	}
+	a.services.Telemetry.ReportUsageEvent("openapi_synced", map[string]interface{}{
+		"spec_id": specID,
+		"added":   result.Added,
+		"updated": result.Updated,
+		"removed": result.Removed,
+	})
+	return result, nil
+}
+
+// ExportProjectOpenAPI describes a project as an OpenAPI 3.1 document; format is "yaml" or "json"
+func (a *App) ExportProjectOpenAPI(projectID int, format string) (string, error) {
+	return a.services.OpenAPI.ExportProject(projectID, format)
+}
+
+func (a *App) ExportFolderOpenAPI(folderID int, format string) (string, error) {
+	return a.services.OpenAPI.ExportFolder(folderID, format)
+}
+
+// ===== STORAGE BINDINGS =====
+
+// GetProjectStorage returns the directory a project is stored in, or nil if it is only in the database
+func (a *App) GetProjectStorage(projectID int) (*models.ProjectStorage, error) {
+	return a.services.Storage.GetStorage(projectID)
+}
+
+// SetProjectStorage stores a project as YAML files in a directory and keeps them in sync
+func (a *App) SetProjectStorage(projectID int, path string) (*models.ProjectStorage, error) {
+	storage, err := a.services.Storage.Link(projectID, path)
+	if err != nil {
+		return nil, err
+	}
+	a.services.Telemetry.ReportUsageEvent("project_storage_linked", map[string]interface{}{
+		"project_id": projectID,
+	})
+	return storage, nil
+}
+
+func (a *App) DeleteProjectStorage(projectID int) error {
+	return a.services.Storage.Unlink(projectID)
+}
+
+func (a *App) SyncProjectStorage(projectID int) (*models.ProjectStorage, error) {
+	return a.services.Storage.Sync(projectID)
+}
+
+// OpenProjectDirectory creates a project from a directory of YAML files, such as a git checkout
+func (a *App) OpenProjectDirectory(path string) (*models.Project, error) {
+	return a.services.Storage.OpenDirectory(path)
+}
+
+// ===== DOCS BINDINGS =====
+
+// ExportProjectDocs writes the documentation site of a project to a directory and returns the
+// path of its index page
+func (a *App) ExportProjectDocs(projectID int, path string) (string, error) {
+	index, err := a.services.Docs.WriteProject(projectID, path)
+	if err != nil {
+		return "", err
+	}
+	a.services.Telemetry.ReportUsageEvent("docs_exported", map[string]interface{}{
+		"project_id": projectID,
+	})
+	return index, nil
+}
+
+// ===== HTTP FILE BINDINGS =====
+
+// ImportHTTPFile imports a .http/.rest file; its variables become an environment named environmentName
+func (a *App) ImportHTTPFile(projectID int, folderID *int, environmentName string, content string) (*models.ImportResult, error) {
+	result, err := a.services.HTTPFile.Import(projectID, folderID, environmentName, []byte(content))
+	if err != nil {
+		return nil, err
+	}
+	a.services.Telemetry.ReportUsageEvent("http_file_imported", map[string]interface{}{
+		"project_id": projectID,
+		"requests":   result.Requests,
+	})
+	return result, nil
+}
+
+func (a *App) ExportProjectHTTPFile(projectID int) (string, error) {
+	return a.services.HTTPFile.ExportProject(projectID)
+}
+
+func (a *App) ExportFolderHTTPFile(folderID int) (string, error) {
+	return a.services.HTTPFile.ExportFolder(folderID)
+}
+
 // ===== FOLDER BINDINGS =====
 
 func (a *App) GetFolders(projectID int) ([]models.Folder, error) {
@@ -261,6 +660,294 @@ func (a *App) DeleteFolder(id int) error {
 	return a.services.Folder.DeleteFolder(id)
 }
 
+// DeleteFolderKeepingContents moves a folder to the trash after moving its subfolders and
+// requests up to its parent
+func (a *App) DeleteFolderKeepingContents(id int) error {
+	return a.services.Folder.DeleteFolderKeepingContents(id)
+}
+
+// ReorderFolders sets the order of the folders directly in parentID, or at the project root
+func (a *App) ReorderFolders(projectID int, parentID *int, folderIDs []int) error {
+	return a.services.Folder.ReorderFolders(projectID, parentID, folderIDs)
+}
+
+// ===== DUPLICATE BINDINGS =====
+
+// DuplicateRequest copies a request with its examples, by default next to the original
+func (a *App) DuplicateRequest(requestID int, target models.CopyTarget) (*models.Request, error) {
+	request, err := a.services.Request.CopyRequest(requestID, target)
+	if err != nil {
+		return nil, err
+	}
+	a.services.Telemetry.ReportUsageEvent("request_duplicated", map[string]interface{}{
+		"request_id": requestID,
+	})
+	return request, nil
+}
+
+// DuplicateFolder copies a folder with all its subfolders and requests
+func (a *App) DuplicateFolder(folderID int, target models.CopyTarget) (*models.Folder, error) {
+	folder, err := a.services.Folder.CopyFolder(folderID, target)
+	if err != nil {
+		return nil, err
+	}
+	a.services.Telemetry.ReportUsageEvent("folder_duplicated", map[string]interface{}{
+		"folder_id": folderID,
+	})
+	return folder, nil
+}
+
+// DuplicateProject copies a project into a new project, or its contents into the target project
+func (a *App) DuplicateProject(projectID int, target models.CopyTarget) (*models.Project, error) {
+	project, err := a.services.Project.CopyProject(projectID, target)
+	if err != nil {
+		return nil, err
+	}
+	a.services.Telemetry.ReportUsageEvent("project_duplicated", map[string]interface{}{
+		"project_id": projectID,
+	})
+	return project, nil
+}
+
+// MoveFolder moves a folder with all its subfolders and requests, possibly to another project,
+// at the target position or at the end
+func (a *App) MoveFolder(folderID int, target models.CopyTarget) error {
+	return a.services.Folder.MoveFolder(folderID, target)
+}
+
+// MoveRequestTo moves a request to the end of a folder, possibly in another project
+func (a *App) MoveRequestTo(requestID int, target models.CopyTarget) error {
+	return a.services.Request.MoveRequestTo(requestID, target)
+}
+
+// ===== TAG BINDINGS =====
+
+func (a *App) GetTags(projectID int) ([]models.Tag, error) {
+	return a.services.Tag.GetTags(projectID)
+}
+
+func (a *App) CreateTag(tag models.Tag) (*models.Tag, error) {
+	if err := a.services.Tag.CreateTag(&tag); err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (a *App) UpdateTag(tag models.Tag) (*models.Tag, error) {
+	return a.services.Tag.UpdateTag(&tag)
+}
+
+func (a *App) DeleteTag(id int) error {
+	return a.services.Tag.DeleteTag(id)
+}
+
+// FindRequests lists the requests of a project matching a filter by tags, method, URL or last status
+func (a *App) FindRequests(projectID int, filter models.RequestFilter) ([]models.Request, error) {
+	return a.services.Tag.FindRequests(projectID, filter)
+}
+
+func (a *App) GetSavedFilters(projectID int) ([]models.SavedFilter, error) {
+	return a.services.Tag.GetSavedFilters(projectID)
+}
+
+func (a *App) CreateSavedFilter(filter models.SavedFilter) (*models.SavedFilter, error) {
+	if err := a.services.Tag.CreateSavedFilter(&filter); err != nil {
+		return nil, err
+	}
+	return &filter, nil
+}
+
+func (a *App) UpdateSavedFilter(filter models.SavedFilter) (*models.SavedFilter, error) {
+	return a.services.Tag.UpdateSavedFilter(&filter)
+}
+
+func (a *App) DeleteSavedFilter(id int) error {
+	return a.services.Tag.DeleteSavedFilter(id)
+}
+
+// GetSavedFilterRequests lists the requests a saved filter currently selects
+func (a *App) GetSavedFilterRequests(id int) ([]models.Request, error) {
+	return a.services.Tag.FindSavedFilterRequests(id)
+}
+
+// RunSavedFilter executes the requests a saved filter selects, one after another
+func (a *App) RunSavedFilter(id int) (*models.RunResult, error) {
+	result, err := a.services.Runner.RunSavedFilter(id)
+	if err != nil {
+		return nil, err
+	}
+	a.services.Telemetry.ReportUsageEvent("filter_run", map[string]interface{}{
+		"filter_id": id,
+		"requests":  len(result.Results),
+		"failed":    result.Failed,
+	})
+	return result, nil
+}
+
+// RunSavedFilterReport runs a saved filter and returns the result as a junit, tap, json or
+// html report
+func (a *App) RunSavedFilterReport(id int, format string) (string, error) {
+	if services.RunReportContentType(format) == "" {
+		return "", fmt.Errorf("unknown report format %q", format)
+	}
+	result, err := a.services.Runner.RunSavedFilter(id)
+	if err != nil {
+		return "", err
+	}
+	var report bytes.Buffer
+	if err := services.WriteRunReport(&report, result, format); err != nil {
+		return "", err
+	}
+	a.services.Telemetry.ReportUsageEvent("filter_run", map[string]interface{}{
+		"filter_id": id,
+		"requests":  len(result.Results),
+		"failed":    result.Failed,
+		"report":    format,
+	})
+	return report.String(), nil
+}
+
+// ===== SEARCH BINDINGS =====
+
+// Search finds requests, folders and, when asked, history responses by their content
+func (a *App) Search(query models.SearchQuery) ([]models.SearchResult, error) {
+	return a.services.Search.Search(query)
+}
+
+// ===== HISTORY BINDINGS =====
+
+// GetProjectHistory returns a page of the executions of every request in a project
+func (a *App) GetProjectHistory(projectID int, filter models.HistoryFilter) (*models.HistoryPage, error) {
+	return a.services.History.GetProjectHistory(projectID, filter)
+}
+
+func (a *App) GetHistoryConfig() models.HistoryConfig {
+	return a.services.History.GetConfig()
+}
+
+func (a *App) SetHistoryConfig(config models.HistoryConfig) error {
+	return a.services.History.SetConfig(config)
+}
+
+// ReplayHistory sends the request of a history entry again exactly as it was sent
+func (a *App) ReplayHistory(historyID int) (*models.RequestHistory, error) {
+	entry, err := a.services.History.Replay(historyID)
+	if err != nil {
+		return nil, err
+	}
+	a.services.Telemetry.ReportUsageEvent("history_replayed", map[string]interface{}{
+		"history_id": historyID,
+	})
+	return entry, nil
+}
+
+// DiffHistory compares the responses of two history entries, leaving out ignorePaths on top
+// of the configured ones
+func (a *App) DiffHistory(historyID, againstID int, ignorePaths []string) (*services.ResponseDiff, error) {
+	return a.services.History.Diff(historyID, againstID, ignorePaths)
+}
+
+// DiffHistoryLive replays a history entry and compares its response with the live one
+func (a *App) DiffHistoryLive(historyID int, ignorePaths []string) (*services.ResponseDiff, error) {
+	return a.services.History.DiffLive(historyID, ignorePaths)
+}
+
+func (a *App) GetResponseDiffConfig() models.ResponseDiffConfig {
+	return a.services.History.GetDiffConfig()
+}
+
+func (a *App) SetResponseDiffConfig(config models.ResponseDiffConfig) error {
+	return a.services.History.SetDiffConfig(config)
+}
+
+// ===== REVISION BINDINGS =====
+
+// GetRequestRevisions lists the earlier states of a request, newest first
+func (a *App) GetRequestRevisions(requestID int) ([]models.RequestRevision, error) {
+	return a.services.Request.GetRevisions(requestID)
+}
+
+// DiffRequestRevisions compares two revisions of a request, 0 standing for its current state
+func (a *App) DiffRequestRevisions(requestID, from, to int) ([]services.RequestFieldChange, error) {
+	return a.services.Request.DiffRevisions(requestID, from, to)
+}
+
+func (a *App) RollbackRequest(requestID, revisionID int) (*models.Request, error) {
+	request, err := a.services.Request.RollbackRequest(requestID, revisionID, "")
+	if err != nil {
+		return nil, err
+	}
+	a.services.Telemetry.ReportUsageEvent("request_rolled_back", map[string]interface{}{
+		"request_id": requestID,
+	})
+	return request, nil
+}
+
+// ===== TRASH BINDINGS =====
+
+// GetProjectTrash lists the folders and requests deleted from a project
+func (a *App) GetProjectTrash(projectID int) ([]models.TrashItem, error) {
+	return a.services.Trash.GetTrash(projectID)
+}
+
+func (a *App) GetTrashedProjects() ([]models.TrashItem, error) {
+	return a.services.Trash.GetTrashedProjects()
+}
+
+// RestoreTrashItem puts a deleted project, folder or request back where it was
+func (a *App) RestoreTrashItem(id int) error {
+	if err := a.services.Trash.Restore(id); err != nil {
+		return err
+	}
+	a.services.Telemetry.ReportUsageEvent("trash_restored", map[string]interface{}{
+		"trash_id": id,
+	})
+	return nil
+}
+
+func (a *App) PurgeTrashItem(id int) error {
+	return a.services.Trash.Purge(id)
+}
+
+func (a *App) GetTrashConfig() models.TrashConfig {
+	return a.services.Trash.GetConfig()
+}
+
+func (a *App) SetTrashConfig(config models.TrashConfig) error {
+	return a.services.Trash.SetConfig(config)
+}
+
+// ===== BACKUP BINDINGS =====
+
+// GetBackups lists the database backups, newest first
+func (a *App) GetBackups() ([]models.Backup, error) {
+	return a.services.Backup.GetBackups()
+}
+
+func (a *App) CreateBackup() (*models.Backup, error) {
+	return a.services.Backup.CreateBackup("manual")
+}
+
+// RestoreBackup replaces the database with a backup, after backing up the current one
+func (a *App) RestoreBackup(name string) (*models.Backup, error) {
+	backup, err := a.services.Backup.RestoreBackup(name)
+	if err != nil {
+		return nil, err
+	}
+	a.services.Telemetry.ReportUsageEvent("backup_restored", map[string]interface{}{
+		"reason": backup.Reason,
+	})
+	return backup, nil
+}
+
+func (a *App) GetBackupConfig() models.BackupConfig {
+	return a.services.Backup.GetConfig()
+}
+
+func (a *App) SetBackupConfig(config models.BackupConfig) error {
+	return a.services.Backup.SetConfig(config)
+}
+
 // ===== TELEMETRY BINDINGS =====
 
 func (a *App) ReportError(errMsg string, stackTrace string) error {
@@ -287,6 +974,15 @@ func (a *App) SetTelemetryEnabled(enabled bool) error {
 	return a.services.Telemetry.UpdateConfig(config)
 }
 
+// marshalIndented renders exported documents as readable JSON for the frontend to save
+func marshalIndented(v interface{}) (string, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
 // getAppDataDir returns the appropriate application data directory for the current OS
 func getAppDataDir() (string, error) {
 	homeDir, err := os.UserHomeDir()